@@ -85,14 +85,36 @@ func run() error {
 
 		swagger := server.CreateEchoSwagger(deps.Echo, deps.AppInfo.Version, ParamsRestAPI.SwaggerEnabled)
 
+		if ParamsRestAPI.HTTPCache.Enabled {
+			deps.Echo.Use(server.HTTPCacheMiddleware(deps.Database, &server.HTTPCacheConfig{
+				MaxAge:          ParamsRestAPI.HTTPCache.MaxAge,
+				ImmutableMaxAge: ParamsRestAPI.HTTPCache.ImmutableMaxAge,
+			}))
+		}
+
 		//nolint:contextcheck //false positive
-		_ = server.NewDatabaseServer(
+		databaseServer := server.NewDatabaseServer(
 			swagger,
 			deps.AppInfo,
 			deps.Database,
 			ParamsRestAPI.Limits.MaxResults,
+			ParamsRestAPI.Limits.MaxScannedKeys,
 		)
 
+		if ParamsRestAPI.SpentAddressesFilter.Enabled {
+			go func() {
+				Component.LogInfo("Building spent addresses filter ...")
+				if err := databaseServer.BuildSpentAddressesFilter(ctx, ParamsRestAPI.SpentAddressesFilter.FalsePositiveRate); err != nil {
+					if !ierrors.Is(err, database.ErrOperationAborted) {
+						Component.LogWarnf("Building spent addresses filter failed: %s", err)
+					}
+
+					return
+				}
+				Component.LogInfo("Building spent addresses filter ... done")
+			}()
+		}
+
 		deps.Echo.Server.BaseContext = func(l net.Listener) context.Context {
 			// set BaseContext to be the same as the worker,
 			// so that requests being processed don't hang the shutdown procedure
@@ -1,6 +1,8 @@
 package coreapi
 
 import (
+	"time"
+
 	"github.com/iotaledger/hive.go/app"
 )
 
@@ -17,8 +19,26 @@ type ParametersRestAPI struct {
 		MaxBodyLength string `default:"1M" usage:"the maximum number of characters that the body of an API call may contain"`
 		// the maximum number of results that may be returned by an endpoint
 		MaxResults int `default:"1000" usage:"the maximum number of results that may be returned by an endpoint"`
+		// the maximum number of index entries that are scanned by a single transactions search
+		MaxScannedKeys int `default:"100000" usage:"the maximum number of index entries that are scanned by a single transactions search"`
+	}
+
+	SpentAddressesFilter struct {
+		// Enabled defines whether the Bloom filter over all spent addresses is built and served
+		Enabled bool `default:"true" usage:"whether the Bloom filter over all spent addresses is built and served"`
+		// FalsePositiveRate defines the false positive rate of the Bloom filter over all spent addresses
+		FalsePositiveRate float64 `default:"0.001" usage:"the false positive rate of the Bloom filter over all spent addresses"`
 	}
 
+	HTTPCache struct {
+		// Enabled defines whether to add ETag and Cache-Control headers to the API responses
+		Enabled bool `default:"true" usage:"whether to add ETag and Cache-Control headers to the API responses"`
+		// MaxAge defines the time that responses which depend on the latest ledger state may be cached
+		MaxAge time.Duration `default:"1m" usage:"the time that responses which depend on the latest ledger state may be cached"`
+		// ImmutableMaxAge defines the time that milestone-bound responses may be cached
+		ImmutableMaxAge time.Duration `default:"8760h" usage:"the time that milestone-bound responses may be cached"`
+	} `name:"httpCache"`
+
 	// SwaggerEnabled defines whether to provide swagger API documentation under endpoint "/swagger"
 	SwaggerEnabled bool `default:"false" usage:"whether to provide swagger API documentation under endpoint \"/swagger\""`
 
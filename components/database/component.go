@@ -2,12 +2,14 @@ package database
 
 import (
 	"context"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 	"go.uber.org/dig"
 
 	"github.com/iotaledger/hive.go/app"
 	"github.com/iotaledger/hive.go/app/shutdown"
+	hivedb "github.com/iotaledger/hive.go/kvstore/database"
 	"github.com/iotaledger/inx-api-core-v0/pkg/daemon"
 	"github.com/iotaledger/inx-api-core-v0/pkg/database"
 )
@@ -39,13 +41,38 @@ func provide(c *dig.Container) error {
 		Component.LogInfo("Setting up database ...")
 		defer Component.LogInfo("Setting up database ... done!")
 
-		return database.New(
+		db, err := database.New(
 			Component.Daemon().ContextStopped(),
 			Component.Logger(),
 			ParamsDatabase.Tangle.Path,
 			ParamsDatabase.Snapshot.Path,
 			ParamsDatabase.Spent.Path,
-			ParamsDatabase.Debug)
+			hivedb.Engine(strings.ToLower(ParamsDatabase.Engine)),
+			ParamsDatabase.Debug,
+			database.CacheSizes{
+				Transactions:                   ParamsDatabase.Cache.Transactions,
+				TransactionMetadata:            ParamsDatabase.Cache.TransactionMetadata,
+				Bundles:                        ParamsDatabase.Cache.Bundles,
+				Milestones:                     ParamsDatabase.Cache.Milestones,
+				MilestoneConfirmedTransactions: ParamsDatabase.Cache.MilestoneConfirmedTransactions,
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		found, err := db.LoadIndexDatabase(ParamsDatabase.Index.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !found {
+			Component.LogInfof("No index database found at %s", ParamsDatabase.Index.Path)
+		} else {
+			Component.LogInfof("Loaded index database at %s, ledger checkpoints: %d, address milestones index: %t", ParamsDatabase.Index.Path, len(db.LedgerCheckpoints()), db.HasAddressMilestonesIndex())
+		}
+
+		return db, nil
 	})
 }
 
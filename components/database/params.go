@@ -6,6 +6,9 @@ import (
 
 // ParametersDatabase contains the definition of the parameters used by the ParametersDatabase.
 type ParametersDatabase struct {
+	// Engine defines the used database engine (auto/rocksdb/pebble).
+	Engine string `default:"auto" usage:"the used database engine (auto/rocksdb/pebble)"`
+
 	Tangle struct {
 		// Path defines the path to the database folder.
 		Path string `default:"database/tangle" usage:"the path to the tangle database folder"`
@@ -21,6 +24,24 @@ type ParametersDatabase struct {
 		Path string `default:"database/spent" usage:"the path to the spent database folder"`
 	}
 
+	Index struct {
+		// Path defines the path to the database folder.
+		Path string `default:"database/index" usage:"the path to the optional index database folder created by the tools"`
+	}
+
+	Cache struct {
+		// Transactions defines the maximum amount of cached transactions (0 = disabled).
+		Transactions int `default:"100000" usage:"the maximum amount of cached transactions (0 = disabled)"`
+		// TransactionMetadata defines the maximum amount of cached transaction metadata (0 = disabled).
+		TransactionMetadata int `default:"100000" usage:"the maximum amount of cached transaction metadata (0 = disabled)"`
+		// Bundles defines the maximum amount of cached bundles (0 = disabled).
+		Bundles int `default:"50000" usage:"the maximum amount of cached bundles (0 = disabled)"`
+		// Milestones defines the maximum amount of cached milestones (0 = disabled).
+		Milestones int `default:"10000" usage:"the maximum amount of cached milestones (0 = disabled)"`
+		// MilestoneConfirmedTransactions defines the maximum amount of transactions over all milestones whose confirmed transactions are cached (0 = disabled).
+		MilestoneConfirmedTransactions int `default:"100000" usage:"the maximum amount of transactions over all milestones whose confirmed transactions are cached (0 = disabled)"`
+	}
+
 	// Debug defines whether to ignore the check for corrupted databases (should only be used for debug reasons).
 	Debug bool `default:"false" usage:"ignore the check for corrupted databases (should only be used for debug reasons)"`
 }
@@ -17,6 +17,7 @@ import (
 	"github.com/iotaledger/hive.go/app"
 	"github.com/iotaledger/hive.go/ierrors"
 	"github.com/iotaledger/inx-api-core-v0/pkg/daemon"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
 )
 
 func init() {
@@ -33,6 +34,7 @@ func init() {
 
 type dependencies struct {
 	dig.In
+	Database       *database.Database
 	Echo           *echo.Echo
 	PrometheusEcho *echo.Echo `name:"prometheusEcho"`
 }
@@ -127,6 +129,10 @@ func registerMetrics() *prometheus.Registry {
 		registry.MustRegister(grpcprometheus.DefaultClientMetrics)
 	}
 
+	if ParamsPrometheus.DatabaseCacheMetrics {
+		registry.MustRegister(newDatabaseCacheCollector(deps.Database))
+	}
+
 	if ParamsPrometheus.RestAPIMetrics {
 		p := echoprometheus.NewPrometheus("iota_restapi", nil)
 		for _, m := range p.MetricsList {
@@ -0,0 +1,43 @@
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
+)
+
+// databaseCacheCollector exports the statistics of the object caches of the database.
+type databaseCacheCollector struct {
+	db *database.Database
+
+	size      *prometheus.Desc
+	hits      *prometheus.Desc
+	misses    *prometheus.Desc
+	evictions *prometheus.Desc
+}
+
+func newDatabaseCacheCollector(db *database.Database) *databaseCacheCollector {
+	return &databaseCacheCollector{
+		db:        db,
+		size:      prometheus.NewDesc("iota_database_cache_size", "The current amount of objects in the database cache.", []string{"cache"}, nil),
+		hits:      prometheus.NewDesc("iota_database_cache_hits_total", "The amount of lookups that were served by the database cache.", []string{"cache"}, nil),
+		misses:    prometheus.NewDesc("iota_database_cache_misses_total", "The amount of lookups that needed to load the object from the database.", []string{"cache"}, nil),
+		evictions: prometheus.NewDesc("iota_database_cache_evictions_total", "The amount of objects that were evicted from the database cache.", []string{"cache"}, nil),
+	}
+}
+
+func (c *databaseCacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.size
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.evictions
+}
+
+func (c *databaseCacheCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, metrics := range c.db.CacheMetrics() {
+		ch <- prometheus.MustNewConstMetric(c.size, prometheus.GaugeValue, float64(metrics.Size), name)
+		ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(metrics.Hits), name)
+		ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(metrics.Misses), name)
+		ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(metrics.Evictions), name)
+	}
+}
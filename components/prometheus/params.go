@@ -14,6 +14,8 @@ type ParametersPrometheus struct {
 	GoMetrics bool `default:"false" usage:"whether to include go metrics"`
 	// ProcessMetrics defines whether to include process metrics.
 	ProcessMetrics bool `default:"false" usage:"whether to include process metrics"`
+	// DatabaseCacheMetrics defines whether to include the metrics of the database caches.
+	DatabaseCacheMetrics bool `default:"true" usage:"whether to include the metrics of the database caches"`
 	// RestAPIMetrics include restAPI metrics.
 	RestAPIMetrics bool `default:"true" usage:"whether to include restAPI metrics"`
 	// INXMetrics defines whether to include INXMetrics metrics.
@@ -2,8 +2,15 @@ package main
 
 import (
 	"github.com/iotaledger/inx-api-core-v0/components/app"
+	"github.com/iotaledger/inx-api-core-v0/pkg/toolset"
 )
 
 func main() {
+	if toolset.ShouldHandleTools() {
+		toolset.HandleTools()
+
+		return
+	}
+
 	app.App().Run()
 }
@@ -0,0 +1,114 @@
+package bloom
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+)
+
+const (
+	// HashFunction describes how the bit positions of an element are derived, so that clients can rebuild the lookup.
+	// The SHA-256 digest of the element is split into two little endian uint64 values h1 and h2,
+	// the i-th bit position is (h1 + i*h2) mod bitsCount.
+	HashFunction = "sha256-double-hashing"
+)
+
+// Filter is a Bloom filter with a fixed size.
+// The bit at position i is stored in byte i/8 with the mask 1<<(i%8).
+type Filter struct {
+	bits          []byte
+	bitsCount     uint64
+	hashesCount   uint8
+	elementsCount uint64
+}
+
+// New creates a Bloom filter with the given amount of bits and hash functions.
+func New(bitsCount uint64, hashesCount uint8) *Filter {
+	if bitsCount == 0 {
+		bitsCount = 1
+	}
+	if hashesCount == 0 {
+		hashesCount = 1
+	}
+
+	return &Filter{
+		bits:          make([]byte, (bitsCount+7)/8),
+		bitsCount:     bitsCount,
+		hashesCount:   hashesCount,
+		elementsCount: 0,
+	}
+}
+
+// NewWithEstimates creates a Bloom filter that is sized for the given amount of elements and false positive rate.
+func NewWithEstimates(elementsCount uint64, falsePositiveRate float64) *Filter {
+	if elementsCount == 0 {
+		elementsCount = 1
+	}
+
+	bitsCount := uint64(math.Ceil(-float64(elementsCount) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	hashesCount := math.Round(float64(bitsCount) / float64(elementsCount) * math.Ln2)
+
+	return New(bitsCount, uint8(math.Max(1, math.Min(hashesCount, math.MaxUint8))))
+}
+
+func (f *Filter) positions(element []byte, consumer func(position uint64) bool) {
+	digest := sha256.Sum256(element)
+	h1 := binary.LittleEndian.Uint64(digest[:8])
+	h2 := binary.LittleEndian.Uint64(digest[8:16])
+
+	for i := uint64(0); i < uint64(f.hashesCount); i++ {
+		if !consumer((h1 + i*h2) % f.bitsCount) {
+			return
+		}
+	}
+}
+
+// Add adds the element to the filter.
+func (f *Filter) Add(element []byte) {
+	f.positions(element, func(position uint64) bool {
+		f.bits[position/8] |= 1 << (position % 8)
+
+		return true
+	})
+	f.elementsCount++
+}
+
+// Test returns false if the element is definitely not in the filter,
+// and true if the element is in the filter with a probability of 1-EstimatedFalsePositiveRate.
+func (f *Filter) Test(element []byte) bool {
+	contained := true
+	f.positions(element, func(position uint64) bool {
+		if f.bits[position/8]&(1<<(position%8)) == 0 {
+			contained = false
+		}
+
+		return contained
+	})
+
+	return contained
+}
+
+// Bytes returns the bits of the filter.
+func (f *Filter) Bytes() []byte {
+	return f.bits
+}
+
+// BitsCount returns the size of the filter in bits.
+func (f *Filter) BitsCount() uint64 {
+	return f.bitsCount
+}
+
+// HashesCount returns the amount of hash functions of the filter.
+func (f *Filter) HashesCount() uint8 {
+	return f.hashesCount
+}
+
+// ElementsCount returns the amount of elements that were added to the filter.
+func (f *Filter) ElementsCount() uint64 {
+	return f.elementsCount
+}
+
+// EstimatedFalsePositiveRate returns the expected false positive rate for the amount of added elements.
+func (f *Filter) EstimatedFalsePositiveRate() float64 {
+	return math.Pow(1-math.Exp(-float64(f.hashesCount)*float64(f.elementsCount)/float64(f.bitsCount)), float64(f.hashesCount))
+}
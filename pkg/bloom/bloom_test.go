@@ -0,0 +1,116 @@
+package bloom
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestFilterNoFalseNegatives(t *testing.T) {
+	const elementsCount = 10_000
+
+	filter := NewWithEstimates(elementsCount, 0.01)
+	for i := 0; i < elementsCount; i++ {
+		filter.Add([]byte(fmt.Sprintf("element-%d", i)))
+	}
+
+	if filter.ElementsCount() != elementsCount {
+		t.Fatalf("expected %d elements, got %d", elementsCount, filter.ElementsCount())
+	}
+
+	for i := 0; i < elementsCount; i++ {
+		if !filter.Test([]byte(fmt.Sprintf("element-%d", i))) {
+			t.Fatalf("false negative for element %d", i)
+		}
+	}
+
+	// the hash function is deterministic, so the amount of false positives doesn't change between runs
+	var falsePositives int
+	for i := 0; i < elementsCount; i++ {
+		if filter.Test([]byte(fmt.Sprintf("other-%d", i))) {
+			falsePositives++
+		}
+	}
+
+	if rate := float64(falsePositives) / elementsCount; rate > 0.02 {
+		t.Fatalf("false positive rate too high: %f", rate)
+	}
+}
+
+func TestNewWithEstimates(t *testing.T) {
+	tests := []struct {
+		elementsCount     uint64
+		falsePositiveRate float64
+		bitsCount         uint64
+		hashesCount       uint8
+	}{
+		{elementsCount: 1000, falsePositiveRate: 0.01, bitsCount: 9586, hashesCount: 7},
+		{elementsCount: 100_000, falsePositiveRate: 0.001, bitsCount: 1437759, hashesCount: 10},
+		{elementsCount: 1, falsePositiveRate: 0.5, bitsCount: 2, hashesCount: 1},
+		// an empty filter is sized for a single element
+		{elementsCount: 0, falsePositiveRate: 0.5, bitsCount: 2, hashesCount: 1},
+	}
+
+	for _, test := range tests {
+		filter := NewWithEstimates(test.elementsCount, test.falsePositiveRate)
+
+		if filter.BitsCount() != test.bitsCount || filter.HashesCount() != test.hashesCount {
+			t.Fatalf("unexpected size for %d elements with rate %f: %d bits, %d hashes", test.elementsCount, test.falsePositiveRate, filter.BitsCount(), filter.HashesCount())
+		}
+
+		if len(filter.Bytes()) != int((test.bitsCount+7)/8) {
+			t.Fatalf("unexpected length of the filter bytes: %d", len(filter.Bytes()))
+		}
+	}
+
+	filter := NewWithEstimates(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		filter.Add([]byte(fmt.Sprintf("element-%d", i)))
+	}
+
+	if rate := filter.EstimatedFalsePositiveRate(); math.Abs(rate-0.01) > 0.001 {
+		t.Fatalf("unexpected estimated false positive rate: %f", rate)
+	}
+}
+
+func TestNewWithoutSize(t *testing.T) {
+	filter := New(0, 0)
+
+	if filter.BitsCount() != 1 || filter.HashesCount() != 1 || len(filter.Bytes()) != 1 {
+		t.Fatalf("unexpected size: %d bits, %d hashes", filter.BitsCount(), filter.HashesCount())
+	}
+
+	filter.Add([]byte("element"))
+	if !filter.Test([]byte("element")) {
+		t.Fatal("false negative in a single bit filter")
+	}
+}
+
+// TestFilterBitPositions rebuilds the lookup like a client of the filter, following HashFunction.
+func TestFilterBitPositions(t *testing.T) {
+	const bitsCount = 1021
+
+	element := []byte("element")
+
+	filter := New(bitsCount, 5)
+	filter.Add(element)
+
+	digest := sha256.Sum256(element)
+	h1 := binary.LittleEndian.Uint64(digest[:8])
+	h2 := binary.LittleEndian.Uint64(digest[8:16])
+
+	expected := make([]byte, (bitsCount+7)/8)
+	for i := uint64(0); i < 5; i++ {
+		position := (h1 + i*h2) % bitsCount
+		expected[position/8] |= 1 << (position % 8)
+	}
+
+	bits := filter.Bytes()
+	for i := range expected {
+		if bits[i] != expected[i] {
+			t.Fatalf("unexpected filter byte %d: %08b != %08b", i, bits[i], expected[i])
+		}
+	}
+}
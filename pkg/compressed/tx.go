@@ -19,6 +19,9 @@ const (
 
 	// The max amount of bytes a signature message fragment is made up from.
 	SigDataMaxBytesLength = 1312
+
+	// the amount of trits that are encoded in a single byte in t5b1 encoding.
+	tritsPerByte = 5
 )
 
 // Expands a truncated bytes encoded transaction payload.
@@ -92,3 +95,58 @@ func TransactionFromCompressedBytes(transactionData []byte, txHash ...trinary.Ha
 
 	return tx, nil
 }
+
+// TagFromCompressedBytes returns the binary representation of the tag of a compressed transaction
+// without decoding and parsing the whole transaction.
+func TagFromCompressedBytes(transactionData []byte) ([]byte, error) {
+	txDataBytes, err := expandTx(transactionData)
+	if err != nil {
+		return nil, err
+	}
+
+	// the tag doesn't start at a byte boundary, so the bytes that contain the tag are decoded
+	firstByte := consts.TagTrinaryOffset / tritsPerByte
+	lastByte := (consts.TagTrinaryOffset + consts.TagTrinarySize + tritsPerByte - 1) / tritsPerByte
+
+	tagDataTrits := make(trinary.Trits, t5b1.DecodedLen(lastByte-firstByte))
+	if _, err := t5b1.Decode(tagDataTrits, txDataBytes[firstByte:lastByte]); err != nil {
+		return nil, err
+	}
+
+	tagOffset := consts.TagTrinaryOffset - firstByte*tritsPerByte
+
+	return t5b1.EncodeTrits(tagDataTrits[tagOffset : tagOffset+consts.TagTrinarySize]), nil
+}
+
+// Truncates the signature message fragment of a bytes encoded transaction payload.
+func truncateTx(data []byte) []byte {
+	// check how many bytes from the signature can be truncated
+	numNonZeroBytes := SigDataMaxBytesLength
+	for numNonZeroBytes > 0 {
+		if data[numNonZeroBytes-1] != 0 {
+			break
+		}
+		numNonZeroBytes--
+	}
+
+	// build up the truncated transaction payload
+	truncatedTx := make([]byte, numNonZeroBytes+NonSigTxPartBytesLength)
+	copy(truncatedTx, data[:numNonZeroBytes])
+	copy(truncatedTx[numNonZeroBytes:], data[SigDataMaxBytesLength:SigDataMaxBytesLength+NonSigTxPartBytesLength])
+
+	return truncatedTx
+}
+
+// TransactionToCompressedBytes returns the compressed bytes representation of the transaction,
+// which is the format used to store transactions in the database.
+func TransactionToCompressedBytes(tx *transaction.Transaction) ([]byte, error) {
+	txTrits, err := transaction.TransactionToTrits(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	txDataBytes := make([]byte, t5b1.EncodedLen(len(txTrits)))
+	t5b1.Encode(txDataBytes, txTrits)
+
+	return truncateTx(txDataBytes), nil
+}
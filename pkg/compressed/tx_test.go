@@ -0,0 +1,55 @@
+package compressed
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/encoding/t5b1"
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+)
+
+func testTransaction(tag trinary.Trytes) *transaction.Transaction {
+	return &transaction.Transaction{
+		SignatureMessageFragment:      strings.Repeat("9", 2187),
+		Address:                       strings.Repeat("A", consts.HashTrytesSize),
+		Value:                         0,
+		ObsoleteTag:                   strings.Repeat("B", consts.TagTrinarySize/consts.TritsPerTryte),
+		Timestamp:                     1_600_000_000,
+		CurrentIndex:                  0,
+		LastIndex:                     0,
+		Bundle:                        strings.Repeat("C", consts.HashTrytesSize),
+		TrunkTransaction:              strings.Repeat("D", consts.HashTrytesSize),
+		BranchTransaction:             strings.Repeat("E", consts.HashTrytesSize),
+		Tag:                           tag,
+		AttachmentTimestamp:           1_600_000_000_000,
+		AttachmentTimestampLowerBound: 0,
+		AttachmentTimestampUpperBound: 1_700_000_000_000,
+		Nonce:                         strings.Repeat("F", 27),
+	}
+}
+
+func TestTagFromCompressedBytes(t *testing.T) {
+	for _, tag := range []trinary.Trytes{
+		strings.Repeat("9", 27),
+		strings.Repeat("Z", 27),
+		"IOTA99999999999999999999999",
+		"ABCDEFGHIJKLMNOPQRSTUVWXYZM",
+	} {
+		data, err := TransactionToCompressedBytes(testTransaction(tag))
+		if err != nil {
+			t.Fatalf("failed to compress transaction: %v", err)
+		}
+
+		tagBytes, err := TagFromCompressedBytes(data)
+		if err != nil {
+			t.Fatalf("failed to get tag: %v", err)
+		}
+
+		if expected := t5b1.EncodeTrytes(tag); !bytes.Equal(tagBytes, expected) {
+			t.Fatalf("tag mismatch for %s: expected %x, got %x", tag, expected, tagBytes)
+		}
+	}
+}
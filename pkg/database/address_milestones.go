@@ -0,0 +1,252 @@
+package database
+
+import (
+	"context"
+	"encoding/binary"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hive.go/runtime/contextutils"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+)
+
+const (
+	addressMilestonesPrefixInfo    byte = 0
+	addressMilestonesPrefixEntries byte = 1
+
+	addressMilestonesLedgerIndexKey = "ledgerIndex"
+
+	// the amount of entries that are written to the index database in a single batch.
+	addressMilestonesBatchSize = 100_000
+)
+
+func databaseKeyForAddressMilestonesLedgerIndex() []byte {
+	return append([]byte{addressMilestonesPrefixInfo}, []byte(addressMilestonesLedgerIndexKey)...)
+}
+
+func databaseKeyPrefixForAddressMilestones(address hornet.Hash) []byte {
+	return concatBytes([]byte{addressMilestonesPrefixEntries}, databaseKeyForAddress(address))
+}
+
+// the milestone index is stored in big endian, so the entries of an address are sorted by milestone index.
+func databaseKeyForAddressMilestone(address hornet.Hash, milestoneIndex milestone.Index) []byte {
+	msIndexBytes := make([]byte, milestone.IndexByteSize)
+	binary.BigEndian.PutUint32(msIndexBytes, uint32(milestoneIndex))
+
+	return concatBytes(databaseKeyPrefixForAddressMilestones(address), msIndexBytes)
+}
+
+func (db *Database) loadAddressMilestonesIndex(store kvstore.KVStore) error {
+	value, err := store.Get(databaseKeyForAddressMilestonesLedgerIndex())
+	if err != nil {
+		if !ierrors.Is(err, kvstore.ErrKeyNotFound) {
+			return ierrors.Wrap(err, "failed to load ledger index of the address milestones index")
+		}
+
+		// the index was not built
+		return nil
+	}
+
+	if ledgerIndex := milestoneIndexFromBytes(value); ledgerIndex != db.LedgerIndex() {
+		return ierrors.Errorf("address milestones index was built for another ledger index: %d != %d", ledgerIndex, db.LedgerIndex())
+	}
+
+	db.addressMilestonesStore = store
+
+	return nil
+}
+
+// HasAddressMilestonesIndex returns whether the address milestones index is available.
+func (db *Database) HasAddressMilestonesIndex() bool {
+	return db.addressMilestonesStore != nil
+}
+
+// iterateAddressBalanceChangesFromIndex iterates over the balance changes of the given address
+// in the address milestones index from the newest to the oldest milestone.
+// The balance of the address after every change is passed to the consumer.
+// The iteration stops as soon as the consumer returns false.
+func (db *Database) iterateAddressBalanceChangesFromIndex(ctx context.Context, address hornet.Hash, consumer func(change *AddressBalanceChange) bool) error {
+
+	balance, _, err := db.BalanceForAddress(address)
+	if err != nil {
+		return err
+	}
+
+	keyPrefix := databaseKeyPrefixForAddressMilestones(address)
+
+	var innerErr error
+	if err := db.addressMilestonesStore.Iterate(keyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
+			innerErr = err
+			return false
+		}
+
+		change := &AddressBalanceChange{
+			MilestoneIndex: milestone.Index(binary.BigEndian.Uint32(key[len(keyPrefix):])),
+			Change:         diffFromBytes(value),
+			Balance:        balance,
+		}
+
+		if !consumer(change) {
+			return false
+		}
+
+		newBalance := int64(balance) - change.Change
+		if newBalance < 0 {
+			innerErr = ierrors.Wrapf(ErrCorrupted, "ledger diff for milestone %d creates negative balance for address %s: current %d, diff %d", change.MilestoneIndex, address.Trytes(), balance, -change.Change)
+			return false
+		}
+		balance = uint64(newBalance)
+
+		return true
+	}, kvstore.IterDirectionBackward); err != nil {
+		return ierrors.Wrapf(err, "failed to iterate over the address milestones index of address %s", address.Trytes())
+	}
+
+	return innerErr
+}
+
+// balanceForAddressAtMilestoneFromIndex returns the balance of the given address at the given milestone index
+// by reverting all newer balance changes from the address milestones index.
+// The iteration stops at the first balance change that is older or equal to the target index.
+func (db *Database) balanceForAddressAtMilestoneFromIndex(ctx context.Context, address hornet.Hash, targetIndex milestone.Index) (uint64, milestone.Index, error) {
+
+	balance, _, err := db.BalanceForAddress(address)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := db.iterateAddressBalanceChangesFromIndex(ctx, address, func(change *AddressBalanceChange) bool {
+		if change.MilestoneIndex <= targetIndex {
+			// the balance didn't change between this milestone and the target index
+			return false
+		}
+
+		// the change happened after the target index, so it is reverted.
+		// negative balances are detected by the iteration.
+		balance = uint64(int64(change.Balance) - change.Change)
+
+		return true
+	}); err != nil {
+		return 0, 0, err
+	}
+
+	return balance, targetIndex, nil
+}
+
+// addressBalanceChangesFromIndexInRange returns the balance changes of the given address between startIndex and endIndex (both inclusive)
+// from the address milestones index, ordered from the newest to the oldest milestone. At most maxResults changes are returned.
+func (db *Database) addressBalanceChangesFromIndexInRange(ctx context.Context, address hornet.Hash, startIndex milestone.Index, endIndex milestone.Index, maxResults int) ([]*AddressBalanceChange, error) {
+
+	result := make([]*AddressBalanceChange, 0)
+	if maxResults <= 0 {
+		return result, nil
+	}
+
+	if err := db.iterateAddressBalanceChangesFromIndex(ctx, address, func(change *AddressBalanceChange) bool {
+		if change.MilestoneIndex < startIndex {
+			return false
+		}
+
+		if change.MilestoneIndex <= endIndex {
+			result = append(result, change)
+		}
+
+		return len(result) < maxResults
+	}); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// BuildAddressMilestonesIndex walks all ledger diffs and stores for every address
+// the milestones that changed its balance in the given index database.
+// An existing index is removed before.
+func BuildAddressMilestonesIndex(ctx context.Context, log *logger.Logger, db *Database, indexDatabase kvstore.KVStore) error {
+
+	addressMilestonesStore, err := indexDatabase.WithRealm([]byte{IndexStorePrefixAddressMilestones})
+	if err != nil {
+		return err
+	}
+
+	if err := addressMilestonesStore.Clear(); err != nil {
+		return ierrors.Wrap(err, "failed to remove existing address milestones index")
+	}
+
+	batch, err := addressMilestonesStore.Batched()
+	if err != nil {
+		return err
+	}
+
+	lastStatusTime := time.Now()
+	var entriesCounter int64
+	var batchEntries int
+
+	var innerErr error
+	if err := db.ledgerDiffStore.Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		milestoneIndex := milestoneIndexFromDatabaseKey(key[:milestone.IndexByteSize])
+		address := hornet.Hash(key[milestone.IndexByteSize : milestone.IndexByteSize+hornet.HashSize])
+
+		if err := batch.Set(databaseKeyForAddressMilestone(address, milestoneIndex), value); err != nil {
+			innerErr = err
+			return false
+		}
+		entriesCounter++
+		batchEntries++
+
+		if batchEntries >= addressMilestonesBatchSize {
+			if err := batch.Commit(); err != nil {
+				innerErr = err
+				return false
+			}
+
+			if batch, err = addressMilestonesStore.Batched(); err != nil {
+				innerErr = err
+				return false
+			}
+			batchEntries = 0
+		}
+
+		// print status to show progress
+		if time.Since(lastStatusTime) >= PrintStatusInterval {
+			lastStatusTime = time.Now()
+
+			// check if the context was already canceled
+			if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
+				innerErr = err
+				return false
+			}
+
+			log.Infof("	analyzed milestone %d, stored %d entries", milestoneIndex, entriesCounter)
+		}
+
+		return true
+	}); err != nil {
+		batch.Cancel()
+		return ierrors.Wrap(err, "failed to iterate over ledger diffs")
+	}
+
+	if innerErr != nil {
+		batch.Cancel()
+		return innerErr
+	}
+
+	if err := batch.Commit(); err != nil {
+		return ierrors.Wrap(err, "failed to commit batch")
+	}
+
+	// the index is only marked as available after all entries were written
+	value := make([]byte, milestone.IndexByteSize)
+	binary.LittleEndian.PutUint32(value, uint32(db.LedgerIndex()))
+	if err := addressMilestonesStore.Set(databaseKeyForAddressMilestonesLedgerIndex(), value); err != nil {
+		return ierrors.Wrap(err, "failed to store ledger index of the address milestones index")
+	}
+
+	log.Infof("stored %d address milestones index entries", entriesCounter)
+
+	return indexDatabase.Flush()
+}
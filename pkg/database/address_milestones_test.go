@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+	"github.com/iotaledger/iota.go/consts"
+)
+
+func TestAddressBalanceChanges(t *testing.T) {
+	tests := []struct {
+		name       string
+		startIndex milestone.Index
+		endIndex   milestone.Index
+		maxResults int
+		expected   []AddressBalanceChange
+	}{
+		{
+			name:       "all changes",
+			startIndex: 1,
+			endIndex:   3,
+			maxResults: 10,
+			expected: []AddressBalanceChange{
+				{MilestoneIndex: 3, Change: -10, Balance: consts.TotalSupply - 30},
+				{MilestoneIndex: 2, Change: -20, Balance: consts.TotalSupply - 20},
+			},
+		},
+		{
+			name:       "max results",
+			startIndex: 1,
+			endIndex:   3,
+			maxResults: 1,
+			expected: []AddressBalanceChange{
+				{MilestoneIndex: 3, Change: -10, Balance: consts.TotalSupply - 30},
+			},
+		},
+		{
+			name:       "older range",
+			startIndex: 1,
+			endIndex:   2,
+			maxResults: 10,
+			expected: []AddressBalanceChange{
+				{MilestoneIndex: 2, Change: -20, Balance: consts.TotalSupply - 20},
+			},
+		},
+		{
+			name:       "no changes in range",
+			startIndex: 1,
+			endIndex:   1,
+			maxResults: 10,
+			expected:   []AddressBalanceChange{},
+		},
+	}
+
+	withIndex, _ := newTestDatabase(t)
+
+	indexStore := mapdb.NewMapDB()
+	if err := BuildAddressMilestonesIndex(context.Background(), logger.NewExampleLogger("test"), withIndex, indexStore); err != nil {
+		t.Fatalf("failed to build address milestones index: %v", err)
+	}
+	if err := withIndex.loadIndexStore(indexStore); err != nil {
+		t.Fatalf("failed to load index store: %v", err)
+	}
+
+	withoutIndex, _ := newTestDatabase(t)
+
+	for _, db := range []*Database{withIndex, withoutIndex} {
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				changes, err := db.AddressBalanceChanges(context.Background(), hornet.HashFromAddressTrytes(testAddressA), test.startIndex, test.endIndex, test.maxResults)
+				if err != nil {
+					t.Fatalf("failed to get balance changes: %v", err)
+				}
+
+				if len(changes) != len(test.expected) {
+					t.Fatalf("expected %d changes, got %d (index: %v)", len(test.expected), len(changes), db.HasAddressMilestonesIndex())
+				}
+
+				for i, change := range changes {
+					if *change != test.expected[i] {
+						t.Fatalf("expected change %+v, got %+v (index: %v)", test.expected[i], *change, db.HasAddressMilestonesIndex())
+					}
+				}
+			})
+		}
+	}
+}
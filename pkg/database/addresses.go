@@ -1,8 +1,15 @@
 package database
 
 import (
-	"github.com/iotaledger/hive.go/lo"
+	"bytes"
+	"context"
+	"sort"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/runtime/contextutils"
 	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
 )
 
 const (
@@ -49,11 +56,122 @@ func (db *Database) TransactionHashesForAddress(address hornet.Hash, valueOnly b
 	return txHashes
 }
 
-// ContainsAddress returns if the given address exists in the cache/persistence layer.
-func (db *Database) ContainsAddress(address hornet.Hash, txHash hornet.Hash, valueOnly bool) bool {
+// ContainsAddress returns if the given transaction belongs to the given address.
+func (db *Database) ContainsAddress(address hornet.Hash, txHash hornet.Hash, valueOnly bool) (bool, error) {
+	isValueFlags := []bool{false, true}
 	if valueOnly {
-		return lo.PanicOnErr(db.addressesStore.Has(databaseKeyPrefixForAddressTransaction(address, txHash, true)))
+		isValueFlags = []bool{true}
+	}
+
+	for _, isValue := range isValueFlags {
+		contains, err := db.addressesStore.Has(databaseKeyPrefixForAddressTransaction(address, txHash, isValue))
+		if err != nil {
+			return false, ierrors.Wrapf(err, "failed to check address of transaction %s", txHash.Trytes())
+		}
+
+		if contains {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// ConfirmedValueBundleTails returns the tail transaction hashes of the valid, non-conflicting value bundles
+// that contain value transactions of the given address and that were confirmed by one of the given milestones.
+// Only the cones of the given milestones are visited, so the cost doesn't grow with the history of the address.
+// The tail transaction hashes of every milestone are sorted.
+func (db *Database) ConfirmedValueBundleTails(ctx context.Context, address hornet.Hash, milestoneIndexes map[milestone.Index]struct{}) (map[milestone.Index]hornet.Hashes, error) {
+
+	tails := make(map[milestone.Index]hornet.Hashes)
+	seenTails := make(map[string]struct{})
+
+	for milestoneIndex := range milestoneIndexes {
+		confirmedTxs, err := db.MilestoneConfirmedTransactions(ctx, milestoneIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, txMeta := range confirmedTxs {
+			if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
+				return nil, err
+			}
+
+			if !txMeta.IsValue() {
+				continue
+			}
+
+			contains, err := db.addressesStore.Has(databaseKeyPrefixForAddressTransaction(address, txMeta.TxHash(), true))
+			if err != nil {
+				return nil, ierrors.Wrapf(err, "failed to check the transactions of address %s", address.Trytes())
+			}
+			if !contains {
+				continue
+			}
+
+			bndl, err := db.confirmedBundleOfTransaction(txMeta.BundleHash(), txMeta.TxHash(), milestoneIndex)
+			if err != nil {
+				return nil, err
+			}
+
+			if !bndl.IsValid() || bndl.IsValueSpam() || bndl.IsConflicting() {
+				continue
+			}
+
+			if _, seen := seenTails[string(bndl.TailHash())]; seen {
+				continue
+			}
+			seenTails[string(bndl.TailHash())] = struct{}{}
+
+			tails[milestoneIndex] = append(tails[milestoneIndex], bndl.TailHash())
+		}
+	}
+
+	for _, tailTxHashes := range tails {
+		sort.Slice(tailTxHashes, func(i, j int) bool { return bytes.Compare(tailTxHashes[i], tailTxHashes[j]) < 0 })
+	}
+
+	return tails, nil
+}
+
+// confirmedBundleOfTransaction returns the attachment of the bundle with the given hash
+// that contains the given transaction and that was confirmed by the given milestone.
+func (db *Database) confirmedBundleOfTransaction(bundleHash hornet.Hash, txHash hornet.Hash, milestoneIndex milestone.Index) (*Bundle, error) {
+
+	var tailTxHashes hornet.Hashes
+	if err := db.bundleTransactionsStore.IterateKeys(concatBytes(databaseKeyPrefixForBundleHash(bundleHash), []byte{BundleTxIsTail}), func(key kvstore.Key) bool {
+		tailTxHashes = append(tailTxHashes, hornet.Hash(key[hornet.HashSize+1:2*hornet.HashSize+1]))
+
+		return true
+	}); err != nil {
+		return nil, ierrors.Wrapf(err, "failed to iterate over the tail transactions of bundle %s", bundleHash.Trytes())
+	}
+
+	for _, tailTxHash := range tailTxHashes {
+		tailMeta, err := db.TxMetadataOrNil(tailTxHash)
+		if err != nil {
+			return nil, err
+		}
+		if tailMeta == nil {
+			return nil, ierrors.Wrapf(ErrCorrupted, "metadata not found for transaction: %s", tailTxHash.Trytes())
+		}
+
+		if confirmed, at := tailMeta.ConfirmedWithIndex(); !confirmed || at != milestoneIndex {
+			continue
+		}
+
+		bndl, err := db.BundleOrNil(tailTxHash)
+		if err != nil {
+			return nil, err
+		}
+		if bndl == nil {
+			return nil, ierrors.Wrapf(ErrCorrupted, "bundle not found for tail transaction: %s", tailTxHash.Trytes())
+		}
+
+		if _, contains := bndl.txs[string(txHash)]; contains {
+			return bndl, nil
+		}
 	}
 
-	return lo.PanicOnErr(db.addressesStore.Has(databaseKeyPrefixForAddressTransaction(address, txHash, false))) || lo.PanicOnErr(db.addressesStore.Has(databaseKeyPrefixForAddressTransaction(address, txHash, true)))
+	return nil, ierrors.Wrapf(ErrCorrupted, "no confirmed attachment of bundle %s contains transaction %s", bundleHash.Trytes(), txHash.Trytes())
 }
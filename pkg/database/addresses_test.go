@@ -0,0 +1,59 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+)
+
+func TestConfirmedValueBundleTails(t *testing.T) {
+	db, fixture := newTestDatabase(t)
+
+	for _, address := range []hornet.Hash{hornet.HashFromAddressTrytes(testAddressA), hornet.HashFromAddressTrytes(testAddressB)} {
+		tails, err := db.ConfirmedValueBundleTails(context.Background(), address, map[milestone.Index]struct{}{2: {}, 3: {}})
+		if err != nil {
+			t.Fatalf("failed to get confirmed value bundle tails: %v", err)
+		}
+
+		if len(tails) != 1 || len(tails[3]) != 1 || !bytes.Equal(tails[3][0], fixture.valueBundleTail) {
+			t.Fatalf("unexpected tails: %v", tails)
+		}
+	}
+
+	// only the requested milestones are returned
+	tails, err := db.ConfirmedValueBundleTails(context.Background(), hornet.HashFromAddressTrytes(testAddressA), map[milestone.Index]struct{}{1: {}, 2: {}})
+	if err != nil {
+		t.Fatalf("failed to get confirmed value bundle tails: %v", err)
+	}
+
+	if len(tails) != 0 {
+		t.Fatalf("expected no tails, got %v", tails)
+	}
+}
+
+func TestConfirmedValueBundleTailsConflicting(t *testing.T) {
+	db, fixture := newTestDatabase(t)
+
+	bndl, err := db.BundleOrNil(fixture.valueBundleTail)
+	if err != nil || bndl == nil {
+		t.Fatalf("failed to load the value bundle: %v", err)
+	}
+
+	bndl.metadata = bndl.metadata.ModifyBit(MetadataConflicting, true)
+	if err := db.bundleStore.Set(databaseKeyForBundle(fixture.valueBundleTail), bndl.Marshal()); err != nil {
+		t.Fatalf("failed to store the value bundle: %v", err)
+	}
+
+	// conflicting bundles didn't move any funds
+	tails, err := db.ConfirmedValueBundleTails(context.Background(), hornet.HashFromAddressTrytes(testAddressA), map[milestone.Index]struct{}{3: {}})
+	if err != nil {
+		t.Fatalf("failed to get confirmed value bundle tails: %v", err)
+	}
+
+	if len(tails) != 0 {
+		t.Fatalf("expected no tails, got %v", tails)
+	}
+}
@@ -1,7 +1,11 @@
 package database
 
 import (
-	"github.com/iotaledger/hive.go/lo"
+	"context"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/runtime/contextutils"
 	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
 )
 
@@ -23,7 +27,50 @@ func (db *Database) ApproverHashes(txHash hornet.Hash, maxFind ...int) hornet.Ha
 	return approverHashes
 }
 
-// ContainsApprover returns if the given approver exists in the cache/persistence layer.
-func (db *Database) ContainsApprover(txHash hornet.Hash, approverHash hornet.Hash) bool {
-	return lo.PanicOnErr(db.approversStore.Has(append(txHash, approverHash...)))
+// ApproverHashesAfter returns at most maxResults hashes of the approvers of the given transaction, sorted by their hash.
+// Only approvers with a hash bigger than the given cursor are returned (nil returns all).
+// The returned bool is true if there are more approvers after the returned ones.
+func (db *Database) ApproverHashesAfter(ctx context.Context, txHash hornet.Hash, cursor hornet.Hash, maxResults int) (hornet.Hashes, bool, error) {
+	approverHashes := make(hornet.Hashes, 0)
+	var moreResults bool
+
+	var afterKey kvstore.Key
+	if cursor != nil {
+		afterKey = concatBytes(txHash, cursor)
+	}
+
+	var innerErr error
+	if err := iterateKeysAfter(db.approversStore, txHash, afterKey, func(key kvstore.Key) bool {
+		if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
+			innerErr = err
+			return false
+		}
+
+		if len(approverHashes) >= maxResults {
+			moreResults = true
+			return false
+		}
+
+		approverHashes = append(approverHashes, hornet.Hash(key[hornet.HashSize:2*hornet.HashSize]))
+
+		return true
+	}, func() {}); err != nil {
+		return nil, false, ierrors.Wrapf(err, "failed to iterate approvers of transaction %s", txHash.Trytes())
+	}
+
+	if innerErr != nil {
+		return nil, false, innerErr
+	}
+
+	return approverHashes, moreResults, nil
+}
+
+// ContainsApprover returns if the given transaction is approved by the given approver.
+func (db *Database) ContainsApprover(txHash hornet.Hash, approverHash hornet.Hash) (bool, error) {
+	contains, err := db.approversStore.Has(concatBytes(txHash, approverHash))
+	if err != nil {
+		return false, ierrors.Wrapf(err, "failed to check approver %s of transaction %s", approverHash.Trytes(), txHash.Trytes())
+	}
+
+	return contains, nil
 }
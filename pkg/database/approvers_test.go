@@ -0,0 +1,66 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+)
+
+func TestApproverHashesAfter(t *testing.T) {
+	for name, newDatabase := range map[string]func(t *testing.T) (*Database, *testFixture){
+		"mapdb":  newTestDatabase,
+		"pebble": newPebbleTestDatabase,
+	} {
+		t.Run(name, func(t *testing.T) {
+			db, fixture := newDatabase(t)
+
+			// milestone 2 is approved by milestone 3 and both transactions of the value bundle
+			for _, maxResults := range []int{1, 2, 3} {
+				var approverHashes hornet.Hashes
+				var cursor hornet.Hash
+				for pages := 1; ; pages++ {
+					if pages > 3 {
+						t.Fatalf("expected at most 3 pages with maxResults %d", maxResults)
+					}
+
+					page, moreResults, err := db.ApproverHashesAfter(context.Background(), fixture.milestoneTails[2], cursor, maxResults)
+					if err != nil {
+						t.Fatalf("failed to get approvers: %v", err)
+					}
+					if len(page) > maxResults {
+						t.Fatalf("expected at most %d approvers, got %d", maxResults, len(page))
+					}
+
+					approverHashes = append(approverHashes, page...)
+					if !moreResults {
+						break
+					}
+					cursor = page[len(page)-1]
+				}
+
+				if len(approverHashes) != 3 {
+					t.Fatalf("expected 3 approvers with maxResults %d, got %d", maxResults, len(approverHashes))
+				}
+
+				for i := 1; i < len(approverHashes); i++ {
+					if bytes.Compare(approverHashes[i-1], approverHashes[i]) >= 0 {
+						t.Fatalf("expected the approvers to be sorted without duplicates with maxResults %d", maxResults)
+					}
+				}
+
+				found := make(map[string]struct{}, len(approverHashes))
+				for _, approverHash := range approverHashes {
+					found[string(approverHash)] = struct{}{}
+				}
+
+				for _, approverHash := range []hornet.Hash{fixture.milestoneTails[3], fixture.valueBundleHead, fixture.valueBundleTail} {
+					if _, exists := found[string(approverHash)]; !exists {
+						t.Fatalf("expected approver %s with maxResults %d", approverHash.Trytes(), maxResults)
+					}
+				}
+			}
+		})
+	}
+}
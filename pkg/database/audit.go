@@ -0,0 +1,606 @@
+package database
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hive.go/runtime/contextutils"
+	"github.com/iotaledger/inx-api-core-v0/pkg/compressed"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+	"github.com/iotaledger/iota.go/consts"
+)
+
+const (
+	AuditCheckDatabaseVersion         = "database-version"
+	AuditCheckLedgerIndex             = "ledger-index"
+	AuditCheckSnapshot                = "snapshot"
+	AuditCheckMilestones              = "milestones"
+	AuditCheckTransactions            = "transactions"
+	AuditCheckTransactionMetadata     = "transaction-metadata"
+	AuditCheckBundles                 = "bundles"
+	AuditCheckBundleTransactionsIndex = "bundle-transactions-index"
+	AuditCheckAddressesIndex          = "addresses-index"
+	AuditCheckTagsIndex               = "tags-index"
+	AuditCheckApproversIndex          = "approvers-index"
+	AuditCheckLedgerDiffs             = "ledger-diffs"
+	AuditCheckTotalSupply             = "total-supply"
+	AuditCheckSpentAddresses          = "spent-addresses"
+)
+
+// AuditIssue is a broken invariant that was found by the audit.
+type AuditIssue struct {
+	Key     string `json:"key"`
+	Message string `json:"message"`
+}
+
+// AuditCheckResult is the result of a single check of the audit.
+type AuditCheckResult struct {
+	Name    string `json:"name"`
+	Checked uint64 `json:"checked"`
+	Failed  uint64 `json:"failed"`
+	// Issues contains at most maxIssues of the failed entries.
+	Issues []*AuditIssue `json:"issues"`
+
+	maxIssues int
+}
+
+func (r *AuditCheckResult) addIssue(key string, format string, args ...interface{}) {
+	r.Failed++
+
+	if len(r.Issues) < r.maxIssues {
+		r.Issues = append(r.Issues, &AuditIssue{
+			Key:     key,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+}
+
+// AuditReport is the machine-readable result of the audit.
+type AuditReport struct {
+	LedgerIndex   milestone.Index     `json:"ledgerIndex"`
+	PruningIndex  milestone.Index     `json:"pruningIndex"`
+	SnapshotIndex milestone.Index     `json:"snapshotIndex"`
+	Checks        []*AuditCheckResult `json:"checks"`
+	// Passed is true if no issues were found.
+	Passed bool `json:"passed"`
+}
+
+// auditor checks the invariants of the databases without relying on the panicking read path.
+type auditor struct {
+	// db is only used to access the stores, the ledger index, the snapshot info
+	// and the solid entry points are loaded by the checks.
+	db               *Database
+	log              *logger.Logger
+	ledgerIndex      milestone.Index
+	snapshot         *SnapshotInfo
+	solidEntryPoints *SolidEntryPoints
+}
+
+// pruningIndex returns the pruning index of the snapshot info, or 0 if it couldn't be loaded.
+func (a *auditor) pruningIndex() milestone.Index {
+	if a.snapshot == nil {
+		return 0
+	}
+
+	return a.snapshot.PruningIndex
+}
+
+// Audit scans the tangle, snapshot and spent stores and checks all invariants the read path relies on.
+// The stores are not opened as a Database, so outdated database versions and missing or corrupted
+// ledger index, snapshot info or solid entry points are reported as issues instead of failing the audit.
+// Broken invariants are collected in the report, an error is only returned if the stores couldn't be read
+// or the audit was aborted. At most maxIssuesPerCheck issues are listed per check.
+func Audit(ctx context.Context, log *logger.Logger, tangleStore kvstore.KVStore, snapshotStore kvstore.KVStore, spentStore kvstore.KVStore, maxIssuesPerCheck int) (*AuditReport, error) {
+
+	a := &auditor{
+		db:               newDatabaseWithoutState(tangleStore, snapshotStore, spentStore, CacheSizes{}),
+		log:              log,
+		ledgerIndex:      0,
+		snapshot:         nil,
+		solidEntryPoints: nil,
+	}
+
+	report := &AuditReport{
+		LedgerIndex:   0,
+		PruningIndex:  0,
+		SnapshotIndex: 0,
+		Checks:        make([]*AuditCheckResult, 0),
+		Passed:        true,
+	}
+
+	checks := []struct {
+		name  string
+		check func(ctx context.Context, result *AuditCheckResult) error
+	}{
+		{AuditCheckDatabaseVersion, a.checkDatabaseVersion},
+		// the ledger index and the snapshot need to be checked first, because other checks depend on them
+		{AuditCheckLedgerIndex, a.checkLedgerIndex},
+		{AuditCheckSnapshot, a.checkSnapshot},
+		{AuditCheckMilestones, a.checkMilestones},
+		{AuditCheckTransactions, a.checkTransactions},
+		{AuditCheckTransactionMetadata, a.checkTransactionMetadata},
+		{AuditCheckBundles, a.checkBundles},
+		{AuditCheckBundleTransactionsIndex, a.checkBundleTransactionsIndex},
+		{AuditCheckAddressesIndex, a.checkAddressesIndex},
+		{AuditCheckTagsIndex, a.checkTagsIndex},
+		{AuditCheckApproversIndex, a.checkApproversIndex},
+		{AuditCheckLedgerDiffs, a.checkLedgerDiffs},
+		{AuditCheckTotalSupply, a.checkTotalSupply},
+		{AuditCheckSpentAddresses, a.checkSpentAddresses},
+	}
+
+	for _, c := range checks {
+		log.Infof("running check \"%s\"...", c.name)
+		ts := time.Now()
+
+		result := &AuditCheckResult{
+			Name:      c.name,
+			Checked:   0,
+			Failed:    0,
+			Issues:    make([]*AuditIssue, 0),
+			maxIssues: maxIssuesPerCheck,
+		}
+
+		if err := c.check(ctx, result); err != nil {
+			return nil, ierrors.Wrapf(err, "check \"%s\" failed", c.name)
+		}
+
+		log.Infof("running check \"%s\"... done! checked: %d, failed: %d, took: %v", c.name, result.Checked, result.Failed, time.Since(ts).Truncate(time.Millisecond))
+
+		report.Checks = append(report.Checks, result)
+		if result.Failed > 0 {
+			report.Passed = false
+		}
+	}
+	report.LedgerIndex = a.ledgerIndex
+	if a.snapshot != nil {
+		report.PruningIndex = a.snapshot.PruningIndex
+		report.SnapshotIndex = a.snapshot.SnapshotIndex
+	}
+
+	return report, nil
+}
+
+// iterate passes all entries of the store to the consumer and prints the progress.
+func (a *auditor) iterate(ctx context.Context, store kvstore.KVStore, result *AuditCheckResult, consumer func(key kvstore.Key, value kvstore.Value) error) error {
+
+	lastStatusTime := time.Now()
+
+	var innerErr error
+	if err := store.Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
+			innerErr = err
+			return false
+		}
+
+		result.Checked++
+
+		if err := consumer(key, value); err != nil {
+			innerErr = err
+			return false
+		}
+
+		// print status to show progress
+		if time.Since(lastStatusTime) >= PrintStatusInterval {
+			lastStatusTime = time.Now()
+			a.log.Infof("	checked %d entries, failed %d", result.Checked, result.Failed)
+		}
+
+		return true
+	}); err != nil {
+		return err
+	}
+
+	return innerErr
+}
+
+// checkReferencedTransaction checks if the transaction referenced by an index entry exists.
+func (a *auditor) checkReferencedTransaction(result *AuditCheckResult, key kvstore.Key, txHash hornet.Hash) error {
+	exists, err := a.db.txStore.Has(txHash)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		result.addIssue(hex.EncodeToString(key), "referenced transaction not found: %s", txHash.Trytes())
+	}
+
+	return nil
+}
+
+func (a *auditor) checkDatabaseVersion(_ context.Context, result *AuditCheckResult) error {
+	for _, db := range []*database{
+		{name: DatabaseNameTangle, path: "", store: a.db.tangleDatabase},
+		{name: DatabaseNameSnapshot, path: "", store: a.db.snapshotDatabase},
+		{name: DatabaseNameSpent, path: "", store: a.db.spentDatabase},
+	} {
+		result.Checked++
+
+		healthTracker, err := kvstore.NewStoreHealthTracker(db.store, kvstore.KeyPrefix{StorePrefixHealth}, DBVersion, nil)
+		if err != nil {
+			result.addIssue(db.name, "failed to read database version: %s", err)
+
+			continue
+		}
+
+		correctVersion, err := healthTracker.CheckCorrectStoreVersion()
+		if err != nil {
+			result.addIssue(db.name, "failed to check database version: %s", err)
+
+			continue
+		}
+
+		if !correctVersion {
+			result.addIssue(db.name, "database needs to be migrated to version %d with the \"migrate\" tool", DBVersion)
+		}
+
+		corrupted, err := healthTracker.IsCorrupted()
+		if err != nil {
+			return err
+		}
+		if corrupted {
+			result.addIssue(db.name, "database is marked as corrupted")
+		}
+
+		tainted, err := healthTracker.IsTainted()
+		if err != nil {
+			return err
+		}
+		if tainted {
+			result.addIssue(db.name, "database is marked as tainted")
+		}
+	}
+
+	return nil
+}
+
+func (a *auditor) checkLedgerIndex(_ context.Context, result *AuditCheckResult) error {
+	result.Checked++
+
+	value, err := a.db.ledgerStore.Get([]byte(ledgerMilestoneIndexKey))
+	if err != nil {
+		if !ierrors.Is(err, kvstore.ErrKeyNotFound) {
+			return err
+		}
+		result.addIssue(ledgerMilestoneIndexKey, "ledger index not found")
+
+		return nil
+	}
+
+	if len(value) != milestone.IndexByteSize {
+		result.addIssue(ledgerMilestoneIndexKey, "invalid length: %d", len(value))
+
+		return nil
+	}
+	a.ledgerIndex = milestoneIndexFromBytes(value)
+
+	return nil
+}
+
+func (a *auditor) checkSnapshot(_ context.Context, result *AuditCheckResult) error {
+
+	result.Checked++
+	snapshot, err := a.db.readSnapshotInfo()
+	if err != nil {
+		result.addIssue("snapshotInfo", "failed to load snapshot info: %s", err)
+	} else {
+		a.snapshot = snapshot
+
+		if a.ledgerIndex != 0 && snapshot.PruningIndex >= a.ledgerIndex {
+			result.addIssue("snapshotInfo", "pruning index %d is not below the ledger index %d", snapshot.PruningIndex, a.ledgerIndex)
+		}
+
+		if a.ledgerIndex != 0 && snapshot.SnapshotIndex > a.ledgerIndex {
+			result.addIssue("snapshotInfo", "snapshot index %d is above the ledger index %d", snapshot.SnapshotIndex, a.ledgerIndex)
+		}
+	}
+
+	result.Checked++
+	solidEntryPoints, err := a.db.readSolidEntryPoints()
+	if err != nil {
+		result.addIssue("solidEntryPoints", "failed to load solid entry points: %s", err)
+
+		return nil
+	}
+	a.solidEntryPoints = solidEntryPoints
+
+	for txHash, msIndex := range solidEntryPoints.entryPointsMap {
+		result.Checked++
+		if a.ledgerIndex != 0 && msIndex > a.ledgerIndex {
+			result.addIssue(hornet.Hash(txHash).Trytes(), "solid entry point index %d is above the ledger index %d", msIndex, a.ledgerIndex)
+		}
+	}
+
+	return nil
+}
+
+func (a *auditor) checkMilestones(ctx context.Context, result *AuditCheckResult) error {
+
+	found := make(map[milestone.Index]struct{})
+
+	if err := a.iterate(ctx, a.db.milestoneStore, result, func(key kvstore.Key, value kvstore.Value) error {
+		if len(key) != milestone.IndexByteSize || len(value) < hornet.HashSize {
+			result.addIssue(hex.EncodeToString(key), "invalid milestone entry, key length: %d, value length: %d", len(key), len(value))
+
+			return nil
+		}
+
+		ms, err := milestoneFactory(key, value)
+		if err != nil {
+			result.addIssue(hex.EncodeToString(key), "failed to parse milestone: %s", err)
+
+			return nil
+		}
+		found[ms.Index] = struct{}{}
+
+		metadataBytes, err := a.db.metadataStore.Get(ms.Hash)
+		if err != nil {
+			if !ierrors.Is(err, kvstore.ErrKeyNotFound) {
+				return err
+			}
+			result.addIssue(fmt.Sprintf("%d", ms.Index), "milestone transaction not found: %s", ms.Hash.Trytes())
+
+			return nil
+		}
+
+		if len(metadataBytes) != TransactionMetadataSize {
+			// reported by the transaction metadata check
+			return nil
+		}
+
+		txMeta, err := metadataFactory(ms.Hash, metadataBytes)
+		if err != nil {
+			return err
+		}
+
+		if !txMeta.IsMilestone() || txMeta.MilestoneIndex() != ms.Index {
+			result.addIssue(fmt.Sprintf("%d", ms.Index), "milestone transaction %s is not marked as milestone %d", ms.Hash.Trytes(), ms.Index)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// all milestones between the pruning index and the ledger index need to exist
+	for msIndex := a.pruningIndex() + 1; msIndex <= a.ledgerIndex; msIndex++ {
+		result.Checked++
+		if _, exists := found[msIndex]; !exists {
+			result.addIssue(fmt.Sprintf("%d", msIndex), "milestone not found")
+		}
+	}
+
+	return nil
+}
+
+func (a *auditor) checkTransactions(ctx context.Context, result *AuditCheckResult) error {
+	return a.iterate(ctx, a.db.txStore, result, func(key kvstore.Key, value kvstore.Value) error {
+		if len(key) != hornet.HashSize {
+			result.addIssue(hex.EncodeToString(key), "invalid key length: %d", len(key))
+
+			return nil
+		}
+
+		txHash := hornet.Hash(key)
+		if _, err := compressed.TransactionFromCompressedBytes(value, txHash.Trytes()); err != nil {
+			result.addIssue(txHash.Trytes(), "failed to decode transaction: %s", err)
+		}
+
+		exists, err := a.db.metadataStore.Has(key)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			result.addIssue(txHash.Trytes(), "transaction metadata not found")
+		}
+
+		return nil
+	})
+}
+
+func (a *auditor) checkTransactionMetadata(ctx context.Context, result *AuditCheckResult) error {
+	return a.iterate(ctx, a.db.metadataStore, result, func(key kvstore.Key, value kvstore.Value) error {
+		if len(key) != hornet.HashSize {
+			result.addIssue(hex.EncodeToString(key), "invalid key length: %d", len(key))
+
+			return nil
+		}
+
+		txHash := hornet.Hash(key)
+		if len(value) != TransactionMetadataSize {
+			result.addIssue(txHash.Trytes(), "invalid length: %d != %d", len(value), TransactionMetadataSize)
+
+			return nil
+		}
+
+		txMeta, err := metadataFactory(key, value)
+		if err != nil {
+			return err
+		}
+
+		if confirmed, at := txMeta.ConfirmedWithIndex(); confirmed && a.ledgerIndex != 0 && at > a.ledgerIndex {
+			result.addIssue(txHash.Trytes(), "confirmation index %d is above the ledger index %d", at, a.ledgerIndex)
+		}
+
+		return a.checkReferencedTransaction(result, key, txHash)
+	})
+}
+
+func (a *auditor) checkBundles(ctx context.Context, result *AuditCheckResult) error {
+	return a.iterate(ctx, a.db.bundleStore, result, func(key kvstore.Key, value kvstore.Value) error {
+		if len(key) != hornet.HashSize {
+			result.addIssue(hex.EncodeToString(key), "invalid key length: %d", len(key))
+
+			return nil
+		}
+
+		tailTxHash := hornet.Hash(key)
+		bndl, err := bundleFactory(a.db, key, value)
+		if err != nil {
+			result.addIssue(tailTxHash.Trytes(), "failed to parse bundle: %s", err)
+
+			return nil
+		}
+
+		if uint64(len(bndl.txs)) != bndl.lastIndex+1 {
+			result.addIssue(tailTxHash.Trytes(), "bundle contains %d transactions, expected %d", len(bndl.txs), bndl.lastIndex+1)
+		}
+
+		if _, exists := bndl.txs[string(bndl.tailTx)]; !exists {
+			result.addIssue(tailTxHash.Trytes(), "tail transaction is not part of the bundle transactions")
+		}
+
+		if _, exists := bndl.txs[string(bndl.headTx)]; !exists {
+			result.addIssue(tailTxHash.Trytes(), "head transaction %s is not part of the bundle transactions", bndl.headTx.Trytes())
+		}
+
+		for txHash := range bndl.txs {
+			exists, err := a.db.txStore.Has(hornet.Hash(txHash))
+			if err != nil {
+				return err
+			}
+
+			if !exists {
+				result.addIssue(tailTxHash.Trytes(), "bundle transaction not found: %s", hornet.Hash(txHash).Trytes())
+			}
+		}
+
+		var ledgerChangesSum int64
+		for _, change := range bndl.ledgerChanges {
+			ledgerChangesSum += change
+		}
+
+		if ledgerChangesSum != 0 {
+			result.addIssue(tailTxHash.Trytes(), "ledger changes do not sum up to zero: %d", ledgerChangesSum)
+		}
+
+		return nil
+	})
+}
+
+func (a *auditor) checkBundleTransactionsIndex(ctx context.Context, result *AuditCheckResult) error {
+	return a.iterate(ctx, a.db.bundleTransactionsStore, result, func(key kvstore.Key, _ kvstore.Value) error {
+		// bundleHash + isTail + txHash
+		if len(key) != 2*hornet.HashSize+1 {
+			result.addIssue(hex.EncodeToString(key), "invalid key length: %d", len(key))
+
+			return nil
+		}
+
+		return a.checkReferencedTransaction(result, key, key[50:99])
+	})
+}
+
+func (a *auditor) checkAddressesIndex(ctx context.Context, result *AuditCheckResult) error {
+	return a.iterate(ctx, a.db.addressesStore, result, func(key kvstore.Key, _ kvstore.Value) error {
+		// address + isValue + txHash
+		if len(key) != 2*hornet.HashSize+1 {
+			result.addIssue(hex.EncodeToString(key), "invalid key length: %d", len(key))
+
+			return nil
+		}
+
+		return a.checkReferencedTransaction(result, key, key[50:99])
+	})
+}
+
+func (a *auditor) checkTagsIndex(ctx context.Context, result *AuditCheckResult) error {
+	return a.iterate(ctx, a.db.tagsStore, result, func(key kvstore.Key, _ kvstore.Value) error {
+		// tag + txHash
+		if len(key) != hornet.TagSize+hornet.HashSize {
+			result.addIssue(hex.EncodeToString(key), "invalid key length: %d", len(key))
+
+			return nil
+		}
+
+		return a.checkReferencedTransaction(result, key, key[hornet.TagSize:hornet.TagSize+hornet.HashSize])
+	})
+}
+
+func (a *auditor) checkApproversIndex(ctx context.Context, result *AuditCheckResult) error {
+	return a.iterate(ctx, a.db.approversStore, result, func(key kvstore.Key, _ kvstore.Value) error {
+		// txHash + approverHash
+		if len(key) != 2*hornet.HashSize {
+			result.addIssue(hex.EncodeToString(key), "invalid key length: %d", len(key))
+
+			return nil
+		}
+
+		// the approved transaction might be a pruned solid entry point, so only the approver is checked
+		return a.checkReferencedTransaction(result, key, key[hornet.HashSize:2*hornet.HashSize])
+	})
+}
+
+func (a *auditor) checkLedgerDiffs(ctx context.Context, result *AuditCheckResult) error {
+
+	// the keys are not sorted by milestone index, because the index is stored in little endian
+	diffSums := make(map[milestone.Index]int64)
+
+	if err := a.iterate(ctx, a.db.ledgerDiffStore, result, func(key kvstore.Key, value kvstore.Value) error {
+		if len(key) != milestone.IndexByteSize+hornet.HashSize || len(value) != 8 {
+			result.addIssue(hex.EncodeToString(key), "invalid ledger diff entry, key length: %d, value length: %d", len(key), len(value))
+
+			return nil
+		}
+
+		msIndex := milestoneIndexFromDatabaseKey(key[:milestone.IndexByteSize])
+		diffSums[msIndex] += diffFromBytes(value)
+
+		if msIndex <= a.pruningIndex() || (a.ledgerIndex != 0 && msIndex > a.ledgerIndex) {
+			result.addIssue(hex.EncodeToString(key), "ledger diff for milestone %d is outside of the ledger range", msIndex)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for msIndex, diffSum := range diffSums {
+		if diffSum != 0 {
+			result.addIssue(fmt.Sprintf("%d", msIndex), "ledger diff does not sum up to zero: %d", diffSum)
+		}
+	}
+
+	return nil
+}
+
+func (a *auditor) checkTotalSupply(ctx context.Context, result *AuditCheckResult) error {
+
+	var total uint64
+	if err := a.iterate(ctx, a.db.ledgerBalanceStore, result, func(key kvstore.Key, value kvstore.Value) error {
+		if len(key) != hornet.HashSize || len(value) != 8 {
+			result.addIssue(hex.EncodeToString(key), "invalid balance entry, key length: %d, value length: %d", len(key), len(value))
+
+			return nil
+		}
+
+		total += balanceFromBytes(value)
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if total != consts.TotalSupply {
+		result.addIssue("totalSupply", "total does not match supply: %d != %d", total, consts.TotalSupply)
+	}
+
+	return nil
+}
+
+func (a *auditor) checkSpentAddresses(ctx context.Context, result *AuditCheckResult) error {
+	return a.iterate(ctx, a.db.spentAddressesStore, result, func(key kvstore.Key, _ kvstore.Value) error {
+		if len(key) != hornet.HashSize {
+			result.addIssue(hex.EncodeToString(key), "invalid key length: %d", len(key))
+		}
+
+		return nil
+	})
+}
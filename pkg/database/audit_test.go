@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/hive.go/logger"
+)
+
+// auditCheck returns the result of the check with the given name.
+func auditCheck(t *testing.T, report *AuditReport, name string) *AuditCheckResult {
+	t.Helper()
+
+	for _, check := range report.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+
+	t.Fatalf("check %s not found in report", name)
+
+	return nil
+}
+
+func runAudit(t *testing.T, db *Database) *AuditReport {
+	t.Helper()
+
+	report, err := Audit(context.Background(), logger.NewExampleLogger("test"), db.tangleDatabase, db.snapshotDatabase, db.spentDatabase, 10)
+	if err != nil {
+		t.Fatalf("failed to audit database: %v", err)
+	}
+
+	return report
+}
+
+func TestAuditPassed(t *testing.T) {
+	db, fixture := newTestDatabase(t)
+
+	report := runAudit(t, db)
+
+	for _, check := range report.Checks {
+		if check.Failed != 0 {
+			t.Fatalf("expected check %s to pass, got %d issues: %+v", check.Name, check.Failed, check.Issues[0])
+		}
+	}
+
+	if !report.Passed || report.LedgerIndex != 3 || report.SnapshotIndex != fixture.snapshotInfo.SnapshotIndex {
+		t.Fatalf("unexpected report: passed %v, ledger index %d, snapshot index %d", report.Passed, report.LedgerIndex, report.SnapshotIndex)
+	}
+}
+
+func TestAuditCorruptedState(t *testing.T) {
+	db, _ := newTestDatabase(t)
+
+	// the database can't be loaded anymore, but the audit still runs all checks
+	for _, corrupt := range []func() error{
+		func() error { return db.ledgerStore.Delete([]byte(ledgerMilestoneIndexKey)) },
+		func() error { return db.snapshotStore.Set([]byte("snapshotInfo"), []byte{1, 2, 3}) },
+		func() error { return db.snapshotStore.Delete([]byte("solidEntryPoints")) },
+	} {
+		if err := corrupt(); err != nil {
+			t.Fatalf("failed to corrupt database: %v", err)
+		}
+	}
+
+	if _, err := NewWithStores(context.Background(), nil, db.tangleDatabase, db.snapshotDatabase, db.spentDatabase, false, CacheSizes{}); err == nil {
+		t.Fatalf("expected the corrupted database to fail to load")
+	}
+
+	report := runAudit(t, db)
+
+	if report.Passed {
+		t.Fatalf("expected the audit to fail")
+	}
+
+	if check := auditCheck(t, report, AuditCheckLedgerIndex); check.Failed != 1 || check.Issues[0].Key != ledgerMilestoneIndexKey {
+		t.Fatalf("expected the missing ledger index to be reported, got %+v", check.Issues)
+	}
+
+	if check := auditCheck(t, report, AuditCheckSnapshot); check.Failed != 2 || check.Issues[0].Key != "snapshotInfo" || check.Issues[1].Key != "solidEntryPoints" {
+		t.Fatalf("expected the corrupted snapshot info and the missing solid entry points to be reported, got %+v", check.Issues)
+	}
+
+	if report.LedgerIndex != 0 || report.SnapshotIndex != 0 {
+		t.Fatalf("expected unknown ledger and snapshot index, got %d and %d", report.LedgerIndex, report.SnapshotIndex)
+	}
+
+	// the other stores are still checked
+	if check := auditCheck(t, report, AuditCheckTotalSupply); check.Checked != 2 || check.Failed != 0 {
+		t.Fatalf("expected the balances to be checked, got %d checked and %d failed", check.Checked, check.Failed)
+	}
+}
+
+func TestAuditOutdatedVersion(t *testing.T) {
+	db, _ := newTestDatabase(t)
+
+	// a store with an outdated version
+	outdatedStore := mapdb.NewMapDB()
+	if _, err := kvstore.NewStoreHealthTracker(outdatedStore, kvstore.KeyPrefix{StorePrefixHealth}, DBVersion-1, nil); err != nil {
+		t.Fatalf("failed to set database version: %v", err)
+	}
+
+	if _, err := NewWithStores(context.Background(), nil, db.tangleDatabase, db.snapshotDatabase, outdatedStore, false, CacheSizes{}); !ierrors.Is(err, ErrMigrationRequired) {
+		t.Fatalf("expected ErrMigrationRequired, got %v", err)
+	}
+
+	report, err := Audit(context.Background(), logger.NewExampleLogger("test"), db.tangleDatabase, db.snapshotDatabase, outdatedStore, 10)
+	if err != nil {
+		t.Fatalf("failed to audit database: %v", err)
+	}
+
+	if check := auditCheck(t, report, AuditCheckDatabaseVersion); check.Checked != 3 || check.Failed != 1 || check.Issues[0].Key != DatabaseNameSpent {
+		t.Fatalf("expected the outdated spent database to be reported, got %+v", check.Issues)
+	}
+
+	// the spent addresses of the outdated store are missing
+	if check := auditCheck(t, report, AuditCheckSpentAddresses); check.Checked != 0 {
+		t.Fatalf("expected no spent addresses, got %d", check.Checked)
+	}
+}
@@ -0,0 +1,322 @@
+package database
+
+import (
+	"encoding/binary"
+
+	"github.com/iotaledger/hive.go/ds/bitmask"
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/lo"
+	"github.com/iotaledger/inx-api-core-v0/pkg/compressed"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+	"github.com/iotaledger/iota.go/curl"
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+)
+
+// TransactionState contains the confirmation state of a transaction that is written by the Builder.
+type TransactionState struct {
+	// ConfirmationIndex is the index of the milestone that confirmed the transaction (0 if unconfirmed).
+	ConfirmationIndex milestone.Index
+	// Conflicting defines whether the transaction is part of a conflicting bundle.
+	Conflicting bool
+	// MilestoneIndex is the index of the milestone, in case the transaction is part of a milestone bundle.
+	MilestoneIndex milestone.Index
+}
+
+// Builder writes entries into empty tangle, snapshot and spent stores using the key layouts of the legacy databases.
+// It can be used to create databases with synthetic data, e.g. for tests or tools.
+type Builder struct {
+	tangleStore   kvstore.KVStore
+	snapshotStore kvstore.KVStore
+	spentStore    kvstore.KVStore
+
+	txStore                 kvstore.KVStore
+	metadataStore           kvstore.KVStore
+	bundleTransactionsStore kvstore.KVStore
+	addressesStore          kvstore.KVStore
+	tagsStore               kvstore.KVStore
+	milestoneStore          kvstore.KVStore
+	approversStore          kvstore.KVStore
+	spentAddressesStore     kvstore.KVStore
+	bundleStore             kvstore.KVStore
+	snapshotInfoStore       kvstore.KVStore
+	ledgerStore             kvstore.KVStore
+	ledgerBalanceStore      kvstore.KVStore
+	ledgerDiffStore         kvstore.KVStore
+}
+
+// NewBuilder creates a new Builder on top of the given writable stores.
+// The stores are marked with the current database version.
+func NewBuilder(tangleStore kvstore.KVStore, snapshotStore kvstore.KVStore, spentStore kvstore.KVStore) (*Builder, error) {
+
+	for _, store := range []kvstore.KVStore{tangleStore, snapshotStore, spentStore} {
+		// creating the health tracker sets the database version in empty stores
+		if _, err := kvstore.NewStoreHealthTracker(store, kvstore.KeyPrefix{StorePrefixHealth}, DBVersion, nil); err != nil {
+			return nil, ierrors.Wrap(err, "failed to set database version")
+		}
+	}
+
+	return &Builder{
+		tangleStore:             tangleStore,
+		snapshotStore:           snapshotStore,
+		spentStore:              spentStore,
+		txStore:                 lo.PanicOnErr(tangleStore.WithRealm([]byte{StorePrefixTransactions})),
+		metadataStore:           lo.PanicOnErr(tangleStore.WithRealm([]byte{StorePrefixTransactionMetadata})),
+		bundleTransactionsStore: lo.PanicOnErr(tangleStore.WithRealm([]byte{StorePrefixBundleTransactions})),
+		addressesStore:          lo.PanicOnErr(tangleStore.WithRealm([]byte{StorePrefixAddresses})),
+		tagsStore:               lo.PanicOnErr(tangleStore.WithRealm([]byte{StorePrefixTags})),
+		milestoneStore:          lo.PanicOnErr(tangleStore.WithRealm([]byte{StorePrefixMilestones})),
+		approversStore:          lo.PanicOnErr(tangleStore.WithRealm([]byte{StorePrefixApprovers})),
+		spentAddressesStore:     lo.PanicOnErr(spentStore.WithRealm([]byte{StorePrefixSpentAddresses})),
+		bundleStore:             lo.PanicOnErr(tangleStore.WithRealm([]byte{StorePrefixBundles})),
+		snapshotInfoStore:       lo.PanicOnErr(snapshotStore.WithRealm([]byte{StorePrefixSnapshot})),
+		ledgerStore:             lo.PanicOnErr(tangleStore.WithRealm([]byte{StorePrefixLedgerState})),
+		ledgerBalanceStore:      lo.PanicOnErr(tangleStore.WithRealm([]byte{StorePrefixLedgerBalance})),
+		ledgerDiffStore:         lo.PanicOnErr(tangleStore.WithRealm([]byte{StorePrefixLedgerDiff})),
+	}, nil
+}
+
+// StoreTransaction stores the transaction, its metadata and all index entries (bundle, address, tag and approvers).
+// If the hash of the transaction is not set, it is calculated and set in the given transaction.
+func (b *Builder) StoreTransaction(tx *transaction.Transaction, state TransactionState) (hornet.Hash, error) {
+
+	if tx.Hash == "" {
+		txTrits, err := transaction.TransactionToTrits(tx)
+		if err != nil {
+			return nil, ierrors.Wrap(err, "failed to convert transaction to trits")
+		}
+
+		hashTrits, err := curl.HashTrits(txTrits)
+		if err != nil {
+			return nil, ierrors.Wrap(err, "failed to calculate transaction hash")
+		}
+		tx.Hash = trinary.MustTritsToTrytes(hashTrits)
+	}
+
+	txBytes, err := compressed.TransactionToCompressedBytes(tx)
+	if err != nil {
+		return nil, ierrors.Wrapf(err, "failed to compress transaction %s", tx.Hash)
+	}
+
+	txHash := hornet.HashFromHashTrytes(tx.Hash)
+	trunkHash := hornet.HashFromHashTrytes(tx.TrunkTransaction)
+	branchHash := hornet.HashFromHashTrytes(tx.BranchTransaction)
+	bundleHash := hornet.HashFromHashTrytes(tx.Bundle)
+	isTail := tx.CurrentIndex == 0
+	isValue := tx.Value != 0
+
+	txMeta := NewTransactionMetadata(txHash)
+	txMeta.metadata = bitmask.BitMask(0).
+		ModifyBit(TransactionMetadataSolid, true).
+		ModifyBit(TransactionMetadataConfirmed, state.ConfirmationIndex != 0).
+		ModifyBit(TransactionMetadataConflicting, state.Conflicting).
+		ModifyBit(TransactionMetadataIsHead, tx.CurrentIndex == tx.LastIndex).
+		ModifyBit(TransactionMetadataIsTail, isTail).
+		ModifyBit(TransactionMetadataIsValue, isValue).
+		ModifyBit(TransactionMetadataIsMilestone, state.MilestoneIndex != 0)
+	txMeta.confirmationIndex = state.ConfirmationIndex
+	txMeta.trunkHash = trunkHash
+	txMeta.branchHash = branchHash
+	txMeta.bundleHash = bundleHash
+	txMeta.milestoneIndex = state.MilestoneIndex
+
+	var isTailByte byte
+	if isTail {
+		isTailByte = BundleTxIsTail
+	}
+
+	var isValueByte byte
+	if isValue {
+		isValueByte = AddressTxIsValue
+	}
+
+	entries := []struct {
+		store kvstore.KVStore
+		key   []byte
+		value []byte
+	}{
+		{b.txStore, txHash, txBytes},
+		{b.metadataStore, txHash, txMeta.Marshal()},
+		{b.bundleTransactionsStore, concatBytes(bundleHash, []byte{isTailByte}, txHash), []byte{}},
+		{b.addressesStore, concatBytes(hornet.HashFromAddressTrytes(tx.Address), []byte{isValueByte}, txHash), []byte{}},
+		{b.tagsStore, concatBytes(hornet.HashFromTagTrytes(tx.Tag), txHash), []byte{}},
+		{b.approversStore, concatBytes(trunkHash, txHash), []byte{}},
+		{b.approversStore, concatBytes(branchHash, txHash), []byte{}},
+	}
+
+	for _, entry := range entries {
+		if err := entry.store.Set(entry.key, entry.value); err != nil {
+			return nil, ierrors.Wrapf(err, "failed to store transaction %s", tx.Hash)
+		}
+	}
+
+	return txHash, nil
+}
+
+// StoreBundle stores the bundle information for the given transactions.
+// The transactions need to be stored with StoreTransaction before, because the confirmation
+// state of the bundle is derived from the metadata of the tail transaction.
+// The ledger changes are computed from the values of the transactions.
+func (b *Builder) StoreBundle(txs []*transaction.Transaction, valid bool) (hornet.Hash, error) {
+
+	if len(txs) == 0 {
+		return nil, ierrors.New("bundle has no transactions")
+	}
+
+	bndl := &Bundle{
+		txs:           make(map[string]struct{}, len(txs)),
+		ledgerChanges: make(map[string]int64),
+	}
+
+	for _, tx := range txs {
+		txHash := hornet.HashFromHashTrytes(tx.Hash)
+		bndl.txs[string(txHash)] = struct{}{}
+
+		if tx.CurrentIndex == 0 {
+			bndl.tailTx = txHash
+		}
+		if tx.CurrentIndex == tx.LastIndex {
+			bndl.headTx = txHash
+			bndl.lastIndex = tx.LastIndex
+		}
+		bndl.hash = hornet.HashFromHashTrytes(tx.Bundle)
+
+		if tx.Value != 0 {
+			bndl.ledgerChanges[string(hornet.HashFromAddressTrytes(tx.Address))] += tx.Value
+		}
+	}
+
+	if len(bndl.tailTx) == 0 || len(bndl.headTx) == 0 {
+		return nil, ierrors.Errorf("bundle %s is missing the head or tail transaction", bndl.hash.Trytes())
+	}
+
+	for address, change := range bndl.ledgerChanges {
+		if change == 0 {
+			delete(bndl.ledgerChanges, address)
+		}
+	}
+
+	tailData, err := b.metadataStore.Get(bndl.tailTx)
+	if err != nil {
+		return nil, ierrors.Wrapf(err, "failed to load metadata of tail transaction %s", bndl.tailTx.Trytes())
+	}
+
+	tailMeta, err := metadataFactory(bndl.tailTx, tailData)
+	if err != nil {
+		return nil, err
+	}
+
+	bndl.metadata = bitmask.BitMask(0).
+		ModifyBit(MetadataSolid, true).
+		ModifyBit(MetadataValid, valid).
+		ModifyBit(MetadataConfirmed, tailMeta.IsConfirmed()).
+		ModifyBit(MetadataIsMilestone, tailMeta.IsMilestone()).
+		ModifyBit(MetadataIsValueSpam, len(bndl.ledgerChanges) == 0).
+		ModifyBit(MetadataValidStrictSemantics, valid).
+		ModifyBit(MetadataConflicting, tailMeta.IsConflicting())
+
+	if err := b.bundleStore.Set(databaseKeyForBundle(bndl.tailTx), bndl.Marshal()); err != nil {
+		return nil, ierrors.Wrapf(err, "failed to store bundle %s", bndl.hash.Trytes())
+	}
+
+	return bndl.tailTx, nil
+}
+
+// StoreMilestone stores the milestone entry that points to the tail transaction of the milestone bundle.
+func (b *Builder) StoreMilestone(milestoneIndex milestone.Index, tailTxHash hornet.Hash) error {
+	if err := b.milestoneStore.Set(databaseKeyForMilestoneIndex(milestoneIndex), tailTxHash[:hornet.HashSize]); err != nil {
+		return ierrors.Wrapf(err, "failed to store milestone %d", milestoneIndex)
+	}
+
+	return nil
+}
+
+// StoreLedgerIndex stores the milestone index the ledger balances belong to.
+func (b *Builder) StoreLedgerIndex(milestoneIndex milestone.Index) error {
+	value := make([]byte, milestone.IndexByteSize)
+	binary.LittleEndian.PutUint32(value, uint32(milestoneIndex))
+
+	if err := b.ledgerStore.Set([]byte(ledgerMilestoneIndexKey), value); err != nil {
+		return ierrors.Wrap(err, "failed to store ledger milestone index")
+	}
+
+	return nil
+}
+
+// StoreLedgerBalance stores the balance of an address at the ledger index.
+func (b *Builder) StoreLedgerBalance(address hornet.Hash, balance uint64) error {
+	value := make([]byte, 8)
+	binary.LittleEndian.PutUint64(value, balance)
+
+	if err := b.ledgerBalanceStore.Set(databaseKeyForAddress(address), value); err != nil {
+		return ierrors.Wrapf(err, "failed to store balance of address %s", address.Trytes())
+	}
+
+	return nil
+}
+
+// StoreLedgerDiff stores the balance change of an address caused by the given milestone.
+func (b *Builder) StoreLedgerDiff(milestoneIndex milestone.Index, address hornet.Hash, change int64) error {
+	value := make([]byte, 8)
+	binary.LittleEndian.PutUint64(value, uint64(change))
+
+	if err := b.ledgerDiffStore.Set(databaseKeyForLedgerDiff(milestoneIndex, address), value); err != nil {
+		return ierrors.Wrapf(err, "failed to store ledger diff of address %s for milestone %d", address.Trytes(), milestoneIndex)
+	}
+
+	return nil
+}
+
+// StoreSpentAddress marks the address as spent.
+func (b *Builder) StoreSpentAddress(address hornet.Hash) error {
+	if err := b.spentAddressesStore.Set(address[:hornet.HashSize], []byte{}); err != nil {
+		return ierrors.Wrapf(err, "failed to store spent address %s", address.Trytes())
+	}
+
+	return nil
+}
+
+// StoreSnapshotInfo stores the snapshot info.
+func (b *Builder) StoreSnapshotInfo(info *SnapshotInfo) error {
+	if err := b.snapshotInfoStore.Set([]byte("snapshotInfo"), info.Bytes()); err != nil {
+		return ierrors.Wrap(err, "failed to store snapshot info")
+	}
+
+	return nil
+}
+
+// StoreSolidEntryPoints stores the solid entry points.
+func (b *Builder) StoreSolidEntryPoints(solidEntryPoints *SolidEntryPoints) error {
+	if err := b.snapshotInfoStore.Set([]byte("solidEntryPoints"), solidEntryPoints.Bytes()); err != nil {
+		return ierrors.Wrap(err, "failed to store solid entry points")
+	}
+
+	return nil
+}
+
+// Flush persists all outstanding write operations of the underlying stores.
+func (b *Builder) Flush() error {
+	for _, store := range []kvstore.KVStore{b.tangleStore, b.snapshotStore, b.spentStore} {
+		if err := store.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func concatBytes(params ...[]byte) []byte {
+	length := 0
+	for _, param := range params {
+		length += len(param)
+	}
+
+	result := make([]byte, 0, length)
+	for _, param := range params {
+		result = append(result, param...)
+	}
+
+	return result
+}
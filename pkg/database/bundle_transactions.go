@@ -1,7 +1,13 @@
 package database
 
 import (
+	"bytes"
+	"context"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/runtime/contextutils"
 	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
 )
 
 const (
@@ -35,3 +41,93 @@ func (db *Database) BundleTransactionHashes(bundleHash hornet.Hash, maxFind ...i
 
 	return bundleTransactionHashes
 }
+
+// BundleTailTransactionHashes returns at most maxResults hashes of tail transactions of the given bundle hash,
+// sorted by their hash. Every tail transaction represents a separate attachment of the bundle.
+// Only tail transactions with a hash bigger than the given cursor are returned (nil returns all).
+// The returned bool is true if there are more tail transactions after the returned ones.
+func (db *Database) BundleTailTransactionHashes(ctx context.Context, bundleHash hornet.Hash, cursor hornet.Hash, maxResults int) (hornet.Hashes, bool, error) {
+	tailTxHashes := make(hornet.Hashes, 0)
+	var moreResults bool
+
+	var innerErr error
+	if err := db.bundleTransactionsStore.IterateKeys(concatBytes(databaseKeyPrefixForBundleHash(bundleHash), []byte{BundleTxIsTail}), func(key kvstore.Key) bool {
+		if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
+			innerErr = err
+			return false
+		}
+
+		txHash := hornet.Hash(key[50:99])
+		if cursor != nil && bytes.Compare(txHash, cursor) <= 0 {
+			// skip all tail transactions that were returned before
+			return true
+		}
+
+		if len(tailTxHashes) >= maxResults {
+			moreResults = true
+			return false
+		}
+
+		tailTxHashes = append(tailTxHashes, txHash)
+
+		return true
+	}); err != nil {
+		return nil, false, err
+	}
+
+	if innerErr != nil {
+		return nil, false, innerErr
+	}
+
+	return tailTxHashes, moreResults, nil
+}
+
+// FirstConfirmedBundleTailTransactionHash returns the tail transaction of the attachment of the given bundle hash
+// that was confirmed without conflicts by the oldest milestone, and the index of that milestone.
+// All attachments of the bundle are checked. Zero-value bundles can be confirmed more than once,
+// attachments that were confirmed by the same milestone are ordered by the hash of their tail transaction.
+// It returns nil if no attachment of the bundle was confirmed without conflicts.
+func (db *Database) FirstConfirmedBundleTailTransactionHash(ctx context.Context, bundleHash hornet.Hash) (hornet.Hash, milestone.Index, error) {
+	var confirmedTailTxHash hornet.Hash
+	var confirmedIndex milestone.Index
+
+	var innerErr error
+	if err := db.bundleTransactionsStore.IterateKeys(concatBytes(databaseKeyPrefixForBundleHash(bundleHash), []byte{BundleTxIsTail}), func(key kvstore.Key) bool {
+		if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
+			innerErr = err
+			return false
+		}
+
+		txHash := hornet.Hash(key[50:99])
+
+		txMeta, err := db.TxMetadataOrNil(txHash)
+		if err != nil {
+			innerErr = err
+			return false
+		}
+		if txMeta == nil {
+			return true
+		}
+
+		confirmed, at := txMeta.ConfirmedWithIndex()
+		if !confirmed || txMeta.IsConflicting() {
+			return true
+		}
+
+		// the tail transactions are sorted by their hash, so the first one wins on equal milestones
+		if confirmedTailTxHash == nil || at < confirmedIndex {
+			confirmedTailTxHash = txHash
+			confirmedIndex = at
+		}
+
+		return true
+	}); err != nil {
+		return nil, 0, err
+	}
+
+	if innerErr != nil {
+		return nil, 0, innerErr
+	}
+
+	return confirmedTailTxHash, confirmedIndex, nil
+}
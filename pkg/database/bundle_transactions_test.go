@@ -0,0 +1,59 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+)
+
+func TestBundleTailTransactionHashes(t *testing.T) {
+	db, fixture := newTestDatabase(t)
+
+	bundleHash := hornet.HashFromHashTrytes(testValueBundleHash)
+
+	tailTxHashes, moreResults, err := db.BundleTailTransactionHashes(context.Background(), bundleHash, nil, 1)
+	if err != nil {
+		t.Fatalf("failed to get tail transactions: %v", err)
+	}
+
+	if len(tailTxHashes) != 1 || !bytes.Equal(tailTxHashes[0], fixture.valueBundleTail) {
+		t.Fatalf("expected the value tail to be returned, got %d tail transactions", len(tailTxHashes))
+	}
+
+	// the head transaction of the bundle is not a tail transaction
+	if moreResults {
+		t.Fatalf("expected no more tail transactions")
+	}
+
+	// all tail transactions were returned before the cursor
+	tailTxHashes, moreResults, err = db.BundleTailTransactionHashes(context.Background(), bundleHash, fixture.valueBundleTail, 1)
+	if err != nil {
+		t.Fatalf("failed to get tail transactions: %v", err)
+	}
+
+	if len(tailTxHashes) != 0 || moreResults {
+		t.Fatalf("expected no tail transactions after the cursor, got %d", len(tailTxHashes))
+	}
+}
+
+func TestFirstConfirmedBundleTailTransactionHash(t *testing.T) {
+	db, fixture := newTestDatabase(t)
+
+	tailTxHash, msIndex, err := db.FirstConfirmedBundleTailTransactionHash(context.Background(), hornet.HashFromHashTrytes(testValueBundleHash))
+	if err != nil {
+		t.Fatalf("failed to get confirmed tail transaction: %v", err)
+	}
+	if !bytes.Equal(tailTxHash, fixture.valueBundleTail) || msIndex != 3 {
+		t.Fatalf("expected the value tail confirmed by milestone 3, got milestone %d", msIndex)
+	}
+
+	tailTxHash, _, err = db.FirstConfirmedBundleTailTransactionHash(context.Background(), hornet.HashFromHashTrytes(testAddressB))
+	if err != nil {
+		t.Fatalf("failed to get confirmed tail transaction: %v", err)
+	}
+	if tailTxHash != nil {
+		t.Fatalf("expected no confirmed tail transaction for an unknown bundle")
+	}
+}
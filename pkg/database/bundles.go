@@ -2,7 +2,7 @@ package database
 
 import (
 	"encoding/binary"
-	"log"
+	"sort"
 	"sync"
 
 	"github.com/iotaledger/hive.go/ds/bitmask"
@@ -42,6 +42,21 @@ const (
 	MetadataInvalidPastCone      = 7
 )
 
+const (
+	// the offsets of the fields in the serialized bundle.
+	bundleOffsetMetadata           = 0
+	bundleOffsetLastIndex          = bundleOffsetMetadata + 1
+	bundleOffsetTxCount            = bundleOffsetLastIndex + 8
+	bundleOffsetLedgerChangesCount = bundleOffsetTxCount + 8
+	bundleOffsetHash               = bundleOffsetLedgerChangesCount + 8
+	bundleOffsetHeadTx             = bundleOffsetHash + hornet.HashSize
+
+	// the size of the fixed part of the serialized bundle.
+	bundleHeaderSize = bundleOffsetHeadTx + hornet.HashSize
+	// the size of a serialized ledger change (address + balance change).
+	bundleLedgerChangeSize = hornet.HashSize + 8
+)
+
 type Bundles []*Bundle
 
 type Bundle struct {
@@ -58,10 +73,55 @@ type Bundle struct {
 	txs           map[string]struct{}
 	ledgerChanges map[string]int64
 
-	milestoneIndexOnce sync.Once
+	milestoneIndexLock sync.Mutex
 	milestoneIndex     milestone.Index
 }
 
+func (bundle *Bundle) Marshal() []byte {
+
+	/*
+		 1 byte  	   				metadata
+		 8 bytes uint64 			lastIndex
+		 8 bytes uint64 			txCount
+		 8 bytes uint64 			ledgerChangesCount
+		49 bytes					bundleHash
+		49 bytes					headTx
+		49 bytes                 	txHashes		(x txCount)
+		49 bytes + 8 bytes uint64 	ledgerChanges	(x ledgerChangesCount)
+	*/
+
+	txHashes := make([]string, 0, len(bundle.txs))
+	for txHash := range bundle.txs {
+		txHashes = append(txHashes, txHash)
+	}
+	sort.Strings(txHashes)
+
+	addresses := make([]string, 0, len(bundle.ledgerChanges))
+	for address := range bundle.ledgerChanges {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	value := make([]byte, bundleHeaderSize, bundleHeaderSize+len(txHashes)*hornet.HashSize+len(addresses)*bundleLedgerChangeSize)
+	value[bundleOffsetMetadata] = byte(bundle.metadata)
+	binary.LittleEndian.PutUint64(value[bundleOffsetLastIndex:bundleOffsetTxCount], bundle.lastIndex)
+	binary.LittleEndian.PutUint64(value[bundleOffsetTxCount:bundleOffsetLedgerChangesCount], uint64(len(txHashes)))
+	binary.LittleEndian.PutUint64(value[bundleOffsetLedgerChangesCount:bundleOffsetHash], uint64(len(addresses)))
+	copy(value[bundleOffsetHash:bundleOffsetHeadTx], bundle.hash)
+	copy(value[bundleOffsetHeadTx:bundleHeaderSize], bundle.headTx)
+
+	for _, txHash := range txHashes {
+		value = append(value, txHash...)
+	}
+
+	for _, address := range addresses {
+		value = append(value, address...)
+		value = binary.LittleEndian.AppendUint64(value, uint64(bundle.ledgerChanges[address]))
+	}
+
+	return value
+}
+
 func (bundle *Bundle) Unmarshal(data []byte) error {
 
 	/*
@@ -75,14 +135,22 @@ func (bundle *Bundle) Unmarshal(data []byte) error {
 		49 bytes + 8 bytes uint64 	ledgerChanges	(x ledgerChangesCount)
 	*/
 
-	bundle.metadata = bitmask.BitMask(data[0])
-	bundle.lastIndex = binary.LittleEndian.Uint64(data[1:9])
-	txCount := int(binary.LittleEndian.Uint64(data[9:17]))
-	ledgerChangesCount := int(binary.LittleEndian.Uint64(data[17:25]))
-	bundle.hash = data[25:74]
-	bundle.headTx = data[74:123]
+	if len(data) < bundleHeaderSize {
+		return ierrors.Wrapf(ErrCorrupted, "invalid bundle length: %d < %d", len(data), bundleHeaderSize)
+	}
+
+	bundle.metadata = bitmask.BitMask(data[bundleOffsetMetadata])
+	bundle.lastIndex = binary.LittleEndian.Uint64(data[bundleOffsetLastIndex:bundleOffsetTxCount])
+	txCount := int(binary.LittleEndian.Uint64(data[bundleOffsetTxCount:bundleOffsetLedgerChangesCount]))
+	ledgerChangesCount := int(binary.LittleEndian.Uint64(data[bundleOffsetLedgerChangesCount:bundleOffsetHash]))
+
+	if expectedLength := bundleHeaderSize + txCount*hornet.HashSize + ledgerChangesCount*bundleLedgerChangeSize; txCount < 0 || ledgerChangesCount < 0 || len(data) != expectedLength {
+		return ierrors.Wrapf(ErrCorrupted, "invalid bundle length: %d, txCount: %d, ledgerChangesCount: %d", len(data), txCount, ledgerChangesCount)
+	}
+	bundle.hash = data[bundleOffsetHash:bundleOffsetHeadTx]
+	bundle.headTx = data[bundleOffsetHeadTx:bundleHeaderSize]
 
-	offset := 123
+	offset := bundleHeaderSize
 	for i := 0; i < txCount; i++ {
 		bundle.txs[string(data[offset:offset+hornet.HashSize])] = struct{}{}
 		offset += hornet.HashSize
@@ -103,49 +171,84 @@ func (bundle *Bundle) Unmarshal(data []byte) error {
 	return nil
 }
 
+func (bundle *Bundle) Hash() hornet.Hash {
+	return bundle.hash
+}
+
+func (bundle *Bundle) LastIndex() uint64 {
+	return bundle.lastIndex
+}
+
 func (bundle *Bundle) LedgerChanges() map[string]int64 {
 	return bundle.ledgerChanges
 }
 
-func (bundle *Bundle) Head() *Transaction {
-	if len(bundle.headTx) == 0 {
-		panic("head hash can never be empty")
-	}
+func (bundle *Bundle) Head() (*Transaction, error) {
+	return bundle.db.loadBundleTx(bundle.headTx, bundle.hash)
+}
 
-	return bundle.db.loadBundleTxIfExistsOrPanic(bundle.headTx, bundle.hash)
+func (bundle *Bundle) HeadHash() hornet.Hash {
+	return bundle.headTx
 }
 
 func (bundle *Bundle) TailHash() hornet.Hash {
-	if len(bundle.tailTx) == 0 {
-		panic("tail hash can never be empty")
-	}
-
 	return bundle.tailTx
 }
 
-func (bundle *Bundle) Tail() *Transaction {
-	if len(bundle.tailTx) == 0 {
-		panic("tail hash can never be empty")
-	}
-
-	return bundle.db.loadBundleTxIfExistsOrPanic(bundle.tailTx, bundle.hash)
+func (bundle *Bundle) Tail() (*Transaction, error) {
+	return bundle.db.loadBundleTx(bundle.tailTx, bundle.hash)
 }
 
-func (bundle *Bundle) Transactions() []*Transaction {
+func (bundle *Bundle) Transactions() ([]*Transaction, error) {
 
 	txs := make([]*Transaction, 0, len(bundle.txs))
 	for txHash := range bundle.txs {
-		tx := bundle.db.loadBundleTxIfExistsOrPanic(hornet.Hash(txHash), bundle.hash)
+		tx, err := bundle.db.loadBundleTx(hornet.Hash(txHash), bundle.hash)
+		if err != nil {
+			return nil, err
+		}
 		txs = append(txs, tx)
 	}
 
-	return txs
+	return txs, nil
+}
+
+// TransactionsOrdered returns the transactions of the bundle ordered by their current index.
+func (bundle *Bundle) TransactionsOrdered() ([]*Transaction, error) {
+
+	txs, err := bundle.Transactions()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(txs, func(i, j int) bool { return txs[i].Tx.CurrentIndex < txs[j].Tx.CurrentIndex })
+
+	return txs, nil
+}
+
+func (bundle *Bundle) IsSolid() bool {
+	return bundle.metadata.HasBit(MetadataSolid)
 }
 
 func (bundle *Bundle) IsValid() bool {
 	return bundle.metadata.HasBit(MetadataValid)
 }
 
+func (bundle *Bundle) IsConfirmed() bool {
+	return bundle.metadata.HasBit(MetadataConfirmed)
+}
+
+func (bundle *Bundle) IsValidStrictSemantics() bool {
+	return bundle.metadata.HasBit(MetadataValidStrictSemantics)
+}
+
+func (bundle *Bundle) IsConflicting() bool {
+	return bundle.metadata.HasBit(MetadataConflicting)
+}
+
+func (bundle *Bundle) IsInvalidPastCone() bool {
+	return bundle.metadata.HasBit(MetadataInvalidPastCone)
+}
+
 func (bundle *Bundle) IsValueSpam() bool {
 	return bundle.metadata.HasBit(MetadataIsValueSpam)
 }
@@ -154,44 +257,58 @@ func (bundle *Bundle) IsMilestone() bool {
 	return bundle.metadata.HasBit(MetadataIsMilestone)
 }
 
-func (bundle *Bundle) MilestoneIndex() milestone.Index {
-	bundle.milestoneIndexOnce.Do(func() {
-		tailTx := bundle.Tail()
-		bundle.milestoneIndex = milestone.Index(trinary.TrytesToInt(tailTx.Tx.ObsoleteTag))
-	})
+func (bundle *Bundle) MilestoneIndex() (milestone.Index, error) {
+	bundle.milestoneIndexLock.Lock()
+	defer bundle.milestoneIndexLock.Unlock()
 
-	return bundle.milestoneIndex
+	if bundle.milestoneIndex != 0 {
+		return bundle.milestoneIndex, nil
+	}
+
+	tailTx, err := bundle.Tail()
+	if err != nil {
+		return 0, err
+	}
+	bundle.milestoneIndex = milestone.Index(trinary.TrytesToInt(tailTx.Tx.ObsoleteTag))
+
+	return bundle.milestoneIndex, nil
 }
 
 func (bundle *Bundle) MilestoneHash() hornet.Hash {
 	return bundle.tailTx
 }
 
-func (db *Database) loadBundleTxIfExistsOrPanic(txHash hornet.Hash, bundleHash hornet.Hash) *Transaction {
-	tx := db.TransactionOrNil(txHash)
+func (db *Database) loadBundleTx(txHash hornet.Hash, bundleHash hornet.Hash) (*Transaction, error) {
+	tx, err := db.TransactionOrNil(txHash)
+	if err != nil {
+		return nil, err
+	}
 	if tx == nil {
-		log.Panicf("bundle %s has a reference to a non persisted transaction: %s", bundleHash.Trytes(), txHash.Trytes())
+		return nil, ierrors.Wrapf(ErrCorrupted, "bundle %s has a reference to a non persisted transaction: %s", bundleHash.Trytes(), txHash.Trytes())
 	}
 
-	return tx
+	return tx, nil
 }
 
-func (db *Database) BundleOrNil(tailTxHash hornet.Hash) *Bundle {
-	key := databaseKeyForBundle(tailTxHash)
+// BundleOrNil returns the bundle with the given tail transaction hash or nil if it doesn't exist.
+func (db *Database) BundleOrNil(tailTxHash hornet.Hash) (*Bundle, error) {
+	return db.caches.bundles.Get(string(tailTxHash), func() (*Bundle, error) {
+		key := databaseKeyForBundle(tailTxHash)
 
-	data, err := db.bundleStore.Get(key)
-	if err != nil {
-		if !ierrors.Is(err, kvstore.ErrKeyNotFound) {
-			panic(ierrors.Errorf("failed to get value from database: %w", err))
-		}
+		data, err := db.bundleStore.Get(key)
+		if err != nil {
+			if !ierrors.Is(err, kvstore.ErrKeyNotFound) {
+				return nil, ierrors.Wrap(err, "failed to get value from database")
+			}
 
-		return nil
-	}
+			return nil, nil
+		}
 
-	bundle, err := bundleFactory(db, key, data)
-	if err != nil {
-		panic(err)
-	}
+		bundle, err := bundleFactory(db, key, data)
+		if err != nil {
+			return nil, ierrors.Wrapf(err, "bundle %s", tailTxHash.Trytes())
+		}
 
-	return bundle
+		return bundle, nil
+	})
 }
@@ -0,0 +1,200 @@
+package database
+
+import (
+	"sync/atomic"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+
+	"github.com/iotaledger/hive.go/lo"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+)
+
+const (
+	// CacheNameTransactions is the name of the cache for transactions.
+	CacheNameTransactions = "transactions"
+	// CacheNameTransactionMetadata is the name of the cache for transaction metadata.
+	CacheNameTransactionMetadata = "transaction_metadata"
+	// CacheNameBundles is the name of the cache for bundles.
+	CacheNameBundles = "bundles"
+	// CacheNameMilestones is the name of the cache for milestones.
+	CacheNameMilestones = "milestones"
+	// CacheNameMilestoneConfirmedTransactions is the name of the cache for the transactions confirmed by a milestone.
+	CacheNameMilestoneConfirmedTransactions = "milestone_confirmed_transactions"
+)
+
+// CacheSizes defines the maximum amount of objects in the caches of the database.
+// A size of 0 disables the cache.
+type CacheSizes struct {
+	Transactions        int
+	TransactionMetadata int
+	Bundles             int
+	Milestones          int
+	// MilestoneConfirmedTransactions is the total amount of transactions over all cached milestones.
+	MilestoneConfirmedTransactions int
+}
+
+// CacheMetrics contains the statistics of a cache.
+type CacheMetrics struct {
+	// Size is the current amount of objects in the cache.
+	Size int
+	// Hits is the amount of lookups that were served by the cache.
+	Hits uint64
+	// Misses is the amount of lookups that needed to load the object from the database.
+	Misses uint64
+	// Evictions is the amount of objects that were removed from the cache to make room for new ones.
+	Evictions uint64
+}
+
+// objectCache is a size-bounded LRU cache for the decoded objects of the database.
+// The legacy database is immutable, so the cached objects never need to be invalidated.
+type objectCache[K comparable, T any] struct {
+	cache *lru.Cache[K, *T]
+
+	// cost returns the cost of an object if the cache is bounded by the total cost of its objects (nil otherwise).
+	cost      func(value *T) int
+	maxCost   int
+	totalCost atomic.Int64
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+// newObjectCache creates a cache with the given size, or nil if the size is 0.
+func newObjectCache[K comparable, T any](size int) *objectCache[K, T] {
+	if size <= 0 {
+		return nil
+	}
+
+	c := &objectCache[K, T]{}
+	c.cache = lo.PanicOnErr(lru.NewWithEvict[K, *T](size, func(_ K, _ *T) {
+		c.evictions.Add(1)
+	}))
+
+	return c
+}
+
+// newWeightedObjectCache creates a cache that is bounded by the total cost of the cached objects instead of their amount,
+// or nil if the maximum cost is 0. Objects that cost more than the maximum are not cached.
+func newWeightedObjectCache[K comparable, T any](maxCost int, cost func(value *T) int) *objectCache[K, T] {
+	if maxCost <= 0 {
+		return nil
+	}
+
+	c := &objectCache[K, T]{
+		cost:    cost,
+		maxCost: maxCost,
+	}
+	c.cache = lo.PanicOnErr(lru.NewWithEvict[K, *T](maxCost, func(_ K, value *T) {
+		c.evictions.Add(1)
+		c.totalCost.Add(-int64(c.cost(value)))
+	}))
+
+	return c
+}
+
+// Get returns the cached object or loads it with the given function.
+// Objects that don't exist (nil) are not cached.
+func (c *objectCache[K, T]) Get(key K, load func() (*T, error)) (*T, error) {
+	if c == nil {
+		return load()
+	}
+
+	if value, ok := c.cache.Get(key); ok {
+		c.hits.Add(1)
+
+		return value, nil
+	}
+	c.misses.Add(1)
+
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	if value != nil {
+		c.add(key, value)
+	}
+
+	return value, nil
+}
+
+// add adds the object to the cache and evicts the least recently used objects
+// until the total cost is within the limit again.
+func (c *objectCache[K, T]) add(key K, value *T) {
+	if c.cost == nil {
+		c.cache.Add(key, value)
+
+		return
+	}
+
+	cost := c.cost(value)
+	if cost > c.maxCost {
+		return
+	}
+
+	if found, _ := c.cache.ContainsOrAdd(key, value); found {
+		// the object was loaded concurrently and is already accounted for
+		return
+	}
+
+	c.totalCost.Add(int64(cost))
+	for c.totalCost.Load() > int64(c.maxCost) {
+		// the cost of the evicted object is subtracted by the eviction callback
+		if _, _, ok := c.cache.RemoveOldest(); !ok {
+			break
+		}
+	}
+}
+
+// Metrics returns the statistics of the cache.
+func (c *objectCache[K, T]) Metrics() CacheMetrics {
+	if c == nil {
+		return CacheMetrics{}
+	}
+
+	return CacheMetrics{
+		Size:      c.cache.Len(),
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+	}
+}
+
+// caches contains the object caches of the database.
+type caches struct {
+	transactions        *objectCache[string, Transaction]
+	transactionMetadata *objectCache[string, TransactionMetadata]
+	bundles             *objectCache[string, Bundle]
+	milestones          *objectCache[milestone.Index, Milestone]
+	// the walked cones are cached, so paging through the transactions of a milestone doesn't walk the cone again.
+	// the cache is bounded by the total amount of transactions, because the cones differ a lot in size.
+	milestoneConfirmedTransactions *objectCache[milestone.Index, []*TransactionMetadata]
+}
+
+// milestoneConfirmedTransactionsCost returns the amount of transactions that were confirmed by a cached milestone.
+func milestoneConfirmedTransactionsCost(confirmedTxs *[]*TransactionMetadata) int {
+	return len(*confirmedTxs)
+}
+
+func newCaches(sizes CacheSizes) *caches {
+	return &caches{
+		transactions:                   newObjectCache[string, Transaction](sizes.Transactions),
+		transactionMetadata:            newObjectCache[string, TransactionMetadata](sizes.TransactionMetadata),
+		bundles:                        newObjectCache[string, Bundle](sizes.Bundles),
+		milestones:                     newObjectCache[milestone.Index, Milestone](sizes.Milestones),
+		milestoneConfirmedTransactions: newWeightedObjectCache[milestone.Index, []*TransactionMetadata](sizes.MilestoneConfirmedTransactions, milestoneConfirmedTransactionsCost),
+	}
+}
+
+// CacheMetrics returns the statistics of all object caches of the database by their name.
+// Disabled caches report zero values.
+func (db *Database) CacheMetrics() map[string]CacheMetrics {
+	return map[string]CacheMetrics{
+		CacheNameTransactions:                   db.caches.transactions.Metrics(),
+		CacheNameTransactionMetadata:            db.caches.transactionMetadata.Metrics(),
+		CacheNameBundles:                        db.caches.bundles.Metrics(),
+		CacheNameMilestones:                     db.caches.milestones.Metrics(),
+		CacheNameMilestoneConfirmedTransactions: db.caches.milestoneConfirmedTransactions.Metrics(),
+	}
+}
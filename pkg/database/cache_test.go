@@ -0,0 +1,147 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/iotaledger/hive.go/ierrors"
+)
+
+func TestObjectCache(t *testing.T) {
+	c := newObjectCache[string, int](2)
+
+	var loads int
+	load := func(value int) func() (*int, error) {
+		return func() (*int, error) {
+			loads++
+
+			return &value, nil
+		}
+	}
+
+	checkMetrics := func(expected CacheMetrics) {
+		t.Helper()
+
+		if metrics := c.Metrics(); metrics != expected {
+			t.Fatalf("expected metrics %+v, got %+v", expected, metrics)
+		}
+	}
+
+	for _, key := range []string{"a", "b", "a"} {
+		if _, err := c.Get(key, load(1)); err != nil {
+			t.Fatalf("failed to get %s: %v", key, err)
+		}
+	}
+	checkMetrics(CacheMetrics{Size: 2, Hits: 1, Misses: 2, Evictions: 0})
+
+	// b is the least recently used object
+	if _, err := c.Get("c", load(3)); err != nil {
+		t.Fatalf("failed to get c: %v", err)
+	}
+	checkMetrics(CacheMetrics{Size: 2, Hits: 1, Misses: 3, Evictions: 1})
+
+	if value, err := c.Get("a", load(2)); err != nil || *value != 1 {
+		t.Fatalf("expected the cached value of a, got %v (%v)", value, err)
+	}
+	if loads != 3 {
+		t.Fatalf("expected 3 loads, got %d", loads)
+	}
+
+	// objects that don't exist and failed loads are not cached
+	for i := 0; i < 2; i++ {
+		if value, err := c.Get("missing", func() (*int, error) { return nil, nil }); err != nil || value != nil {
+			t.Fatalf("expected no value, got %v (%v)", value, err)
+		}
+	}
+
+	errLoad := ierrors.New("load failed")
+	if _, err := c.Get("failing", func() (*int, error) { return nil, errLoad }); !ierrors.Is(err, errLoad) {
+		t.Fatalf("expected the load error, got %v", err)
+	}
+	checkMetrics(CacheMetrics{Size: 2, Hits: 2, Misses: 6, Evictions: 1})
+}
+
+func TestObjectCacheDisabled(t *testing.T) {
+	for _, c := range []*objectCache[string, int]{
+		newObjectCache[string, int](0),
+		newWeightedObjectCache[string, int](0, func(_ *int) int { return 1 }),
+	} {
+		if c != nil {
+			t.Fatal("expected a size of 0 to disable the cache")
+		}
+
+		var loads int
+		for i := 0; i < 2; i++ {
+			if _, err := c.Get("a", func() (*int, error) {
+				loads++
+				value := 1
+
+				return &value, nil
+			}); err != nil {
+				t.Fatalf("failed to get a: %v", err)
+			}
+		}
+
+		if loads != 2 {
+			t.Fatalf("expected every lookup to load the object, got %d loads", loads)
+		}
+
+		if metrics := c.Metrics(); metrics != (CacheMetrics{}) {
+			t.Fatalf("expected no metrics of a disabled cache, got %+v", metrics)
+		}
+	}
+
+	db := &Database{caches: newCaches(CacheSizes{})}
+	for name, metrics := range db.CacheMetrics() {
+		if metrics != (CacheMetrics{}) {
+			t.Fatalf("expected no metrics of the disabled cache %s, got %+v", name, metrics)
+		}
+	}
+}
+
+func TestWeightedObjectCache(t *testing.T) {
+	c := newWeightedObjectCache[int, []int](5, func(value *[]int) int { return len(*value) })
+
+	load := func(size int) func() (*[]int, error) {
+		return func() (*[]int, error) {
+			value := make([]int, size)
+
+			return &value, nil
+		}
+	}
+
+	for key, size := range []int{3, 2} {
+		if _, err := c.Get(key, load(size)); err != nil {
+			t.Fatalf("failed to get %d: %v", key, err)
+		}
+	}
+
+	if metrics := c.Metrics(); metrics.Size != 2 || metrics.Evictions != 0 || c.totalCost.Load() != 5 {
+		t.Fatalf("expected 2 objects with a cost of 5, got %d objects with a cost of %d", metrics.Size, c.totalCost.Load())
+	}
+
+	// the least recently used object is evicted to make room
+	if _, err := c.Get(2, load(1)); err != nil {
+		t.Fatalf("failed to get 2: %v", err)
+	}
+
+	if metrics := c.Metrics(); metrics.Size != 2 || metrics.Evictions != 1 || c.totalCost.Load() != 3 {
+		t.Fatalf("expected 2 objects with a cost of 3 after 1 eviction, got %d objects with a cost of %d after %d evictions", metrics.Size, c.totalCost.Load(), metrics.Evictions)
+	}
+
+	// objects that cost more than the maximum are not cached
+	if _, err := c.Get(3, load(6)); err != nil {
+		t.Fatalf("failed to get 3: %v", err)
+	}
+
+	if metrics := c.Metrics(); metrics.Size != 2 || metrics.Evictions != 1 || c.totalCost.Load() != 3 {
+		t.Fatalf("expected the oversized object not to be cached, got %d objects with a cost of %d", metrics.Size, c.totalCost.Load())
+	}
+
+	if _, err := c.Get(1, func() (*[]int, error) {
+		t.Fatalf("expected 1 to be cached")
+
+		return nil, nil
+	}); err != nil {
+		t.Fatalf("failed to get 1: %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package database
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/runtime/contextutils"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+)
+
+// ConeDirection is the direction in which the tangle is traversed.
+type ConeDirection byte
+
+const (
+	// ConeDirectionPast walks from a transaction to its trunk and branch.
+	ConeDirectionPast ConeDirection = iota
+	// ConeDirectionFuture walks from a transaction to its approvers.
+	ConeDirectionFuture
+)
+
+// ConeNode is a transaction that was reached during the traversal of a cone.
+type ConeNode struct {
+	TxHash hornet.Hash
+	// Depth is the distance to the start transaction.
+	Depth int
+	// IsSolidEntryPoint is true if the transaction is a solid entry point, the traversal of the past cone stops there.
+	IsSolidEntryPoint bool
+	// Metadata is nil if the transaction is unknown.
+	Metadata *TransactionMetadata
+}
+
+// ConeEdge is an approval between two transactions of a cone.
+type ConeEdge struct {
+	// Approver approves the Approvee either as trunk or as branch.
+	Approver hornet.Hash
+	Approvee hornet.Hash
+	IsTrunk  bool
+}
+
+// Cone is the result of the traversal of a cone.
+type Cone struct {
+	Nodes []*ConeNode
+	Edges []*ConeEdge
+	// Truncated is true if the traversal was stopped because the maximum amount of nodes was reached.
+	Truncated bool
+}
+
+// TraverseCone walks the past or future cone of the given transaction in breadth-first order.
+// The traversal stops after maxDepth levels (0 means unlimited) or if maxNodes transactions were found.
+// Only edges between transactions that are part of the result are returned.
+func (db *Database) TraverseCone(ctx context.Context, startTxHash hornet.Hash, direction ConeDirection, maxDepth int, maxNodes int) (*Cone, error) {
+
+	cone := &Cone{
+		Nodes:     make([]*ConeNode, 0),
+		Edges:     make([]*ConeEdge, 0),
+		Truncated: false,
+	}
+
+	if maxNodes <= 0 {
+		return cone, nil
+	}
+
+	visited := make(map[string]struct{})
+
+	addNode := func(txHash hornet.Hash, depth int) (*ConeNode, error) {
+		txMeta, err := db.TxMetadataOrNil(txHash)
+		if err != nil {
+			return nil, err
+		}
+
+		node := &ConeNode{
+			TxHash:            txHash,
+			Depth:             depth,
+			IsSolidEntryPoint: db.SolidEntryPointsContain(txHash),
+			Metadata:          txMeta,
+		}
+		visited[string(txHash)] = struct{}{}
+		cone.Nodes = append(cone.Nodes, node)
+
+		return node, nil
+	}
+
+	startNode, err := addNode(startTxHash, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := []*ConeNode{startNode}
+	for len(queue) > 0 {
+		if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
+			return nil, err
+		}
+
+		node := queue[0]
+		queue = queue[1:]
+
+		if node.Metadata == nil || (maxDepth > 0 && node.Depth >= maxDepth) {
+			continue
+		}
+
+		// addNeighbor adds the edge to the neighbor and enqueues the neighbor if it was not visited before.
+		// It returns false if the maximum amount of nodes was reached.
+		addNeighbor := func(neighbor hornet.Hash, edge *ConeEdge) (bool, error) {
+			if _, exists := visited[string(neighbor)]; !exists {
+				if len(cone.Nodes) >= maxNodes {
+					cone.Truncated = true
+
+					return false, nil
+				}
+
+				neighborNode, err := addNode(neighbor, node.Depth+1)
+				if err != nil {
+					return false, err
+				}
+				queue = append(queue, neighborNode)
+			}
+			cone.Edges = append(cone.Edges, edge)
+
+			return true, nil
+		}
+
+		switch direction {
+		case ConeDirectionPast:
+			if node.IsSolidEntryPoint {
+				continue
+			}
+
+			trunkHash := node.Metadata.TrunkHash()
+			branchHash := node.Metadata.BranchHash()
+
+			added, err := addNeighbor(trunkHash, &ConeEdge{Approver: node.TxHash, Approvee: trunkHash, IsTrunk: true})
+			if err != nil {
+				return nil, err
+			}
+			if !added {
+				return cone, nil
+			}
+
+			if !bytes.Equal(branchHash, trunkHash) {
+				added, err := addNeighbor(branchHash, &ConeEdge{Approver: node.TxHash, Approvee: branchHash, IsTrunk: false})
+				if err != nil {
+					return nil, err
+				}
+				if !added {
+					return cone, nil
+				}
+			}
+
+		case ConeDirectionFuture:
+			// the approvers are iterated one by one, so transactions with many approvers
+			// are not loaded completely if the maximum amount of nodes is reached.
+			var innerErr error
+			if err := db.approversStore.IterateKeys(node.TxHash, func(key kvstore.Key) bool {
+				approverHash := hornet.Hash(key[hornet.HashSize : 2*hornet.HashSize])
+
+				approverMeta, err := db.TxMetadataOrNil(approverHash)
+				if err != nil {
+					innerErr = err
+					return false
+				}
+				isTrunk := approverMeta != nil && bytes.Equal(approverMeta.TrunkHash(), node.TxHash)
+
+				added, err := addNeighbor(approverHash, &ConeEdge{Approver: approverHash, Approvee: node.TxHash, IsTrunk: isTrunk})
+				if err != nil {
+					innerErr = err
+					return false
+				}
+
+				return added
+			}); err != nil {
+				return nil, err
+			}
+
+			if innerErr != nil {
+				return nil, innerErr
+			}
+
+			if cone.Truncated {
+				return cone, nil
+			}
+		}
+	}
+
+	return cone, nil
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTraverseCone(t *testing.T) {
+	db, fixture := newTestDatabase(t)
+
+	tests := []struct {
+		name              string
+		direction         ConeDirection
+		maxNodes          int
+		expectedNodes     int
+		expectedTruncated bool
+	}{
+		// milestones 1 to 3 and both transactions of the value bundle
+		{name: "future cone", direction: ConeDirectionFuture, maxNodes: 100, expectedNodes: 5, expectedTruncated: false},
+		{name: "truncated future cone", direction: ConeDirectionFuture, maxNodes: 3, expectedNodes: 3, expectedTruncated: true},
+		// milestone 1 and the solid entry point
+		{name: "past cone", direction: ConeDirectionPast, maxNodes: 100, expectedNodes: 2, expectedTruncated: false},
+		{name: "truncated past cone", direction: ConeDirectionPast, maxNodes: 1, expectedNodes: 1, expectedTruncated: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cone, err := db.TraverseCone(context.Background(), fixture.milestoneTails[1], test.direction, 0, test.maxNodes)
+			if err != nil {
+				t.Fatalf("failed to traverse cone: %v", err)
+			}
+
+			if len(cone.Nodes) != test.expectedNodes {
+				t.Fatalf("expected %d nodes, got %d", test.expectedNodes, len(cone.Nodes))
+			}
+
+			if cone.Truncated != test.expectedTruncated {
+				t.Fatalf("expected truncated to be %v", test.expectedTruncated)
+			}
+
+			// only edges between returned nodes are returned
+			nodes := make(map[string]struct{})
+			for _, node := range cone.Nodes {
+				nodes[string(node.TxHash)] = struct{}{}
+			}
+			for _, edge := range cone.Edges {
+				if _, exists := nodes[string(edge.Approver)]; !exists {
+					t.Fatalf("edge approver %s is not part of the cone", edge.Approver.Trytes())
+				}
+				if _, exists := nodes[string(edge.Approvee)]; !exists {
+					t.Fatalf("edge approvee %s is not part of the cone", edge.Approvee.Trytes())
+				}
+			}
+		})
+	}
+}
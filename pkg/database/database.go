@@ -18,8 +18,8 @@ import (
 const (
 	DBVersion = 3
 
-	// printStatusInterval is the interval for printing status messages.
-	printStatusInterval = 2 * time.Second
+	// PrintStatusInterval is the interval for printing status messages.
+	PrintStatusInterval = 2 * time.Second
 )
 
 const (
@@ -41,11 +41,25 @@ const (
 	StorePrefixSpentAddresses          byte = 15
 	StorePrefixAutopeering             byte = 16 // unused
 	StorePrefixWhiteFlag               byte = 17 // unused
+	StorePrefixMigration               byte = 18
 )
 
 var (
+	// ErrNotFound is returned when the requested entry doesn't exist.
+	ErrNotFound = ierrors.New("not found")
+	// ErrCorrupted is returned when an entry of the database can't be parsed or breaks an invariant.
+	ErrCorrupted = ierrors.New("database is corrupted")
+	// ErrOutOfRange is returned when the requested milestone index is outside of the milestone range of the database.
+	ErrOutOfRange = ierrors.New("out of range")
 	// ErrOperationAborted is returned when the operation was aborted e.g. by a shutdown signal.
 	ErrOperationAborted = ierrors.New("operation was aborted")
+	// ErrMilestoneNotFound is returned when the requested milestone doesn't exist.
+	ErrMilestoneNotFound = ierrors.Wrap(ErrNotFound, "milestone not found")
+	// ErrLedgerIndexMismatch is returned when the ledger index doesn't match the solid milestone index,
+	// so the ledger diffs can't be applied to the stored balances.
+	ErrLedgerIndexMismatch = ierrors.New("ledger index doesn't match the solid milestone index")
+	// ErrEngineNotSupported is returned when the databases can't be opened with the given database engine.
+	ErrEngineNotSupported = ierrors.New("database engine not supported")
 )
 
 type Database struct {
@@ -53,6 +67,7 @@ type Database struct {
 	tangleDatabase   kvstore.KVStore
 	snapshotDatabase kvstore.KVStore
 	spentDatabase    kvstore.KVStore
+	indexDatabase    kvstore.KVStore
 
 	// kv stores
 	txStore                 kvstore.KVStore
@@ -68,168 +83,152 @@ type Database struct {
 	ledgerStore             kvstore.KVStore
 	ledgerBalanceStore      kvstore.KVStore
 	ledgerDiffStore         kvstore.KVStore
+	ledgerCheckpointsStore  kvstore.KVStore
+	addressMilestonesStore  kvstore.KVStore
 
 	// solid entry points
 	solidEntryPoints *SolidEntryPoints
 
+	// ledger checkpoints
+	ledgerCheckpoints []milestone.Index
+
 	// snapshot info
 	snapshot *SnapshotInfo
 
 	// syncstate
 	syncState     *SyncState
-	syncStateOnce sync.Once
+	syncStateLock sync.Mutex
 
-	ledgerMilestoneIndex     milestone.Index
-	ledgerMilestoneIndexOnce sync.Once
+	ledgerMilestoneIndex milestone.Index
 
 	latestSolidMilestoneBundle     *Bundle
-	latestSolidMilestoneBundleOnce sync.Once
-}
+	latestSolidMilestoneBundleLock sync.Mutex
 
-func New(ctx context.Context, log *logger.Logger, tangleDatabasePath string, snapshotDatabasePath string, spentDatabasePath string, skipHealthCheck bool) (*Database, error) {
+	// object caches
+	caches *caches
 
-	type database struct {
-		name        string
-		path        string
-		upgradeFunc func(context.Context, *logger.Logger, kvstore.KVStore, byte, byte) error
-		store       kvstore.KVStore
-	}
+	// the running walks of milestone cones, shared by concurrent requests for the same milestone
+	milestoneConeWalks sharedCalls[milestone.Index, *[]*TransactionMetadata]
+}
 
+type database struct {
+	name  string
+	path  string
+	store kvstore.KVStore
+}
+
+func newDatabases(tangleDatabasePath string, snapshotDatabasePath string, spentDatabasePath string) (*database, *database, *database) {
 	tangleDatabase := &database{
-		name:        "tangle",
-		path:        tangleDatabasePath,
-		upgradeFunc: migrateTangleDatabaseFunc,
-		store:       nil,
+		name:  DatabaseNameTangle,
+		path:  tangleDatabasePath,
+		store: nil,
 	}
 
 	snapshotDatabase := &database{
-		name: "snapshot",
-		path: snapshotDatabasePath,
-		upgradeFunc: func(_ context.Context, _ *logger.Logger, _ kvstore.KVStore, oldVersion byte, newVersion byte) error {
-			return supportedDatabaseVersionUpgradeFunc(oldVersion, newVersion)
-		},
+		name:  DatabaseNameSnapshot,
+		path:  snapshotDatabasePath,
 		store: nil,
 	}
 
 	spentDatabase := &database{
-		name: "spent",
-		path: spentDatabasePath,
-		upgradeFunc: func(_ context.Context, _ *logger.Logger, _ kvstore.KVStore, oldVersion byte, newVersion byte) error {
-			return supportedDatabaseVersionUpgradeFunc(oldVersion, newVersion)
-		},
+		name:  DatabaseNameSpent,
+		path:  spentDatabasePath,
 		store: nil,
 	}
 
-	checkDatabaseVersionAndHealth := func(store kvstore.KVStore, consumer func(healthTracker *kvstore.StoreHealthTracker) error, storeVersionUpdateFunc kvstore.StoreVersionUpdateFunc) error {
-		healthTracker, err := kvstore.NewStoreHealthTracker(store, kvstore.KeyPrefix{StorePrefixHealth}, DBVersion, storeVersionUpdateFunc)
-		if err != nil {
-			return err
-		}
+	return tangleDatabase, snapshotDatabase, spentDatabase
+}
 
-		if !skipHealthCheck {
-			if lo.PanicOnErr(healthTracker.IsCorrupted()) {
-				return ierrors.New("database is corrupted")
-			}
+func checkDatabaseVersionAndHealth(store kvstore.KVStore, skipHealthCheck bool, consumer func(healthTracker *kvstore.StoreHealthTracker) error, storeVersionUpdateFunc kvstore.StoreVersionUpdateFunc) error {
+	healthTracker, err := kvstore.NewStoreHealthTracker(store, kvstore.KeyPrefix{StorePrefixHealth}, DBVersion, storeVersionUpdateFunc)
+	if err != nil {
+		return err
+	}
 
-			if lo.PanicOnErr(healthTracker.IsTainted()) {
-				return ierrors.New("database is tainted")
-			}
+	if !skipHealthCheck {
+		if lo.PanicOnErr(healthTracker.IsCorrupted()) {
+			return ierrors.New("database is corrupted")
 		}
 
-		return consumer(healthTracker)
+		if lo.PanicOnErr(healthTracker.IsTainted()) {
+			return ierrors.New("database is tainted")
+		}
 	}
 
-	for _, db := range []*database{tangleDatabase, snapshotDatabase, spentDatabase} {
-		// open the database in readonly mode first
-		store, err := engine.StoreWithDefaultSettings(db.path, false, hivedb.EngineAuto, true, engine.AllowedEnginesStorageAuto...)
+	return consumer(healthTracker)
+}
+
+// checkDatabaseVersion checks the health of the database and returns ErrMigrationRequired if the database version is outdated.
+// Databases are never upgraded implicitly, they need to be migrated with the "migrate" tool instead.
+func checkDatabaseVersion(db *database, skipHealthCheck bool) error {
+	return checkDatabaseVersionAndHealth(db.store, skipHealthCheck, func(healthTracker *kvstore.StoreHealthTracker) error {
+		correctVersion, err := healthTracker.CheckCorrectStoreVersion()
 		if err != nil {
-			return nil, ierrors.Wrapf(err, "failed to open %s database", db.name)
+			return err
 		}
-		db.store = store
 
-		// check if we need to upgrade the database
-		upgradeNeeded := false
-		if err := checkDatabaseVersionAndHealth(store, func(healthTracker *kvstore.StoreHealthTracker) error {
-			correctVersion, err := healthTracker.CheckCorrectStoreVersion()
-			if err != nil {
-				return err
-			}
-			upgradeNeeded = !correctVersion
-
-			return nil
-		}, nil); err != nil {
-			return nil, ierrors.Errorf("failed to check %s database health: %w", db.name, err)
+		if !correctVersion {
+			return ierrors.Wrapf(ErrMigrationRequired, "%s database needs to be migrated to version %d with the \"migrate\" tool", db.name, DBVersion)
 		}
 
-		if !upgradeNeeded {
-			continue
-		}
+		return nil
+	}, nil)
+}
 
-		log.Infof("Upgrading %s database...", db.name)
+// New opens the tangle, snapshot and spent databases with the given database engine.
+// The decoded objects are cached with the given cache sizes.
+// It returns ErrMigrationRequired if any of the databases has an outdated version.
+// The in-memory mapdb engine is rejected with ErrEngineNotSupported, because the databases would always be empty.
+// In-memory databases are created with NewWithStores instead.
+func New(_ context.Context, _ *logger.Logger, tangleDatabasePath string, snapshotDatabasePath string, spentDatabasePath string, dbEngine hivedb.Engine, skipHealthCheck bool, cacheSizes CacheSizes) (*Database, error) {
 
-		// to be able to upgrade, we need to close the database and reopen it in read/write mode afterwards
-		if err := store.Close(); err != nil {
-			return nil, ierrors.Errorf("failed to close %s database: %w", db.name, err)
-		}
+	if dbEngine == hivedb.EngineMapDB {
+		return nil, ierrors.Wrapf(ErrEngineNotSupported, "%s: the databases are read-only and would be empty, use rocksdb or pebble", dbEngine)
+	}
 
-		// open the database in read/write mode
-		store, err = engine.StoreWithDefaultSettings(db.path, false, hivedb.EngineAuto, false, engine.AllowedEnginesStorageAuto...)
+	tangleDatabase, snapshotDatabase, spentDatabase := newDatabases(tangleDatabasePath, snapshotDatabasePath, spentDatabasePath)
+
+	for _, db := range []*database{tangleDatabase, snapshotDatabase, spentDatabase} {
+		// the databases are opened in readonly mode, because the API never writes to them
+		store, err := engine.StoreWithDefaultSettings(db.path, false, dbEngine, true, engine.AllowedEnginesStorageAuto...)
 		if err != nil {
 			return nil, ierrors.Wrapf(err, "failed to open %s database", db.name)
 		}
+		db.store = store
 
-		// execute the upgrade function
-		if err := checkDatabaseVersionAndHealth(store, func(healthTracker *kvstore.StoreHealthTracker) error {
-			return lo.Return2(healthTracker.UpdateStoreVersion())
-		}, func(oldVersion, newVersion byte) error {
-			//nolint:scopelint
-			return db.upgradeFunc(ctx, log, store, oldVersion, newVersion)
-		}); err != nil {
-			return nil, ierrors.Errorf("failed to upgrade %s database version: %w", db.name, err)
+		if err := checkDatabaseVersion(db, skipHealthCheck); err != nil {
+			return nil, ierrors.Wrapf(err, "failed to check %s database health", db.name)
 		}
+	}
 
-		// close the database again
-		if err := store.Close(); err != nil {
-			return nil, ierrors.Errorf("failed to close %s database: %w", db.name, err)
-		}
+	return newDatabase(tangleDatabase.store, snapshotDatabase.store, spentDatabase.store, cacheSizes)
+}
 
-		// open the database in readonly mode again
-		store, err = engine.StoreWithDefaultSettings(db.path, false, hivedb.EngineAuto, true, engine.AllowedEnginesStorageAuto...)
-		if err != nil {
-			return nil, ierrors.Wrapf(err, "failed to open %s database", db.name)
-		}
-		db.store = store
+// NewWithStores creates a Database on top of already opened stores, e.g. in-memory stores filled with synthetic data.
+// It returns ErrMigrationRequired if any of the stores has an outdated version.
+func NewWithStores(_ context.Context, _ *logger.Logger, tangleStore kvstore.KVStore, snapshotStore kvstore.KVStore, spentStore kvstore.KVStore, skipHealthCheck bool, cacheSizes CacheSizes) (*Database, error) {
 
-		log.Infof("Upgrading %s database...done!", db.name)
-	}
+	tangleDatabase, snapshotDatabase, spentDatabase := newDatabases("", "", "")
+	tangleDatabase.store = tangleStore
+	snapshotDatabase.store = snapshotStore
+	spentDatabase.store = spentStore
 
-	db := &Database{
-		tangleDatabase:                 tangleDatabase.store,
-		snapshotDatabase:               snapshotDatabase.store,
-		spentDatabase:                  spentDatabase.store,
-		txStore:                        lo.PanicOnErr(tangleDatabase.store.WithRealm([]byte{StorePrefixTransactions})),
-		metadataStore:                  lo.PanicOnErr(tangleDatabase.store.WithRealm([]byte{StorePrefixTransactionMetadata})),
-		addressesStore:                 lo.PanicOnErr(tangleDatabase.store.WithRealm([]byte{StorePrefixAddresses})),
-		approversStore:                 lo.PanicOnErr(tangleDatabase.store.WithRealm([]byte{StorePrefixApprovers})),
-		bundleStore:                    lo.PanicOnErr(tangleDatabase.store.WithRealm([]byte{StorePrefixBundles})),
-		bundleTransactionsStore:        lo.PanicOnErr(tangleDatabase.store.WithRealm([]byte{StorePrefixBundleTransactions})),
-		milestoneStore:                 lo.PanicOnErr(tangleDatabase.store.WithRealm([]byte{StorePrefixMilestones})),
-		spentAddressesStore:            lo.PanicOnErr(spentDatabase.store.WithRealm([]byte{StorePrefixSpentAddresses})),
-		tagsStore:                      lo.PanicOnErr(tangleDatabase.store.WithRealm([]byte{StorePrefixTags})),
-		snapshotStore:                  lo.PanicOnErr(snapshotDatabase.store.WithRealm([]byte{StorePrefixSnapshot})),
-		ledgerStore:                    lo.PanicOnErr(tangleDatabase.store.WithRealm([]byte{StorePrefixLedgerState})),
-		ledgerBalanceStore:             lo.PanicOnErr(tangleDatabase.store.WithRealm([]byte{StorePrefixLedgerBalance})),
-		ledgerDiffStore:                lo.PanicOnErr(tangleDatabase.store.WithRealm([]byte{StorePrefixLedgerDiff})),
-		solidEntryPoints:               nil,
-		snapshot:                       nil,
-		syncState:                      nil,
-		syncStateOnce:                  sync.Once{},
-		ledgerMilestoneIndex:           0,
-		ledgerMilestoneIndexOnce:       sync.Once{},
-		latestSolidMilestoneBundle:     nil,
-		latestSolidMilestoneBundleOnce: sync.Once{},
+	for _, db := range []*database{tangleDatabase, snapshotDatabase, spentDatabase} {
+		if err := checkDatabaseVersion(db, skipHealthCheck); err != nil {
+			return nil, ierrors.Wrapf(err, "failed to check %s database version", db.name)
+		}
 	}
 
+	return newDatabase(tangleStore, snapshotStore, spentStore, cacheSizes)
+}
+
+func newDatabase(tangleStore kvstore.KVStore, snapshotStore kvstore.KVStore, spentStore kvstore.KVStore, cacheSizes CacheSizes) (*Database, error) {
+
+	db := newDatabaseWithoutState(tangleStore, snapshotStore, spentStore, cacheSizes)
+
+	if err := db.loadLedgerIndex(); err != nil {
+		return nil, err
+	}
 	if err := db.loadSnapshotInfo(); err != nil {
 		return nil, err
 	}
@@ -240,6 +239,43 @@ func New(ctx context.Context, log *logger.Logger, tangleDatabasePath string, sna
 	return db, nil
 }
 
+// newDatabaseWithoutState creates a Database on top of the given stores without loading
+// the ledger index, the snapshot info and the solid entry points.
+func newDatabaseWithoutState(tangleStore kvstore.KVStore, snapshotStore kvstore.KVStore, spentStore kvstore.KVStore, cacheSizes CacheSizes) *Database {
+
+	return &Database{
+		tangleDatabase:                 tangleStore,
+		snapshotDatabase:               snapshotStore,
+		spentDatabase:                  spentStore,
+		indexDatabase:                  nil,
+		txStore:                        lo.PanicOnErr(tangleStore.WithRealm([]byte{StorePrefixTransactions})),
+		metadataStore:                  lo.PanicOnErr(tangleStore.WithRealm([]byte{StorePrefixTransactionMetadata})),
+		addressesStore:                 lo.PanicOnErr(tangleStore.WithRealm([]byte{StorePrefixAddresses})),
+		approversStore:                 lo.PanicOnErr(tangleStore.WithRealm([]byte{StorePrefixApprovers})),
+		bundleStore:                    lo.PanicOnErr(tangleStore.WithRealm([]byte{StorePrefixBundles})),
+		bundleTransactionsStore:        lo.PanicOnErr(tangleStore.WithRealm([]byte{StorePrefixBundleTransactions})),
+		milestoneStore:                 lo.PanicOnErr(tangleStore.WithRealm([]byte{StorePrefixMilestones})),
+		spentAddressesStore:            lo.PanicOnErr(spentStore.WithRealm([]byte{StorePrefixSpentAddresses})),
+		tagsStore:                      lo.PanicOnErr(tangleStore.WithRealm([]byte{StorePrefixTags})),
+		snapshotStore:                  lo.PanicOnErr(snapshotStore.WithRealm([]byte{StorePrefixSnapshot})),
+		ledgerStore:                    lo.PanicOnErr(tangleStore.WithRealm([]byte{StorePrefixLedgerState})),
+		ledgerBalanceStore:             lo.PanicOnErr(tangleStore.WithRealm([]byte{StorePrefixLedgerBalance})),
+		ledgerDiffStore:                lo.PanicOnErr(tangleStore.WithRealm([]byte{StorePrefixLedgerDiff})),
+		ledgerCheckpointsStore:         nil,
+		addressMilestonesStore:         nil,
+		solidEntryPoints:               nil,
+		snapshot:                       nil,
+		syncState:                      nil,
+		syncStateLock:                  sync.Mutex{},
+		ledgerMilestoneIndex:           0,
+		latestSolidMilestoneBundle:     nil,
+		latestSolidMilestoneBundleLock: sync.Mutex{},
+		ledgerCheckpoints:              nil,
+		caches:                         newCaches(cacheSizes),
+		milestoneConeWalks:             sharedCalls[milestone.Index, *[]*TransactionMetadata]{},
+	}
+}
+
 func (db *Database) CloseDatabases() error {
 	var closeError error
 	if err := db.tangleDatabase.Close(); err != nil {
@@ -251,6 +287,11 @@ func (db *Database) CloseDatabases() error {
 	if err := db.spentDatabase.Close(); err != nil {
 		closeError = err
 	}
+	if db.indexDatabase != nil {
+		if err := db.indexDatabase.Close(); err != nil {
+			closeError = err
+		}
+	}
 
 	return closeError
 }
@@ -265,21 +306,36 @@ type SyncState struct {
 	CoordinatorAddress                 trinary.Hash
 }
 
-func (db *Database) LatestSyncState() *SyncState {
-	db.syncStateOnce.Do(func() {
-		ledgerIndex := db.LedgerIndex()
-		latestMilestoneHash := db.LatestSolidMilestoneBundle().MilestoneHash().Trytes()
-
-		db.syncState = &SyncState{
-			LatestMilestone:                    latestMilestoneHash,
-			LatestMilestoneIndex:               ledgerIndex,
-			LatestSolidSubtangleMilestone:      latestMilestoneHash,
-			LatestSolidSubtangleMilestoneIndex: ledgerIndex,
-			MilestoneStartIndex:                db.snapshot.PruningIndex,
-			LastSnapshottedMilestoneIndex:      db.snapshot.PruningIndex,
-			CoordinatorAddress:                 db.snapshot.CoordinatorAddress.Trytes(),
-		}
-	})
+// LatestSyncState returns the sync state of the database.
+func (db *Database) LatestSyncState() (*SyncState, error) {
+	db.syncStateLock.Lock()
+	defer db.syncStateLock.Unlock()
+
+	if db.syncState != nil {
+		return db.syncState, nil
+	}
+
+	// the milestone entry is used instead of the milestone bundle,
+	// because databases that were imported from a snapshot contain no transactions.
+	ledgerIndex := db.LedgerIndex()
+	latestSolidMilestone, err := db.MilestoneOrNil(ledgerIndex)
+	if err != nil {
+		return nil, err
+	}
+	if latestSolidMilestone == nil {
+		return nil, ierrors.Wrapf(ErrCorrupted, "latest solid milestone not found: %d", ledgerIndex)
+	}
+	latestMilestoneHash := latestSolidMilestone.Hash.Trytes()
+
+	db.syncState = &SyncState{
+		LatestMilestone:                    latestMilestoneHash,
+		LatestMilestoneIndex:               ledgerIndex,
+		LatestSolidSubtangleMilestone:      latestMilestoneHash,
+		LatestSolidSubtangleMilestoneIndex: ledgerIndex,
+		MilestoneStartIndex:                db.snapshot.PruningIndex,
+		LastSnapshottedMilestoneIndex:      db.snapshot.PruningIndex,
+		CoordinatorAddress:                 db.snapshot.CoordinatorAddress.Trytes(),
+	}
 
-	return db.syncState
+	return db.syncState, nil
 }
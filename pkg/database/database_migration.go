@@ -18,24 +18,51 @@ import (
 	"github.com/iotaledger/iota.go/trinary"
 )
 
-func supportedDatabaseVersionUpgradeFunc(oldVersion, newVersion byte) error {
-	if !(oldVersion == 2 && newVersion == 3) {
-		return ierrors.Errorf("unsupported database version migration from %d to %d", oldVersion, newVersion)
+const (
+	// transactionMetadataSizeV2 is the size of the transaction metadata in database version 2.
+	transactionMetadataSizeV2 = 1 + 5*milestone.IndexByteSize + 3*hornet.HashSize
+)
+
+var (
+	tangleMigrationSteps = []*MigrationStep{
+		{
+			Name:         "add milestone information to the transaction metadata",
+			FromVersion:  2,
+			ToVersion:    3,
+			Realm:        StorePrefixTransactionMetadata,
+			NewEntryFunc: newTransactionMetadataV3MigrationFunc,
+		},
 	}
 
-	return nil
-}
+	snapshotMigrationSteps = []*MigrationStep{
+		{
+			Name:         "no changes",
+			FromVersion:  2,
+			ToVersion:    3,
+			Realm:        StorePrefixSnapshot,
+			NewEntryFunc: nil,
+		},
+	}
 
-func migrateTangleDatabaseFunc(ctx context.Context, logger *logger.Logger, tangleDatabase kvstore.KVStore, oldVersion, newVersion byte) error {
-	if err := supportedDatabaseVersionUpgradeFunc(oldVersion, newVersion); err != nil {
-		return err
+	spentMigrationSteps = []*MigrationStep{
+		{
+			Name:         "no changes",
+			FromVersion:  2,
+			ToVersion:    3,
+			Realm:        StorePrefixSpentAddresses,
+			NewEntryFunc: nil,
+		},
 	}
+)
+
+// newTransactionMetadataV3MigrationFunc returns the function that migrates the transaction metadata from version 2 to 3.
+//
+// In version 3, we add the information about milestones to the transaction metadata.
+// We also determine all missing information once and store it in the metadata.
+// This is only possible because the legacy database is complete and we will never add new transactions to it.
+func newTransactionMetadataV3MigrationFunc(ctx context.Context, log *logger.Logger, tangleDatabase kvstore.KVStore) (MigrationEntryFunc, error) {
 
-	// in version 3, we add the information about milestones to the transaction metadata.
-	// we also determine all missing information once and store it in the metadata.
-	// this is only possible because the legacy database is complete and we will never add new transactions to it.
 	txStore := lo.PanicOnErr(tangleDatabase.WithRealm([]byte{StorePrefixTransactions}))
-	metadataStore := lo.PanicOnErr(tangleDatabase.WithRealm([]byte{StorePrefixTransactionMetadata}))
 	bundleStore := lo.PanicOnErr(tangleDatabase.WithRealm([]byte{StorePrefixBundles}))
 	bundleTransactionsStore := lo.PanicOnErr(tangleDatabase.WithRealm([]byte{StorePrefixBundleTransactions}))
 
@@ -61,32 +88,92 @@ func migrateTangleDatabaseFunc(ctx context.Context, logger *logger.Logger, tangl
 		return milestone.Index(trinary.TrytesToInt(tailTx.Tx.ObsoleteTag)), nil
 	}
 
-	// we add a cache to speed up the migration
-	milestoneCache := lo.PanicOnErr(lru.New[[49]byte, milestone.Index](10_000_000))
-	defer milestoneCache.Purge()
+	// searchBundleMilestoneIndex returns the milestone index if any of the attachments of the bundle is a milestone.
+	searchBundleMilestoneIndex := func(bundleHash hornet.Hash) (milestone.Index, error) {
+		var milestoneIndex milestone.Index
 
-	lastStatusTime := time.Now()
-	var transactionsCounter int64
+		lastBundleStatusTime := time.Now()
+		var bundleTransactionsCounter int64
 
-	var innerErr error
+		var innerErr error
+		if err := bundleTransactionsStore.IterateKeys(append(databaseKeyPrefixForBundleHash(bundleHash), BundleTxIsTail), func(key []byte) bool {
+			bundleTransactionsCounter++
 
-	// iterate over all transaction metadata
-	if err := metadataStore.Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, data kvstore.Value) bool {
-		transactionsCounter++
+			// print status to show progress
+			if time.Since(lastBundleStatusTime) >= PrintStatusInterval {
+				lastBundleStatusTime = time.Now()
 
-		txHash := hornet.Hash(key[:hornet.HashSize])
+				// check if the context was already canceled
+				if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
+					innerErr = err
+					return false
+				}
+
+				log.Infof("		analyzed %d bundle transactions for bundle %s", bundleTransactionsCounter, bundleHash.Trytes())
+			}
+
+			bundleKey := databaseKeyForBundle(key[50:99]) // tailTxHash in that case, because we only search for "BundleTxIsTail"
+
+			bundleData, err := bundleStore.Get(bundleKey)
+			if err != nil {
+				if !ierrors.Is(err, kvstore.ErrKeyNotFound) {
+					innerErr = ierrors.Wrapf(err, "failed to get bundle %s from database", hornet.Hash(bundleKey).Trytes())
+					return false
+				}
 
-		// print status to show progress
-		if time.Since(lastStatusTime) >= printStatusInterval {
-			lastStatusTime = time.Now()
+				// bundle doesn't exist for this tailTxHash, keep searching
+				return true
+			}
 
-			// check if the context was already canceled
-			if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
-				innerErr = err
+			bundle, err := bundleFactory(nil, bundleKey, bundleData)
+			if err != nil {
+				innerErr = ierrors.Wrapf(err, "failed to get bundle %s from database", hornet.Hash(bundleKey).Trytes())
 				return false
 			}
 
-			logger.Infof("	analyzed %d transactions", transactionsCounter)
+			if bundle.IsMilestone() {
+				msIndex, err := getBundleMilestoneIndex(bundle)
+				if err != nil {
+					innerErr = ierrors.Wrapf(err, "failed to get milestone index for bundle %s", hornet.Hash(bundleKey).Trytes())
+					return false
+				}
+
+				milestoneIndex = msIndex
+
+				// we found the milestone, stop searching
+				return false
+			}
+
+			// another attachment could be a milestone, keep searching
+			return true
+		}); err != nil {
+			return 0, ierrors.Wrapf(err, "failed to iterate over all bundle transactions, bundle: %s", bundleHash.Trytes())
+		}
+
+		if innerErr != nil {
+			return 0, innerErr
+		}
+
+		return milestoneIndex, nil
+	}
+
+	// we add a cache to speed up the migration
+	milestoneCache, err := lru.New[[49]byte, milestone.Index](10_000_000)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(key kvstore.Key, data kvstore.Value) (kvstore.Value, error) {
+		txHash := hornet.Hash(key[:hornet.HashSize])
+
+		switch len(data) {
+		case TransactionMetadataSize:
+			// the entry was already migrated before the migration was interrupted
+			return nil, nil
+		case transactionMetadataSizeV2:
+			// the entry needs to be migrated
+		default:
+			return nil, ierrors.Wrapf(ErrCorrupted, "invalid transaction metadata length: %d, txHash: %s", len(data), txHash.Trytes())
 		}
 
 		txMeta := NewTransactionMetadata(txHash)
@@ -111,80 +198,24 @@ func migrateTangleDatabaseFunc(ctx context.Context, logger *logger.Logger, tangl
 		txData, err := txStore.Get(txHash)
 		if err != nil {
 			if !ierrors.Is(err, kvstore.ErrKeyNotFound) {
-				innerErr = ierrors.Wrapf(err, "failed to get transaction %s from database", txHash.Trytes())
-				return false
+				return nil, ierrors.Wrapf(err, "failed to get transaction %s from database", txHash.Trytes())
 			}
-			innerErr = ierrors.Errorf("transaction %s not found", txHash.Trytes())
 
-			return false
+			return nil, ierrors.Errorf("transaction %s not found", txHash.Trytes())
 		}
 
 		tx, err := transactionFactory(txHash, txData)
 		if err != nil {
-			innerErr = ierrors.Wrapf(err, "failed to parse data for transaction %s", txHash.Trytes())
-			return false
+			return nil, ierrors.Wrapf(err, "failed to parse data for transaction %s", txHash.Trytes())
 		}
 
 		milestoneCacheKey := [49]byte(tx.BundleHash())
 		milestoneIndex, ok := milestoneCache.Get(milestoneCacheKey)
 		if !ok {
-			lastBundleStatusTime := time.Now()
-			var bundleTransactionsCounter int64
-
 			// the entry for the bundle doesn't exist yet in the cache
-			if err := bundleTransactionsStore.IterateKeys(append(databaseKeyPrefixForBundleHash(tx.BundleHash()), BundleTxIsTail), func(key []byte) bool {
-				bundleTransactionsCounter++
-
-				// print status to show progress
-				if time.Since(lastBundleStatusTime) >= printStatusInterval {
-					lastBundleStatusTime = time.Now()
-
-					// check if the context was already canceled
-					if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
-						innerErr = err
-						return false
-					}
-
-					logger.Infof("		analyzed %d bundle transactions for bundle %s", bundleTransactionsCounter, tx.BundleHash().Trytes())
-				}
-
-				bundleKey := databaseKeyForBundle(key[50:99]) // tailTxHash in that case, because we only search for "BundleTxIsTail"
-
-				bundleData, err := bundleStore.Get(bundleKey)
-				if err != nil {
-					if !ierrors.Is(err, kvstore.ErrKeyNotFound) {
-						innerErr = ierrors.Wrapf(err, "failed to get bundle %s from database", hornet.Hash(bundleKey).Trytes())
-						return false
-					}
-
-					// bundle doesn't exist for this tailTxHash, keep searching
-					return true
-				}
-
-				bundle, err := bundleFactory(nil, bundleKey, bundleData)
-				if err != nil {
-					innerErr = ierrors.Wrapf(err, "failed to get bundle %s from database", hornet.Hash(bundleKey).Trytes())
-					return false
-				}
-
-				if bundle.IsMilestone() {
-					msIndex, err := getBundleMilestoneIndex(bundle)
-					if err != nil {
-						innerErr = ierrors.Wrapf(err, "failed to get milestone index for bundle %s", hornet.Hash(bundleKey).Trytes())
-						return false
-					}
-
-					milestoneIndex = msIndex
-
-					// we found the milestone, stop searching
-					return false
-				}
-
-				// another attachment could be a milestone, keep searching
-				return true
-			}); err != nil {
-				innerErr = ierrors.Wrapf(err, "failed to iterate over all bundle transactions, bundle: %s", tx.BundleHash().Trytes())
-				return false
+			milestoneIndex, err = searchBundleMilestoneIndex(tx.BundleHash())
+			if err != nil {
+				return nil, err
 			}
 
 			// set the entry in the cache, so we don't need to search for it again
@@ -202,19 +233,6 @@ func migrateTangleDatabaseFunc(ctx context.Context, logger *logger.Logger, tangl
 		txMeta.bundleHash = tx.BundleHash()
 		txMeta.milestoneIndex = milestoneIndex
 
-		if err := metadataStore.Set(key, txMeta.Marshal()); err != nil {
-			innerErr = ierrors.Wrapf(err, "failed to set updated transaction metadata, txHash: %s", txHash.Trytes())
-			return false
-		}
-
-		return true
-	}); err != nil {
-		return ierrors.Wrap(err, "failed to iterate over all transaction metadata")
-	}
-
-	if innerErr != nil {
-		return innerErr
-	}
-
-	return nil
+		return txMeta.Marshal(), nil
+	}, nil
 }
@@ -0,0 +1,422 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore"
+	hivedb "github.com/iotaledger/hive.go/kvstore/database"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/inx-api-core-v0/pkg/compressed"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database/engine"
+	pebblestore "github.com/iotaledger/inx-api-core-v0/pkg/database/engine/pebble"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+)
+
+var (
+	testCoordinatorAddress = trinary.Trytes(strings.Repeat("C", 81))
+	testAddressA           = trinary.Trytes(strings.Repeat("A", 81))
+	testAddressB           = trinary.Trytes(strings.Repeat("B", 81))
+	testValueBundleHash    = trinary.Trytes(strings.Repeat("V", 81))
+	testValueTag           = trinary.Trytes("VALUE9TRANSFER9999999999999")
+)
+
+// testFixture contains the hashes of the objects written by newTestDatabase.
+//
+// The ledger of the test database:
+//
+//	milestone 1: A = supply,      B = 0
+//	milestone 2: A = supply - 20, B = 20
+//	milestone 3: A = supply - 30, B = 30 (ledger index, value bundle A -> B)
+type testFixture struct {
+	milestoneTails   map[milestone.Index]hornet.Hash
+	valueBundleTail  hornet.Hash
+	valueBundleHead  hornet.Hash
+	snapshotInfo     *SnapshotInfo
+	solidEntryPoints *SolidEntryPoints
+}
+
+func testTransaction(address trinary.Trytes, value int64, obsoleteTag trinary.Trytes, tag trinary.Trytes, bundleHash trinary.Trytes, currentIndex uint64, lastIndex uint64, trunk trinary.Hash, branch trinary.Hash) *transaction.Transaction {
+	return &transaction.Transaction{
+		SignatureMessageFragment:      strings.Repeat("9", 2187),
+		Address:                       address,
+		Value:                         value,
+		ObsoleteTag:                   obsoleteTag,
+		Timestamp:                     1_600_000_000 + currentIndex,
+		CurrentIndex:                  currentIndex,
+		LastIndex:                     lastIndex,
+		Bundle:                        bundleHash,
+		TrunkTransaction:              trunk,
+		BranchTransaction:             branch,
+		Tag:                           tag,
+		AttachmentTimestamp:           0,
+		AttachmentTimestampLowerBound: 0,
+		AttachmentTimestampUpperBound: 0,
+		Nonce:                         strings.Repeat("9", 27),
+	}
+}
+
+// testMilestoneTimestamp returns the timestamp of the milestone with the given index in the test database.
+func testMilestoneTimestamp(msIndex milestone.Index) uint64 {
+	return 1_600_000_000 + uint64(msIndex)*60
+}
+
+// newTestDatabase builds a small legacy database in memory and opens it.
+func newTestDatabase(t *testing.T) (*Database, *testFixture) {
+	t.Helper()
+
+	tangleStore := mapdb.NewMapDB()
+	snapshotStore := mapdb.NewMapDB()
+	spentStore := mapdb.NewMapDB()
+
+	fixture := buildTestDatabase(t, tangleStore, snapshotStore, spentStore)
+
+	db, err := NewWithStores(context.Background(), nil, tangleStore, snapshotStore, spentStore, false, CacheSizes{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	return db, fixture
+}
+
+// buildTestDatabase writes the entries of the test database into the given stores.
+func buildTestDatabase(t *testing.T, tangleStore kvstore.KVStore, snapshotStore kvstore.KVStore, spentStore kvstore.KVStore) *testFixture {
+	t.Helper()
+
+	builder, err := NewBuilder(tangleStore, snapshotStore, spentStore)
+	if err != nil {
+		t.Fatalf("failed to create builder: %v", err)
+	}
+
+	fixture := &testFixture{
+		milestoneTails: make(map[milestone.Index]hornet.Hash),
+	}
+
+	// the first milestone approves the null hash (solid entry point)
+	previousMilestone := trinary.Hash(strings.Repeat("9", 81))
+	for msIndex := milestone.Index(1); msIndex <= 3; msIndex++ {
+		branch := previousMilestone
+
+		if msIndex == 3 {
+			// the value bundle approves milestone 2 and is confirmed by milestone 3
+			valueHead := testTransaction(testAddressB, 10, strings.Repeat("9", 27), testValueTag, testValueBundleHash, 1, 1, previousMilestone, previousMilestone)
+			if _, err := builder.StoreTransaction(valueHead, TransactionState{ConfirmationIndex: 3, Conflicting: false, MilestoneIndex: 0}); err != nil {
+				t.Fatalf("failed to store value transaction: %v", err)
+			}
+
+			valueTail := testTransaction(testAddressA, -10, strings.Repeat("9", 27), testValueTag, testValueBundleHash, 0, 1, valueHead.Hash, previousMilestone)
+			if _, err := builder.StoreTransaction(valueTail, TransactionState{ConfirmationIndex: 3, Conflicting: false, MilestoneIndex: 0}); err != nil {
+				t.Fatalf("failed to store value transaction: %v", err)
+			}
+
+			if fixture.valueBundleTail, err = builder.StoreBundle([]*transaction.Transaction{valueTail, valueHead}, true); err != nil {
+				t.Fatalf("failed to store value bundle: %v", err)
+			}
+			fixture.valueBundleHead = hornet.HashFromHashTrytes(valueHead.Hash)
+
+			// milestone 3 approves the value bundle, so it is part of its cone
+			branch = valueTail.Hash
+		}
+
+		milestoneTx := testTransaction(testCoordinatorAddress, 0, trinary.IntToTrytes(int64(msIndex), 27), strings.Repeat("M", 27), strings.Repeat(string(rune('C'+msIndex)), 81), 0, 0, previousMilestone, branch)
+		milestoneTx.Timestamp = testMilestoneTimestamp(msIndex)
+
+		if _, err := builder.StoreTransaction(milestoneTx, TransactionState{ConfirmationIndex: msIndex, Conflicting: false, MilestoneIndex: msIndex}); err != nil {
+			t.Fatalf("failed to store milestone transaction: %v", err)
+		}
+
+		tailTxHash, err := builder.StoreBundle([]*transaction.Transaction{milestoneTx}, true)
+		if err != nil {
+			t.Fatalf("failed to store milestone bundle: %v", err)
+		}
+
+		if err := builder.StoreMilestone(msIndex, tailTxHash); err != nil {
+			t.Fatalf("failed to store milestone: %v", err)
+		}
+
+		fixture.milestoneTails[msIndex] = tailTxHash
+		previousMilestone = milestoneTx.Hash
+	}
+
+	addressA := hornet.HashFromAddressTrytes(testAddressA)
+	addressB := hornet.HashFromAddressTrytes(testAddressB)
+
+	for _, step := range []func() error{
+		func() error { return builder.StoreLedgerIndex(3) },
+		func() error { return builder.StoreLedgerBalance(addressA, consts.TotalSupply-30) },
+		func() error { return builder.StoreLedgerBalance(addressB, 30) },
+		func() error { return builder.StoreLedgerDiff(2, addressA, -20) },
+		func() error { return builder.StoreLedgerDiff(2, addressB, 20) },
+		func() error { return builder.StoreLedgerDiff(3, addressA, -10) },
+		func() error { return builder.StoreLedgerDiff(3, addressB, 10) },
+		func() error { return builder.StoreSpentAddress(addressA) },
+	} {
+		if err := step(); err != nil {
+			t.Fatalf("failed to store ledger: %v", err)
+		}
+	}
+
+	fixture.snapshotInfo = &SnapshotInfo{
+		CoordinatorAddress: hornet.HashFromAddressTrytes(testCoordinatorAddress),
+		Hash:               fixture.milestoneTails[1],
+		SnapshotIndex:      1,
+		EntryPointIndex:    1,
+		PruningIndex:       0,
+		Timestamp:          1_600_000_000,
+		Metadata:           0,
+	}
+	if err := builder.StoreSnapshotInfo(fixture.snapshotInfo); err != nil {
+		t.Fatalf("failed to store snapshot info: %v", err)
+	}
+
+	fixture.solidEntryPoints = NewSolidEntryPoints()
+	fixture.solidEntryPoints.Add(hornet.HashFromHashTrytes(strings.Repeat("9", 81)), 0)
+	if err := builder.StoreSolidEntryPoints(fixture.solidEntryPoints); err != nil {
+		t.Fatalf("failed to store solid entry points: %v", err)
+	}
+
+	if err := builder.Flush(); err != nil {
+		t.Fatalf("failed to flush builder: %v", err)
+	}
+
+	return fixture
+}
+
+func TestDatabaseRoundTrip(t *testing.T) {
+	db, fixture := newTestDatabase(t)
+
+	if db.LedgerIndex() != 3 {
+		t.Fatalf("expected ledger index 3, got %d", db.LedgerIndex())
+	}
+
+	// transactions and metadata
+	tx, err := db.TransactionOrNil(fixture.valueBundleTail)
+	if err != nil || tx == nil {
+		t.Fatalf("failed to load tail transaction: %v", err)
+	}
+	if tx.Tx.Address != testAddressA || tx.Tx.Value != -10 || tx.Tx.Tag != testValueTag || !tx.IsTail() || tx.IsHead() {
+		t.Fatalf("unexpected tail transaction: %+v", tx.Tx)
+	}
+	if !bytes.Equal(tx.TrunkHash(), fixture.valueBundleHead) {
+		t.Fatalf("unexpected trunk of tail transaction: %s", tx.TrunkHash().Trytes())
+	}
+
+	txMeta, err := db.TxMetadataOrNil(fixture.valueBundleTail)
+	if err != nil || txMeta == nil {
+		t.Fatalf("failed to load tail transaction metadata: %v", err)
+	}
+	if confirmed, at := txMeta.ConfirmedWithIndex(); !confirmed || at != 3 || !txMeta.IsSolid() || !txMeta.IsTail() || !txMeta.IsValue() || txMeta.IsMilestone() {
+		t.Fatalf("unexpected tail transaction metadata")
+	}
+
+	// bundles
+	bndl, err := db.BundleOrNil(fixture.valueBundleTail)
+	if err != nil || bndl == nil {
+		t.Fatalf("failed to load value bundle: %v", err)
+	}
+	if !bndl.IsSolid() || !bndl.IsValid() || !bndl.IsConfirmed() || bndl.IsMilestone() || bndl.IsValueSpam() || bndl.IsConflicting() {
+		t.Fatalf("unexpected value bundle flags")
+	}
+	if bndl.LastIndex() != 1 || !bytes.Equal(bndl.HeadHash(), fixture.valueBundleHead) || bndl.Hash().Trytes() != testValueBundleHash {
+		t.Fatalf("unexpected value bundle")
+	}
+
+	ledgerChanges := bndl.LedgerChanges()
+	if len(ledgerChanges) != 2 ||
+		ledgerChanges[string(hornet.HashFromAddressTrytes(testAddressA))] != -10 ||
+		ledgerChanges[string(hornet.HashFromAddressTrytes(testAddressB))] != 10 {
+		t.Fatalf("unexpected ledger changes: %v", ledgerChanges)
+	}
+
+	txs, err := bndl.TransactionsOrdered()
+	if err != nil || len(txs) != 2 || txs[0].Tx.CurrentIndex != 0 || txs[1].Tx.CurrentIndex != 1 {
+		t.Fatalf("unexpected bundle transactions: %v", err)
+	}
+
+	// milestones
+	for msIndex, tailTxHash := range fixture.milestoneTails {
+		milestoneBundle, err := db.MilestoneBundleOrNil(msIndex)
+		if err != nil || milestoneBundle == nil {
+			t.Fatalf("failed to load milestone bundle %d: %v", msIndex, err)
+		}
+		if !milestoneBundle.IsMilestone() || !bytes.Equal(milestoneBundle.MilestoneHash(), tailTxHash) {
+			t.Fatalf("unexpected milestone bundle %d", msIndex)
+		}
+
+		bundleMsIndex, err := milestoneBundle.MilestoneIndex()
+		if err != nil || bundleMsIndex != msIndex {
+			t.Fatalf("expected milestone index %d, got %d (%v)", msIndex, bundleMsIndex, err)
+		}
+	}
+
+	if ms, err := db.MilestoneOrNil(4); err != nil || ms != nil {
+		t.Fatalf("expected no milestone 4: %v", err)
+	}
+
+	// ledger
+	balance, ledgerIndex, err := db.BalanceForAddress(hornet.HashFromAddressTrytes(testAddressB))
+	if err != nil || balance != 30 || ledgerIndex != 3 {
+		t.Fatalf("unexpected balance of B: %d at %d (%v)", balance, ledgerIndex, err)
+	}
+
+	for address, expected := range map[trinary.Hash]bool{testAddressA: true, testAddressB: false} {
+		if spent, err := db.WasAddressSpentFrom(hornet.HashFromAddressTrytes(address)); err != nil || spent != expected {
+			t.Fatalf("expected spent state %v of %s, got %v (%v)", expected, address, spent, err)
+		}
+	}
+
+	// snapshot
+	if !bytes.Equal(db.snapshot.Bytes(), fixture.snapshotInfo.Bytes()) {
+		t.Fatalf("snapshot info mismatch")
+	}
+	if !bytes.Equal(db.solidEntryPoints.Bytes(), fixture.solidEntryPoints.Bytes()) {
+		t.Fatalf("solid entry points mismatch")
+	}
+}
+
+func TestNewWithEngine(t *testing.T) {
+	// the in-memory databases would always be empty, because they are opened read-only
+	if _, err := New(context.Background(), nil, "", "", "", hivedb.EngineMapDB, false, CacheSizes{}); !ierrors.Is(err, ErrEngineNotSupported) {
+		t.Fatalf("expected ErrEngineNotSupported for the mapdb engine, got %v", err)
+	}
+
+	directory := t.TempDir()
+	tangleDatabasePath := filepath.Join(directory, "tangle")
+	snapshotDatabasePath := filepath.Join(directory, "snapshot")
+	spentDatabasePath := filepath.Join(directory, "spent")
+
+	stores := make([]kvstore.KVStore, 0, 3)
+	for _, path := range []string{tangleDatabasePath, snapshotDatabasePath, spentDatabasePath} {
+		pebbleDB, err := engine.NewPebbleDB(path, false)
+		if err != nil {
+			t.Fatalf("failed to create pebble database: %v", err)
+		}
+		stores = append(stores, pebblestore.New(pebbleDB))
+	}
+
+	buildTestDatabase(t, stores[0], stores[1], stores[2])
+
+	for _, store := range stores {
+		if err := store.Close(); err != nil {
+			t.Fatalf("failed to close store: %v", err)
+		}
+	}
+
+	// the engine of existing databases is detected automatically
+	for _, dbEngine := range []hivedb.Engine{engine.EnginePebble, hivedb.EngineAuto} {
+		db, err := New(context.Background(), nil, tangleDatabasePath, snapshotDatabasePath, spentDatabasePath, dbEngine, false, CacheSizes{})
+		if err != nil {
+			t.Fatalf("failed to open databases with engine %s: %v", dbEngine, err)
+		}
+
+		if db.LedgerIndex() != 3 {
+			t.Fatalf("expected ledger index 3 with engine %s, got %d", dbEngine, db.LedgerIndex())
+		}
+
+		if err := db.CloseDatabases(); err != nil {
+			t.Fatalf("failed to close databases: %v", err)
+		}
+	}
+}
+
+func TestBundleMarshalRoundTrip(t *testing.T) {
+	db, fixture := newTestDatabase(t)
+
+	bndl, err := db.BundleOrNil(fixture.valueBundleTail)
+	if err != nil || bndl == nil {
+		t.Fatalf("failed to load value bundle: %v", err)
+	}
+
+	data := bndl.Marshal()
+	if expectedLength := bundleHeaderSize + 2*hornet.HashSize + 2*bundleLedgerChangeSize; len(data) != expectedLength {
+		t.Fatalf("expected length %d, got %d", expectedLength, len(data))
+	}
+
+	unmarshalled, err := bundleFactory(db, databaseKeyForBundle(fixture.valueBundleTail), data)
+	if err != nil {
+		t.Fatalf("failed to unmarshal bundle: %v", err)
+	}
+
+	if !bytes.Equal(unmarshalled.Marshal(), data) {
+		t.Fatalf("bundle changed after round trip")
+	}
+
+	if err := unmarshalled.Unmarshal(data[:bundleHeaderSize-1]); err == nil {
+		t.Fatalf("expected an error for a truncated bundle")
+	}
+	if err := unmarshalled.Unmarshal(data[:len(data)-1]); err == nil {
+		t.Fatalf("expected an error for a bundle with missing ledger changes")
+	}
+}
+
+func TestSnapshotInfoRoundTrip(t *testing.T) {
+	info := &SnapshotInfo{
+		CoordinatorAddress: hornet.HashFromAddressTrytes(testCoordinatorAddress),
+		Hash:               hornet.HashFromHashTrytes(testValueBundleHash),
+		SnapshotIndex:      10,
+		EntryPointIndex:    9,
+		PruningIndex:       8,
+		Timestamp:          1_600_000_000,
+		Metadata:           1,
+	}
+
+	parsed, err := snapshotInfoFromBytes(info.Bytes())
+	if err != nil {
+		t.Fatalf("failed to parse snapshot info: %v", err)
+	}
+
+	if !bytes.Equal(parsed.CoordinatorAddress, info.CoordinatorAddress) || !bytes.Equal(parsed.Hash, info.Hash) ||
+		parsed.SnapshotIndex != info.SnapshotIndex || parsed.EntryPointIndex != info.EntryPointIndex || parsed.PruningIndex != info.PruningIndex ||
+		parsed.Timestamp != info.Timestamp || parsed.Metadata != info.Metadata {
+		t.Fatalf("snapshot info changed after round trip: %+v", parsed)
+	}
+}
+
+func TestSolidEntryPointsRoundTrip(t *testing.T) {
+	solidEntryPoints := NewSolidEntryPoints()
+	solidEntryPoints.Add(hornet.HashFromHashTrytes(testValueBundleHash), 5)
+	solidEntryPoints.Add(hornet.HashFromHashTrytes(strings.Repeat("9", 81)), 0)
+
+	parsed, err := solidEntryPointsFromBytes(solidEntryPoints.Bytes())
+	if err != nil {
+		t.Fatalf("failed to parse solid entry points: %v", err)
+	}
+
+	if len(parsed.entryPointsMap) != 2 || parsed.entryPointsMap[string(hornet.HashFromHashTrytes(testValueBundleHash))] != 5 {
+		t.Fatalf("solid entry points changed after round trip: %v", parsed.entryPointsMap)
+	}
+}
+
+func TestTransactionCompressionRoundTrip(t *testing.T) {
+	tx := testTransaction(testAddressA, -10, strings.Repeat("9", 27), testValueTag, testValueBundleHash, 0, 1, testValueBundleHash, testValueBundleHash)
+	tx.Hash = testValueBundleHash
+	tx.SignatureMessageFragment = strings.Repeat("SIGNATURE", 3) + strings.Repeat("9", 2187-27)
+
+	data, err := compressed.TransactionToCompressedBytes(tx)
+	if err != nil {
+		t.Fatalf("failed to compress transaction: %v", err)
+	}
+
+	if len(data) >= compressed.TransactionSize {
+		t.Fatalf("expected the signature to be truncated, got %d bytes", len(data))
+	}
+
+	decompressed, err := compressed.TransactionFromCompressedBytes(data, tx.Hash)
+	if err != nil {
+		t.Fatalf("failed to decompress transaction: %v", err)
+	}
+
+	if decompressed.SignatureMessageFragment != tx.SignatureMessageFragment || decompressed.Address != tx.Address ||
+		decompressed.Value != tx.Value || decompressed.Tag != tx.Tag || decompressed.Bundle != tx.Bundle ||
+		decompressed.CurrentIndex != tx.CurrentIndex || decompressed.LastIndex != tx.LastIndex || decompressed.Hash != tx.Hash {
+		t.Fatalf("transaction changed after round trip: %+v", decompressed)
+	}
+}
@@ -4,17 +4,27 @@ import (
 	"github.com/iotaledger/hive.go/ierrors"
 	"github.com/iotaledger/hive.go/kvstore"
 	hivedb "github.com/iotaledger/hive.go/kvstore/database"
-	"github.com/iotaledger/hive.go/kvstore/rocksdb"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database/engine/pebble"
+)
+
+const (
+	// EnginePebble is the pure-Go pebble database engine.
+	EnginePebble hivedb.Engine = "pebble"
 )
 
 var (
 	AllowedEnginesDefault = []hivedb.Engine{
 		hivedb.EngineAuto,
+		hivedb.EngineMapDB,
 		hivedb.EngineRocksDB,
+		EnginePebble,
 	}
 
 	AllowedEnginesStorage = []hivedb.Engine{
+		hivedb.EngineMapDB,
 		hivedb.EngineRocksDB,
+		EnginePebble,
 	}
 
 	AllowedEnginesStorageAuto = append(AllowedEnginesStorage, hivedb.EngineAuto)
@@ -29,6 +39,34 @@ func StoreWithDefaultSettings(directory string, createDatabaseIfNotExists bool,
 		tmpAllowedEngines = allowedEngines
 	}
 
+	// the in-memory database has no representation on disk,
+	// so there is no engine information we could check in the directory.
+	if dbEngine == hivedb.EngineMapDB {
+		if !engineAllowed(dbEngine, tmpAllowedEngines) {
+			return nil, ierrors.Errorf("database engine not allowed: %s", dbEngine)
+		}
+
+		return mapdb.NewMapDB(), nil
+	}
+
+	// the pebble engine is not known to hive.go, so we need to detect it on our own.
+	if dbEngine == EnginePebble || (dbEngine == hivedb.EngineAuto && isPebbleDatabase(directory)) {
+		if !engineAllowed(EnginePebble, tmpAllowedEngines) {
+			return nil, ierrors.Errorf("database engine not allowed: %s", EnginePebble)
+		}
+
+		if !createDatabaseIfNotExists && !isPebbleDatabase(directory) {
+			return nil, ierrors.Errorf("pebble database not found: %s", directory)
+		}
+
+		db, err := NewPebbleDB(directory, readonly)
+		if err != nil {
+			return nil, err
+		}
+
+		return pebble.New(db), nil
+	}
+
 	targetEngine, err := hivedb.CheckEngine(directory, createDatabaseIfNotExists, dbEngine, tmpAllowedEngines)
 	if err != nil {
 		return nil, err
@@ -37,14 +75,19 @@ func StoreWithDefaultSettings(directory string, createDatabaseIfNotExists bool,
 	//nolint:exhaustive
 	switch targetEngine {
 	case hivedb.EngineRocksDB:
-		db, err := NewRocksDB(directory, readonly)
-		if err != nil {
-			return nil, err
-		}
-
-		return rocksdb.New(db), nil
+		return newRocksDBStore(directory, readonly)
 
 	default:
-		return nil, ierrors.Errorf("unknown database engine: %s, supported engines: rocksdb", dbEngine)
+		return nil, ierrors.Errorf("unknown database engine: %s, supported engines: rocksdb/pebble/mapdb", dbEngine)
+	}
+}
+
+func engineAllowed(dbEngine hivedb.Engine, allowedEngines []hivedb.Engine) bool {
+	for _, allowedEngine := range allowedEngines {
+		if dbEngine == allowedEngine {
+			return true
+		}
 	}
+
+	return false
 }
@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"path/filepath"
+	"runtime"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/bloom"
+)
+
+// NewPebbleDB creates a new pebble instance.
+func NewPebbleDB(path string, readonly bool) (*pebble.DB, error) {
+	cache := pebble.NewCache(128 << 20) // 128 MB
+	defer cache.Unref()
+
+	opts := &pebble.Options{
+		Cache:                       cache,
+		ReadOnly:                    readonly,
+		MaxConcurrentCompactions:    func() int { return runtime.NumCPU() },
+		MemTableSize:                64 << 20, // 64 MB
+		MemTableStopWritesThreshold: 4,
+		MaxOpenFiles:                16384,
+	}
+
+	for i := range opts.Levels {
+		l := &opts.Levels[i]
+		l.FilterPolicy = bloom.FilterPolicy(10)
+		l.FilterType = pebble.TableFilter
+	}
+	opts.EnsureDefaults()
+
+	return pebble.Open(path, opts)
+}
+
+// isPebbleDatabase checks if the given directory contains a pebble database.
+// pebble keeps track of its format version in marker files, which don't exist in RocksDB databases.
+func isPebbleDatabase(directory string) bool {
+	matches, err := filepath.Glob(filepath.Join(directory, "marker.format-version.*"))
+	if err != nil {
+		return false
+	}
+
+	return len(matches) > 0
+}
@@ -0,0 +1,281 @@
+package pebble
+
+import (
+	"bytes"
+	"sync/atomic"
+
+	"github.com/cockroachdb/pebble"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore"
+)
+
+var (
+	// ErrStoreClosed is returned if the underlying pebble instance was already closed.
+	ErrStoreClosed = ierrors.New("store is closed")
+)
+
+// pebbleStore implements the KVStore interface around a pebble instance.
+type pebbleStore struct {
+	instance *pebble.DB
+	closed   *atomic.Bool
+	dbPrefix []byte
+}
+
+// New creates a new KVStore with the underlying pebble instance.
+func New(db *pebble.DB) kvstore.KVStore {
+	return &pebbleStore{
+		instance: db,
+		closed:   &atomic.Bool{},
+	}
+}
+
+func (s *pebbleStore) WithRealm(realm kvstore.Realm) (kvstore.KVStore, error) {
+	if s.closed.Load() {
+		return nil, ErrStoreClosed
+	}
+
+	return &pebbleStore{
+		instance: s.instance,
+		closed:   s.closed,
+		dbPrefix: realm,
+	}, nil
+}
+
+func (s *pebbleStore) WithExtendedRealm(realm kvstore.Realm) (kvstore.KVStore, error) {
+	return s.WithRealm(s.buildKeyPrefix(realm))
+}
+
+func (s *pebbleStore) Realm() kvstore.Realm {
+	return copyBytes(s.dbPrefix)
+}
+
+// Iterate iterates over all keys and values with the provided prefix. You can pass kvstore.EmptyPrefix to iterate over all keys and values.
+// Optionally the direction for the iteration can be passed (default: IterDirectionForward).
+func (s *pebbleStore) Iterate(prefix kvstore.KeyPrefix, consumerFunc kvstore.IteratorKeyValueConsumerFunc, direction ...kvstore.IterDirection) error {
+	return s.iterate(prefix, nil, consumerFunc, direction...)
+}
+
+// IterateKeys iterates over all keys with the provided prefix. You can pass kvstore.EmptyPrefix to iterate over all keys.
+// Optionally the direction for the iteration can be passed (default: IterDirectionForward).
+func (s *pebbleStore) IterateKeys(prefix kvstore.KeyPrefix, consumerFunc kvstore.IteratorKeyConsumerFunc, direction ...kvstore.IterDirection) error {
+	return s.iterate(prefix, nil, func(key kvstore.Key, _ kvstore.Value) bool {
+		return consumerFunc(key)
+	}, direction...)
+}
+
+// IterateKeysFrom iterates forward over all keys with the provided prefix, starting at the first key
+// that is bigger than or equal to the start key. The keys before the start key are not read at all.
+func (s *pebbleStore) IterateKeysFrom(prefix kvstore.KeyPrefix, start kvstore.Key, consumerFunc kvstore.IteratorKeyConsumerFunc) error {
+	return s.iterate(prefix, start, func(key kvstore.Key, _ kvstore.Value) bool {
+		return consumerFunc(key)
+	})
+}
+
+func (s *pebbleStore) iterate(prefix kvstore.KeyPrefix, start kvstore.Key, consumerFunc kvstore.IteratorKeyValueConsumerFunc, direction ...kvstore.IterDirection) error {
+	if s.closed.Load() {
+		return ErrStoreClosed
+	}
+
+	keyPrefix := s.buildKeyPrefix(prefix)
+
+	iterOptions := iterOptionsForPrefix(keyPrefix)
+	if start != nil {
+		startKey := s.buildKeyPrefix(start)
+		if iterOptions.UpperBound != nil && bytes.Compare(startKey, iterOptions.UpperBound) >= 0 {
+			// the start key is behind all keys with the prefix
+			return nil
+		}
+
+		if bytes.Compare(startKey, iterOptions.LowerBound) > 0 {
+			iterOptions.LowerBound = startKey
+		}
+	}
+
+	it, err := s.instance.NewIter(iterOptions)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = it.Close() }()
+
+	start, move := it.First, it.Next
+	if len(direction) > 0 && direction[0] == kvstore.IterDirectionBackward {
+		start, move = it.Last, it.Prev
+	}
+
+	for start(); it.Valid(); move() {
+		if !consumerFunc(copyBytes(it.Key()[len(s.dbPrefix):]), copyBytes(it.Value())) {
+			break
+		}
+	}
+
+	return it.Error()
+}
+
+func (s *pebbleStore) Clear() error {
+	return s.DeletePrefix(kvstore.EmptyPrefix)
+}
+
+func (s *pebbleStore) Get(key kvstore.Key) (kvstore.Value, error) {
+	if s.closed.Load() {
+		return nil, ErrStoreClosed
+	}
+
+	value, closer, err := s.instance.Get(s.buildKeyPrefix(key))
+	if err != nil {
+		if ierrors.Is(err, pebble.ErrNotFound) {
+			return nil, kvstore.ErrKeyNotFound
+		}
+
+		return nil, err
+	}
+	defer func() { _ = closer.Close() }()
+
+	return copyBytes(value), nil
+}
+
+func (s *pebbleStore) Set(key kvstore.Key, value kvstore.Value) error {
+	if s.closed.Load() {
+		return ErrStoreClosed
+	}
+
+	return s.instance.Set(s.buildKeyPrefix(key), value, pebble.NoSync)
+}
+
+func (s *pebbleStore) Has(key kvstore.Key) (bool, error) {
+	if _, err := s.Get(key); err != nil {
+		if ierrors.Is(err, kvstore.ErrKeyNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *pebbleStore) Delete(key kvstore.Key) error {
+	if s.closed.Load() {
+		return ErrStoreClosed
+	}
+
+	return s.instance.Delete(s.buildKeyPrefix(key), pebble.NoSync)
+}
+
+func (s *pebbleStore) DeletePrefix(prefix kvstore.KeyPrefix) error {
+	if s.closed.Load() {
+		return ErrStoreClosed
+	}
+
+	keyPrefix := s.buildKeyPrefix(prefix)
+
+	if upperBound := keyUpperBound(keyPrefix); upperBound != nil {
+		return s.instance.DeleteRange(keyPrefix, upperBound, pebble.NoSync)
+	}
+
+	// the prefix has no upper bound (empty or only 0xff bytes), so we need to delete the keys one by one.
+	batch := s.instance.NewBatch()
+	defer func() { _ = batch.Close() }()
+
+	if err := s.iterate(prefix, nil, func(key kvstore.Key, _ kvstore.Value) bool {
+		return batch.Delete(s.buildKeyPrefix(key), nil) == nil
+	}); err != nil {
+		return err
+	}
+
+	return batch.Commit(pebble.NoSync)
+}
+
+func (s *pebbleStore) Flush() error {
+	if s.closed.Load() {
+		return ErrStoreClosed
+	}
+
+	return s.instance.Flush()
+}
+
+func (s *pebbleStore) Close() error {
+	if s.closed.Swap(true) {
+		// the store was already closed
+		return nil
+	}
+
+	return s.instance.Close()
+}
+
+func (s *pebbleStore) Batched() (kvstore.BatchedMutations, error) {
+	if s.closed.Load() {
+		return nil, ErrStoreClosed
+	}
+
+	return &batchedMutations{
+		kvStore: s,
+		batch:   s.instance.NewBatch(),
+	}, nil
+}
+
+func (s *pebbleStore) buildKeyPrefix(prefix kvstore.KeyPrefix) kvstore.KeyPrefix {
+	keyPrefix := make([]byte, 0, len(s.dbPrefix)+len(prefix))
+	keyPrefix = append(keyPrefix, s.dbPrefix...)
+
+	return append(keyPrefix, prefix...)
+}
+
+// batchedMutations is a wrapper around a pebble batch that implements the BatchedMutations interface.
+type batchedMutations struct {
+	kvStore *pebbleStore
+	batch   *pebble.Batch
+}
+
+func (b *batchedMutations) Set(key kvstore.Key, value kvstore.Value) error {
+	return b.batch.Set(b.kvStore.buildKeyPrefix(key), value, nil)
+}
+
+func (b *batchedMutations) Delete(key kvstore.Key) error {
+	return b.batch.Delete(b.kvStore.buildKeyPrefix(key), nil)
+}
+
+func (b *batchedMutations) Cancel() {
+	_ = b.batch.Close()
+}
+
+func (b *batchedMutations) Commit() error {
+	if b.kvStore.closed.Load() {
+		return ErrStoreClosed
+	}
+	defer func() { _ = b.batch.Close() }()
+
+	return b.batch.Commit(pebble.NoSync)
+}
+
+func iterOptionsForPrefix(prefix []byte) *pebble.IterOptions {
+	return &pebble.IterOptions{
+		LowerBound: prefix,
+		UpperBound: keyUpperBound(prefix),
+	}
+}
+
+// keyUpperBound returns the smallest key that is bigger than all keys with the given prefix,
+// or nil if there is no such key.
+func keyUpperBound(prefix []byte) []byte {
+	end := copyBytes(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		end[i]++
+		if end[i] != 0 {
+			return end[:i+1]
+		}
+	}
+
+	return nil
+}
+
+func copyBytes(source []byte) []byte {
+	if source == nil {
+		return nil
+	}
+
+	result := make([]byte, len(source))
+	copy(result, source)
+
+	return result
+}
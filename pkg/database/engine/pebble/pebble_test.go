@@ -0,0 +1,224 @@
+package pebble
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore"
+)
+
+func newTestStore(t *testing.T) kvstore.KVStore {
+	t.Helper()
+
+	db, err := pebble.Open("", &pebble.Options{FS: vfs.NewMem()})
+	if err != nil {
+		t.Fatalf("failed to open pebble database: %v", err)
+	}
+
+	store := New(db)
+	t.Cleanup(func() { _ = store.Close() })
+
+	return store
+}
+
+func collectKeys(t *testing.T, store kvstore.KVStore, prefix kvstore.KeyPrefix, direction kvstore.IterDirection) []string {
+	t.Helper()
+
+	var keys []string
+	if err := store.Iterate(prefix, func(key kvstore.Key, _ kvstore.Value) bool {
+		keys = append(keys, string(key))
+
+		return true
+	}, direction); err != nil {
+		t.Fatalf("failed to iterate: %v", err)
+	}
+
+	return keys
+}
+
+func TestPebbleStoreRoundTrip(t *testing.T) {
+	store := newTestStore(t)
+
+	for _, key := range []string{"a1", "a2", "b1"} {
+		if err := store.Set([]byte(key), []byte("value-"+key)); err != nil {
+			t.Fatalf("failed to set %s: %v", key, err)
+		}
+	}
+
+	value, err := store.Get([]byte("a2"))
+	if err != nil || !bytes.Equal(value, []byte("value-a2")) {
+		t.Fatalf("unexpected value of a2: %q (%v)", value, err)
+	}
+
+	if _, err := store.Get([]byte("c1")); !ierrors.Is(err, kvstore.ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound, got %v", err)
+	}
+
+	if has, err := store.Has([]byte("b1")); err != nil || !has {
+		t.Fatalf("expected b1 to exist (%v)", err)
+	}
+
+	if keys := collectKeys(t, store, []byte("a"), kvstore.IterDirectionForward); len(keys) != 2 || keys[0] != "a1" || keys[1] != "a2" {
+		t.Fatalf("unexpected keys with prefix a: %v", keys)
+	}
+
+	if keys := collectKeys(t, store, kvstore.EmptyPrefix, kvstore.IterDirectionBackward); len(keys) != 3 || keys[0] != "b1" || keys[2] != "a1" {
+		t.Fatalf("unexpected keys in backward direction: %v", keys)
+	}
+
+	// batched mutations are only visible after the commit
+	batch, err := store.Batched()
+	if err != nil {
+		t.Fatalf("failed to create batch: %v", err)
+	}
+	if err := batch.Set([]byte("c1"), []byte("value-c1")); err != nil {
+		t.Fatalf("failed to set c1 in batch: %v", err)
+	}
+	if err := batch.Delete([]byte("a1")); err != nil {
+		t.Fatalf("failed to delete a1 in batch: %v", err)
+	}
+
+	if has, _ := store.Has([]byte("c1")); has {
+		t.Fatalf("expected c1 to be invisible before the commit")
+	}
+
+	if err := batch.Commit(); err != nil {
+		t.Fatalf("failed to commit batch: %v", err)
+	}
+
+	if keys := collectKeys(t, store, kvstore.EmptyPrefix, kvstore.IterDirectionForward); len(keys) != 3 || keys[0] != "a2" || keys[2] != "c1" {
+		t.Fatalf("unexpected keys after the batch: %v", keys)
+	}
+
+	// canceled batches are discarded
+	batch, err = store.Batched()
+	if err != nil {
+		t.Fatalf("failed to create batch: %v", err)
+	}
+	if err := batch.Set([]byte("d1"), []byte("value-d1")); err != nil {
+		t.Fatalf("failed to set d1 in batch: %v", err)
+	}
+	batch.Cancel()
+
+	if has, _ := store.Has([]byte("d1")); has {
+		t.Fatalf("expected d1 to be discarded")
+	}
+
+	if err := store.Delete([]byte("c1")); err != nil {
+		t.Fatalf("failed to delete c1: %v", err)
+	}
+	if has, _ := store.Has([]byte("c1")); has {
+		t.Fatalf("expected c1 to be deleted")
+	}
+
+	if err := store.DeletePrefix([]byte("a")); err != nil {
+		t.Fatalf("failed to delete prefix a: %v", err)
+	}
+	if keys := collectKeys(t, store, kvstore.EmptyPrefix, kvstore.IterDirectionForward); len(keys) != 1 || keys[0] != "b1" {
+		t.Fatalf("unexpected keys after deleting prefix a: %v", keys)
+	}
+}
+
+func TestPebbleStoreRealm(t *testing.T) {
+	store := newTestStore(t)
+
+	realmA, err := store.WithRealm([]byte{1})
+	if err != nil {
+		t.Fatalf("failed to create realm: %v", err)
+	}
+	realmB, err := store.WithRealm([]byte{2})
+	if err != nil {
+		t.Fatalf("failed to create realm: %v", err)
+	}
+
+	if err := realmA.Set([]byte("key"), []byte("a")); err != nil {
+		t.Fatalf("failed to set key in realm a: %v", err)
+	}
+	if err := realmB.Set([]byte("key"), []byte("b")); err != nil {
+		t.Fatalf("failed to set key in realm b: %v", err)
+	}
+
+	// the keys of a realm are returned without the realm prefix
+	if keys := collectKeys(t, realmA, kvstore.EmptyPrefix, kvstore.IterDirectionForward); len(keys) != 1 || keys[0] != "key" {
+		t.Fatalf("unexpected keys in realm a: %v", keys)
+	}
+
+	// clearing a realm doesn't touch the other realms
+	if err := realmA.Clear(); err != nil {
+		t.Fatalf("failed to clear realm a: %v", err)
+	}
+	if has, _ := realmA.Has([]byte("key")); has {
+		t.Fatalf("expected realm a to be empty")
+	}
+
+	value, err := store.Get([]byte{2, 'k', 'e', 'y'})
+	if err != nil || !bytes.Equal(value, []byte("b")) {
+		t.Fatalf("unexpected value in realm b: %q (%v)", value, err)
+	}
+}
+
+func TestKeyUpperBound(t *testing.T) {
+	tests := []struct {
+		prefix   []byte
+		expected []byte
+	}{
+		{prefix: []byte{1, 2}, expected: []byte{1, 3}},
+		{prefix: []byte{1, 0xff}, expected: []byte{2}},
+		{prefix: []byte{0xff, 0xff}, expected: nil},
+		{prefix: []byte{}, expected: nil},
+	}
+
+	for _, test := range tests {
+		if upperBound := keyUpperBound(test.prefix); !bytes.Equal(upperBound, test.expected) {
+			t.Fatalf("unexpected upper bound of %x: %x", test.prefix, upperBound)
+		}
+	}
+}
+
+func TestPebbleStoreIterateKeysFrom(t *testing.T) {
+	realm, err := newTestStore(t).WithRealm([]byte{1})
+	if err != nil {
+		t.Fatalf("failed to create realm: %v", err)
+	}
+
+	for _, key := range []string{"a1", "a2", "a3", "b1"} {
+		if err := realm.Set([]byte(key), []byte("value-"+key)); err != nil {
+			t.Fatalf("failed to set %s: %v", key, err)
+		}
+	}
+
+	tests := []struct {
+		start    string
+		expected []string
+	}{
+		{start: "", expected: []string{"a1", "a2", "a3"}},
+		{start: "0", expected: []string{"a1", "a2", "a3"}},
+		{start: "a2", expected: []string{"a2", "a3"}},
+		{start: "a25", expected: []string{"a3"}},
+		{start: "b", expected: nil},
+	}
+
+	for _, test := range tests {
+		var keys []string
+		if err := realm.(*pebbleStore).IterateKeysFrom([]byte("a"), []byte(test.start), func(key kvstore.Key) bool {
+			keys = append(keys, string(key))
+
+			return true
+		}); err != nil {
+			t.Fatalf("failed to iterate from %q: %v", test.start, err)
+		}
+
+		if len(keys) != len(test.expected) {
+			t.Fatalf("unexpected keys from %q: %v", test.start, keys)
+		}
+		for i := range keys {
+			if keys[i] != test.expected[i] {
+				t.Fatalf("unexpected keys from %q: %v", test.start, keys)
+			}
+		}
+	}
+}
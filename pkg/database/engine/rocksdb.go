@@ -1,8 +1,11 @@
+//go:build rocksdb
+
 package engine
 
 import (
 	"runtime"
 
+	"github.com/iotaledger/hive.go/kvstore"
 	"github.com/iotaledger/hive.go/kvstore/rocksdb"
 )
 
@@ -24,3 +27,12 @@ func NewRocksDB(path string, readonly bool) (*rocksdb.RocksDB, error) {
 
 	return rocksdb.CreateDB(path, opts...)
 }
+
+func newRocksDBStore(path string, readonly bool) (kvstore.KVStore, error) {
+	db, err := NewRocksDB(path, readonly)
+	if err != nil {
+		return nil, err
+	}
+
+	return rocksdb.New(db), nil
+}
@@ -0,0 +1,12 @@
+//go:build !rocksdb
+
+package engine
+
+import (
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore"
+)
+
+func newRocksDBStore(_ string, _ bool) (kvstore.KVStore, error) {
+	return nil, ierrors.New("rocksdb is not supported by this binary, it needs to be built with the \"rocksdb\" build tag")
+}
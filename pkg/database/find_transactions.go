@@ -0,0 +1,461 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"sort"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/runtime/contextutils"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+)
+
+var (
+	// ErrInvalidCursor is returned if the given cursor doesn't belong to the query.
+	ErrInvalidCursor = ierrors.New("invalid cursor")
+	// ErrNoSearchCriteria is returned if no search criteria was given in the query.
+	ErrNoSearchCriteria = ierrors.New("no search criteria was given")
+)
+
+// TransactionsIndex is the kind of index that is used to search for transactions.
+type TransactionsIndex byte
+
+const (
+	TransactionsIndexBundle TransactionsIndex = iota + 1
+	TransactionsIndexApprovee
+	TransactionsIndexAddress
+	TransactionsIndexTag
+)
+
+const (
+	// the size of the query hash in the cursor.
+	transactionsCursorQueryHashSize = 8
+)
+
+// TransactionsIndexes are all indexes that can be used to search for transactions.
+var TransactionsIndexes = []TransactionsIndex{TransactionsIndexBundle, TransactionsIndexApprovee, TransactionsIndexAddress, TransactionsIndexTag}
+
+// TransactionsQuery contains the search criteria to find transactions.
+// The values of a single criteria are combined with OR, the different criteria are combined with AND.
+// This means the found transactions match at least one of the values of every given criteria.
+type TransactionsQuery struct {
+	BundleHashes   hornet.Hashes
+	ApproveeHashes hornet.Hashes
+	Addresses      hornet.Hashes
+	// Tags can also contain binary tag prefixes (see hornet.TagPrefixesFromTrytes).
+	Tags hornet.Hashes
+	// ValueOnly only returns value transactions for the address criteria.
+	ValueOnly bool
+}
+
+// transactionsIndexIterator describes how the transaction hashes are iterated in an index.
+type transactionsIndexIterator struct {
+	store kvstore.KVStore
+	// searchPrefix returns the key prefix for the given search value.
+	searchPrefix func(value hornet.Hash) []byte
+	// txHash extracts the transaction hash from the key.
+	txHash func(key []byte) hornet.Hash
+}
+
+func (db *Database) transactionsIndexIterator(index TransactionsIndex, valueOnly bool) *transactionsIndexIterator {
+	switch index {
+	case TransactionsIndexBundle:
+		return &transactionsIndexIterator{
+			store:        db.bundleTransactionsStore,
+			searchPrefix: databaseKeyPrefixForBundleHash,
+			txHash:       func(key []byte) hornet.Hash { return key[50:99] },
+		}
+
+	case TransactionsIndexApprovee:
+		return &transactionsIndexIterator{
+			store:        db.approversStore,
+			searchPrefix: func(value hornet.Hash) []byte { return value },
+			txHash:       func(key []byte) hornet.Hash { return key[hornet.HashSize : 2*hornet.HashSize] },
+		}
+
+	case TransactionsIndexAddress:
+		return &transactionsIndexIterator{
+			store: db.addressesStore,
+			searchPrefix: func(value hornet.Hash) []byte {
+				if valueOnly {
+					return concatBytes(databaseKeyPrefixForAddress(value), []byte{AddressTxIsValue})
+				}
+
+				return databaseKeyPrefixForAddress(value)
+			},
+			txHash: func(key []byte) hornet.Hash { return key[50:99] },
+		}
+
+	case TransactionsIndexTag:
+		return &transactionsIndexIterator{
+			store:        db.tagsStore,
+			searchPrefix: func(value hornet.Hash) []byte { return value },
+			txHash:       func(key []byte) hornet.Hash { return key[hornet.TagSize : hornet.TagSize+hornet.HashSize] },
+		}
+
+	default:
+		panic(ierrors.Errorf("unknown transactions index: %d", index))
+	}
+}
+
+// ContainsBundleTransaction returns if the given transaction is part of the bundle with the given hash.
+func (db *Database) ContainsBundleTransaction(bundleHash hornet.Hash, txHash hornet.Hash) (bool, error) {
+	for _, isTailByte := range []byte{0, BundleTxIsTail} {
+		contains, err := db.bundleTransactionsStore.Has(concatBytes(databaseKeyPrefixForBundleHash(bundleHash), []byte{isTailByte}, txHash))
+		if err != nil {
+			return false, ierrors.Wrapf(err, "failed to check bundle of transaction %s", txHash.Trytes())
+		}
+
+		if contains {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// containsAny returns if the transaction matches at least one of the given values of the index.
+func (db *Database) containsAny(index TransactionsIndex, values hornet.Hashes, txHash hornet.Hash, valueOnly bool) (bool, error) {
+	for _, value := range values {
+		var contains bool
+
+		var err error
+		switch index {
+		case TransactionsIndexBundle:
+			contains, err = db.ContainsBundleTransaction(value, txHash)
+		case TransactionsIndexApprovee:
+			contains, err = db.ContainsApprover(value, txHash)
+		case TransactionsIndexAddress:
+			contains, err = db.ContainsAddress(value, txHash, valueOnly)
+		case TransactionsIndexTag:
+			contains, err = db.ContainsTagPrefix(value, txHash)
+		}
+		if err != nil {
+			return false, err
+		}
+
+		if contains {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Values returns the search values of the query for the given index.
+func (q *TransactionsQuery) Values(index TransactionsIndex) hornet.Hashes {
+	switch index {
+	case TransactionsIndexBundle:
+		return q.BundleHashes
+	case TransactionsIndexApprovee:
+		return q.ApproveeHashes
+	case TransactionsIndexAddress:
+		return q.Addresses
+	case TransactionsIndexTag:
+		return q.Tags
+	default:
+		return nil
+	}
+}
+
+// cursorQueryHash returns a short hash of the sorted search values and the flags of the query.
+// It is stored in the cursor, so a cursor can't be used to continue the search of another query.
+func (q *TransactionsQuery) cursorQueryHash() []byte {
+	hash := sha256.New()
+
+	var valueOnlyByte byte
+	if q.ValueOnly {
+		valueOnlyByte = 1
+	}
+	hash.Write([]byte{valueOnlyByte})
+
+	for _, index := range TransactionsIndexes {
+		values := sortedHashes(q.Values(index))
+
+		hash.Write([]byte{byte(index), byte(len(values) >> 8), byte(len(values))})
+		for _, value := range values {
+			// the values are prefixed with their length, because tag prefixes have different sizes
+			hash.Write([]byte{byte(len(value))})
+			hash.Write(value)
+		}
+	}
+
+	return hash.Sum(nil)[:transactionsCursorQueryHashSize]
+}
+
+// TransactionsIndexFromCursor returns the index that was used to search for the transactions of the cursor.
+func TransactionsIndexFromCursor(cursor []byte) (TransactionsIndex, error) {
+	if len(cursor) < 1+transactionsCursorQueryHashSize {
+		return 0, ierrors.Wrap(ErrInvalidCursor, "cursor too short")
+	}
+
+	index := TransactionsIndex(cursor[0])
+	for _, knownIndex := range TransactionsIndexes {
+		if index == knownIndex {
+			return index, nil
+		}
+	}
+
+	return 0, ierrors.Wrapf(ErrInvalidCursor, "unknown index: %d", index)
+}
+
+// CountTransactionsInIndex returns the amount of transactions in the index for the given values.
+// The counting stops as soon as the limit is reached.
+func (db *Database) CountTransactionsInIndex(ctx context.Context, index TransactionsIndex, values hornet.Hashes, valueOnly bool, limit int) (int, error) {
+	iterator := db.transactionsIndexIterator(index, valueOnly)
+
+	var count int
+	for _, value := range values {
+		var innerErr error
+		if err := iterator.store.IterateKeys(iterator.searchPrefix(value), func(_ kvstore.Key) bool {
+			if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
+				innerErr = err
+				return false
+			}
+
+			count++
+
+			return count < limit
+		}); err != nil {
+			return 0, err
+		}
+
+		if innerErr != nil {
+			return 0, innerErr
+		}
+
+		if count >= limit {
+			return limit, nil
+		}
+	}
+
+	return count, nil
+}
+
+// FindTransactions returns the hashes of the transactions that match the query.
+// The given search index is iterated to find the candidates, the criteria of all other indexes are used as filters.
+// The results are sorted by the key in the search index.
+// If there are more than maxResults results, a cursor is returned that can be passed
+// to the next call to continue the search after the last returned transaction.
+// Every transaction is only returned once over all pages.
+// At most maxScannedKeys keys of the search index are checked per call (0 means no limit). If the budget is exhausted,
+// a cursor is returned as well, even if less than maxResults transactions were found.
+// Stores that can seek (pebble) continue the scan at the cursor, for all other stores the keys before the cursor
+// are scanned again and charged to the budget, but every page advances by at least one key.
+// The cursor contains a hash of the query, so it is rejected with ErrInvalidCursor if it is passed to another query.
+func (db *Database) FindTransactions(ctx context.Context, query *TransactionsQuery, searchIndex TransactionsIndex, cursor []byte, maxResults int, maxScannedKeys int) (hornet.Hashes, []byte, error) {
+
+	if len(query.Values(searchIndex)) == 0 {
+		return nil, nil, ErrNoSearchCriteria
+	}
+
+	queryHash := query.cursorQueryHash()
+
+	var cursorKey []byte
+	if len(cursor) > 0 {
+		cursorIndex, err := TransactionsIndexFromCursor(cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if cursorIndex != searchIndex || !bytes.Equal(cursor[1:1+transactionsCursorQueryHashSize], queryHash) {
+			return nil, nil, ierrors.Wrap(ErrInvalidCursor, "cursor belongs to another query")
+		}
+		cursorKey = cursor[1+transactionsCursorQueryHashSize:]
+	}
+
+	// the search values are sorted to get a deterministic order of the results
+	searchValues := removeCoveredPrefixes(sortedHashes(query.Values(searchIndex)))
+
+	matchesFilters := func(txHash hornet.Hash) (bool, error) {
+		for _, index := range TransactionsIndexes {
+			if index == searchIndex || len(query.Values(index)) == 0 {
+				continue
+			}
+
+			contains, err := db.containsAny(index, query.Values(index), txHash, query.ValueOnly)
+			if err != nil {
+				return false, err
+			}
+
+			if !contains {
+				return false, nil
+			}
+		}
+
+		return true, nil
+	}
+
+	searchValuesSet := make(map[string]struct{}, len(searchValues))
+	for _, value := range searchValues {
+		searchValuesSet[string(value)] = struct{}{}
+	}
+
+	// returnedBefore returns whether the transaction was already returned for a smaller search value,
+	// either on this or on a previous page. Only the approvee index contains more than one key
+	// for a transaction (trunk and branch), so duplicates can be detected without keeping state between pages.
+	returnedBefore := func(value hornet.Hash, txHash hornet.Hash) (bool, error) {
+		if searchIndex != TransactionsIndexApprovee {
+			return false, nil
+		}
+
+		txMeta, err := db.TxMetadataOrNil(txHash)
+		if err != nil {
+			return false, err
+		}
+		if txMeta == nil {
+			return false, ierrors.Wrapf(ErrCorrupted, "metadata not found for transaction: %s", txHash.Trytes())
+		}
+
+		for _, approvee := range []hornet.Hash{txMeta.TrunkHash(), txMeta.BranchHash()} {
+			if _, isSearchValue := searchValuesSet[string(approvee)]; isSearchValue && bytes.Compare(approvee, value) < 0 {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+
+	iterator := db.transactionsIndexIterator(searchIndex, query.ValueOnly)
+
+	results := make(hornet.Hashes, 0)
+	var lastKey []byte
+	var scannedKeys int
+	var moreResults bool
+
+	for _, value := range searchValues {
+		if cursorKey != nil && len(cursorKey) >= len(value) && bytes.Compare(value, cursorKey[:len(value)]) < 0 {
+			// all transactions of this value were returned before
+			continue
+		}
+
+		var innerErr error
+		if err := iterateKeysAfter(iterator.store, iterator.searchPrefix(value), cursorKey, func(key kvstore.Key) bool {
+			if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
+				innerErr = err
+				return false
+			}
+
+			// at least one key after the cursor is scanned on every page, even if skipping the keys
+			// that were returned before already exhausted the budget, otherwise the search would never advance.
+			if maxScannedKeys > 0 && scannedKeys >= maxScannedKeys && lastKey != nil {
+				// the search is continued after the last scanned key
+				moreResults = true
+				return false
+			}
+			scannedKeys++
+
+			txHash := iterator.txHash(key)
+
+			duplicate, err := returnedBefore(value, txHash)
+			if err != nil {
+				innerErr = err
+				return false
+			}
+
+			if duplicate {
+				lastKey = key
+				return true
+			}
+
+			matches, err := matchesFilters(txHash)
+			if err != nil {
+				innerErr = err
+				return false
+			}
+
+			if !matches {
+				lastKey = key
+				return true
+			}
+
+			if len(results) >= maxResults {
+				moreResults = true
+				return false
+			}
+
+			results = append(results, txHash)
+			lastKey = key
+
+			return true
+		}, func() {
+			// the keys that were returned before are charged to the budget as well, if the store can't skip them
+			scannedKeys++
+		}); err != nil {
+			return nil, nil, err
+		}
+
+		if innerErr != nil {
+			return nil, nil, innerErr
+		}
+
+		if moreResults {
+			break
+		}
+	}
+
+	if !moreResults {
+		return results, nil, nil
+	}
+
+	return results, concatBytes([]byte{byte(searchIndex)}, queryHash, lastKey), nil
+}
+
+// keySeeker is implemented by stores that can start the iteration over a prefix at a given key, like the pebble store.
+type keySeeker interface {
+	IterateKeysFrom(prefix kvstore.KeyPrefix, start kvstore.Key, consumerFunc kvstore.IteratorKeyConsumerFunc) error
+}
+
+// iterateKeysAfter passes all keys with the given prefix that are bigger than afterKey to the consumer.
+// If the store can't seek to afterKey, the iteration starts at the beginning of the prefix
+// and the keys up to afterKey are passed to the skipped function instead.
+func iterateKeysAfter(store kvstore.KVStore, prefix kvstore.KeyPrefix, afterKey kvstore.Key, consumer kvstore.IteratorKeyConsumerFunc, skipped func()) error {
+	if afterKey == nil {
+		return store.IterateKeys(prefix, consumer)
+	}
+
+	if seeker, ok := store.(keySeeker); ok {
+		return seeker.IterateKeysFrom(prefix, afterKey, func(key kvstore.Key) bool {
+			if bytes.Equal(key, afterKey) {
+				// the iteration starts at afterKey itself
+				return true
+			}
+
+			return consumer(key)
+		})
+	}
+
+	return store.IterateKeys(prefix, func(key kvstore.Key) bool {
+		if bytes.Compare(key, afterKey) <= 0 {
+			skipped()
+			return true
+		}
+
+		return consumer(key)
+	})
+}
+
+// sortedHashes returns a sorted copy of the given hashes.
+func sortedHashes(hashes hornet.Hashes) hornet.Hashes {
+	sorted := make(hornet.Hashes, len(hashes))
+	copy(sorted, hashes)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i], sorted[j]) < 0 })
+
+	return sorted
+}
+
+// removeCoveredPrefixes removes all values from the sorted values that start with another value,
+// because their keys are already covered by the prefix scan of the shorter value.
+func removeCoveredPrefixes(sortedValues hornet.Hashes) hornet.Hashes {
+	result := make(hornet.Hashes, 0, len(sortedValues))
+	for _, value := range sortedValues {
+		if len(result) > 0 && bytes.HasPrefix(value, result[len(result)-1]) {
+			continue
+		}
+		result = append(result, value)
+	}
+
+	return result
+}
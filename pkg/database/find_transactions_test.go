@@ -0,0 +1,233 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	pebblestore "github.com/iotaledger/inx-api-core-v0/pkg/database/engine/pebble"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+)
+
+func TestFindTransactionsByApproveesAcrossPages(t *testing.T) {
+	db, fixture := newTestDatabase(t)
+
+	// the value tail approves the value head and the tail of milestone 2,
+	// so it is found for both search values, but must only be returned once.
+	query := &TransactionsQuery{
+		ApproveeHashes: hornet.Hashes{fixture.milestoneTails[2], fixture.valueBundleHead},
+	}
+
+	results := make(map[string]int)
+	var cursor []byte
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatalf("cursor did not terminate")
+		}
+
+		txHashes, nextCursor, err := db.FindTransactions(context.Background(), query, TransactionsIndexApprovee, cursor, 1, 0)
+		if err != nil {
+			t.Fatalf("failed to find transactions: %v", err)
+		}
+
+		for _, txHash := range txHashes {
+			results[string(txHash)]++
+		}
+
+		if nextCursor == nil {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	// milestone 3 approves the tail of milestone 2 as well
+	if len(results) != 3 {
+		t.Fatalf("expected 3 transactions, got %d", len(results))
+	}
+
+	for _, txHash := range []hornet.Hash{fixture.valueBundleTail, fixture.valueBundleHead, fixture.milestoneTails[3]} {
+		if results[string(txHash)] != 1 {
+			t.Fatalf("expected transaction %s to be returned once, got %d", txHash.Trytes(), results[string(txHash)])
+		}
+	}
+}
+
+// newPebbleTestDatabase builds the test database of newTestDatabase with a pebble tangle store,
+// which can continue the scan of an index at a cursor.
+func newPebbleTestDatabase(t *testing.T) (*Database, *testFixture) {
+	t.Helper()
+
+	pebbleDB, err := pebble.Open("", &pebble.Options{FS: vfs.NewMem()})
+	if err != nil {
+		t.Fatalf("failed to open pebble database: %v", err)
+	}
+	tangleStore := pebblestore.New(pebbleDB)
+	t.Cleanup(func() { _ = tangleStore.Close() })
+
+	snapshotStore := mapdb.NewMapDB()
+	spentStore := mapdb.NewMapDB()
+
+	fixture := buildTestDatabase(t, tangleStore, snapshotStore, spentStore)
+
+	db, err := NewWithStores(context.Background(), nil, tangleStore, snapshotStore, spentStore, false, CacheSizes{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	return db, fixture
+}
+
+func TestFindTransactionsScannedKeysBudget(t *testing.T) {
+	for name, newDatabase := range map[string]func(t *testing.T) (*Database, *testFixture){
+		"mapdb":  newTestDatabase,
+		"pebble": newPebbleTestDatabase,
+	} {
+		db, fixture := newDatabase(t)
+
+		// both transactions of the value bundle and milestone 3 approve the tail of milestone 2,
+		// but only the value tail spends from address A.
+		query := &TransactionsQuery{
+			ApproveeHashes: hornet.Hashes{fixture.milestoneTails[2]},
+			Addresses:      hornet.Hashes{hornet.HashFromAddressTrytes(testAddressA)},
+		}
+
+		var results hornet.Hashes
+		var cursor []byte
+		var pages int
+		for ; ; pages++ {
+			if pages > 10 {
+				t.Fatalf("%s: cursor did not terminate", name)
+			}
+
+			txHashes, nextCursor, err := db.FindTransactions(context.Background(), query, TransactionsIndexApprovee, cursor, 10, 1)
+			if err != nil {
+				t.Fatalf("%s: failed to find transactions: %v", name, err)
+			}
+			results = append(results, txHashes...)
+
+			if nextCursor == nil {
+				break
+			}
+			cursor = nextCursor
+		}
+
+		// every page only scans a single new key
+		if pages < 1 {
+			t.Fatalf("%s: expected the search to be split into several pages", name)
+		}
+
+		if len(results) != 1 || !bytes.Equal(results[0], fixture.valueBundleTail) {
+			t.Fatalf("%s: expected only the value tail to be found, got %d results", name, len(results))
+		}
+	}
+}
+
+func TestFindTransactionsCursorOfAnotherQuery(t *testing.T) {
+	db, fixture := newTestDatabase(t)
+
+	query := &TransactionsQuery{
+		ApproveeHashes: hornet.Hashes{fixture.milestoneTails[2], fixture.valueBundleHead},
+	}
+
+	_, cursor, err := db.FindTransactions(context.Background(), query, TransactionsIndexApprovee, nil, 1, 0)
+	if err != nil || cursor == nil {
+		t.Fatalf("expected a cursor for the first page: %v", err)
+	}
+
+	// the order of the search values doesn't change the query
+	reordered := &TransactionsQuery{
+		ApproveeHashes: hornet.Hashes{fixture.valueBundleHead, fixture.milestoneTails[2]},
+	}
+	if _, _, err := db.FindTransactions(context.Background(), reordered, TransactionsIndexApprovee, cursor, 1, 0); err != nil {
+		t.Fatalf("expected the cursor to be accepted for the reordered query: %v", err)
+	}
+
+	for name, otherQuery := range map[string]*TransactionsQuery{
+		"other search values": {ApproveeHashes: hornet.Hashes{fixture.milestoneTails[2]}},
+		"value only":          {ApproveeHashes: query.ApproveeHashes, ValueOnly: true},
+		"additional filter":   {ApproveeHashes: query.ApproveeHashes, Addresses: hornet.Hashes{hornet.HashFromAddressTrytes(testAddressA)}},
+	} {
+		if _, _, err := db.FindTransactions(context.Background(), otherQuery, TransactionsIndexApprovee, cursor, 1, 0); !ierrors.Is(err, ErrInvalidCursor) {
+			t.Fatalf("expected ErrInvalidCursor for %s, got %v", name, err)
+		}
+	}
+
+	if _, _, err := db.FindTransactions(context.Background(), query, TransactionsIndexApprovee, cursor[:transactionsCursorQueryHashSize], 1, 0); !ierrors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor for a truncated cursor, got %v", err)
+	}
+}
+
+// failingHasStore is a store whose Has method always fails.
+type failingHasStore struct {
+	kvstore.KVStore
+}
+
+func (s *failingHasStore) Has(_ kvstore.Key) (bool, error) {
+	return false, ierrors.New("storage error")
+}
+
+func TestFindTransactionsFilterError(t *testing.T) {
+	db, fixture := newTestDatabase(t)
+	db.addressesStore = &failingHasStore{KVStore: db.addressesStore}
+
+	query := &TransactionsQuery{
+		ApproveeHashes: hornet.Hashes{fixture.milestoneTails[2]},
+		Addresses:      hornet.Hashes{hornet.HashFromAddressTrytes(testAddressA)},
+	}
+
+	// the storage error of the address filter is returned instead of panicking
+	if _, _, err := db.FindTransactions(context.Background(), query, TransactionsIndexApprovee, nil, 10, 0); err == nil {
+		t.Fatalf("expected the storage error to be returned")
+	}
+}
+
+func TestIterateKeysAfter(t *testing.T) {
+	pebbleDB, err := pebble.Open("", &pebble.Options{FS: vfs.NewMem()})
+	if err != nil {
+		t.Fatalf("failed to open pebble database: %v", err)
+	}
+	pebbleStore := pebblestore.New(pebbleDB)
+	defer func() { _ = pebbleStore.Close() }()
+
+	tests := []struct {
+		name            string
+		store           kvstore.KVStore
+		expectedSkipped int
+	}{
+		// the pebble store seeks to the key, so the keys before it are not scanned at all
+		{name: "pebble", store: pebbleStore, expectedSkipped: 0},
+		{name: "mapdb", store: mapdb.NewMapDB(), expectedSkipped: 3},
+	}
+
+	for _, test := range tests {
+		for _, key := range []string{"a1", "a2", "a3", "a4", "b1"} {
+			if err := test.store.Set([]byte(key), []byte{}); err != nil {
+				t.Fatalf("failed to set %s: %v", key, err)
+			}
+		}
+
+		var keys []string
+		var skipped int
+		if err := iterateKeysAfter(test.store, []byte("a"), []byte("a3"), func(key kvstore.Key) bool {
+			keys = append(keys, string(key))
+			return true
+		}, func() {
+			skipped++
+		}); err != nil {
+			t.Fatalf("%s: failed to iterate: %v", test.name, err)
+		}
+
+		if len(keys) != 1 || keys[0] != "a4" {
+			t.Fatalf("%s: expected only a4, got %v", test.name, keys)
+		}
+		if skipped != test.expectedSkipped {
+			t.Fatalf("%s: expected %d skipped keys, got %d", test.name, test.expectedSkipped, skipped)
+		}
+	}
+}
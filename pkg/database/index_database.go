@@ -0,0 +1,83 @@
+package database
+
+import (
+	"os"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore"
+	hivedb "github.com/iotaledger/hive.go/kvstore/database"
+	"github.com/iotaledger/hive.go/lo"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database/engine"
+)
+
+const (
+	// IndexDBVersion is the version of the index database.
+	IndexDBVersion = 1
+)
+
+// the index database is an optional sidecar database that contains additional
+// indexes which are built by the tools, because the legacy databases are opened read-only.
+const (
+	IndexStorePrefixHealth            byte = 0
+	IndexStorePrefixLedgerCheckpoints byte = 1
+	IndexStorePrefixAddressMilestones byte = 2
+)
+
+// OpenIndexDatabase opens the index database at the given path.
+// If createDatabaseIfNotExists is false, the database is opened in readonly mode.
+func OpenIndexDatabase(path string, createDatabaseIfNotExists bool, dbEngine hivedb.Engine) (kvstore.KVStore, error) {
+	store, err := engine.StoreWithDefaultSettings(path, createDatabaseIfNotExists, dbEngine, !createDatabaseIfNotExists, engine.AllowedEnginesStorageAuto...)
+	if err != nil {
+		return nil, ierrors.Wrapf(err, "failed to open index database: %s", path)
+	}
+
+	healthTracker, err := kvstore.NewStoreHealthTracker(store, kvstore.KeyPrefix{IndexStorePrefixHealth}, IndexDBVersion, nil)
+	if err != nil {
+		return nil, ierrors.Wrap(err, "failed to check index database version")
+	}
+
+	correctVersion, err := healthTracker.CheckCorrectStoreVersion()
+	if err != nil {
+		return nil, ierrors.Wrap(err, "failed to check index database version")
+	}
+	if !correctVersion {
+		return nil, ierrors.Errorf("index database version mismatch, please rebuild the index database: %s", path)
+	}
+
+	return store, nil
+}
+
+// LoadIndexDatabase opens the optional index database at the given path and loads the contained indexes.
+// It returns false if the index database doesn't exist.
+func (db *Database) LoadIndexDatabase(path string) (bool, error) {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+
+		return false, ierrors.Wrapf(err, "failed to check index database path: %s", path)
+	}
+
+	store, err := OpenIndexDatabase(path, false, hivedb.EngineAuto)
+	if err != nil {
+		return false, err
+	}
+
+	if err := db.loadIndexStore(store); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// loadIndexStore loads the indexes contained in the given index database.
+func (db *Database) loadIndexStore(store kvstore.KVStore) error {
+	db.indexDatabase = store
+	db.ledgerCheckpointsStore = lo.PanicOnErr(store.WithRealm([]byte{IndexStorePrefixLedgerCheckpoints}))
+
+	if err := db.loadLedgerCheckpoints(); err != nil {
+		return err
+	}
+
+	return db.loadAddressMilestonesIndex(lo.PanicOnErr(store.WithRealm([]byte{IndexStorePrefixAddressMilestones})))
+}
@@ -3,10 +3,10 @@ package database
 import (
 	"context"
 	"encoding/binary"
-	"fmt"
 
 	"github.com/iotaledger/hive.go/ierrors"
 	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/runtime/contextutils"
 	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
 	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
 	"github.com/iotaledger/iota.go/consts"
@@ -20,6 +20,10 @@ func databaseKeyForAddress(address hornet.Hash) []byte {
 	return address[:hornet.HashSize]
 }
 
+func databaseKeyForLedgerDiff(milestoneIndex milestone.Index, address hornet.Hash) []byte {
+	return append(databaseKeyForMilestoneIndex(milestoneIndex), databaseKeyForAddress(address)...)
+}
+
 func balanceFromBytes(bytes []byte) uint64 {
 	return binary.LittleEndian.Uint64(bytes)
 }
@@ -46,16 +50,180 @@ func (db *Database) BalanceForAddress(address hornet.Hash) (uint64, milestone.In
 	return balanceFromBytes(value), db.LedgerIndex(), err
 }
 
+// BalanceForAddressAtMilestone returns the balance of the given address at the given milestone index.
+// In contrast to LedgerStateForMilestone, only the ledger diff entries of the address are applied,
+// so the full ledger state doesn't need to be calculated.
+// If the address milestones index is available, only the milestones that changed the balance are visited.
+// Otherwise the walk starts at the nearest ledger checkpoint, so at most half of the checkpoint interval
+// of ledger diff entries need to be looked up.
+func (db *Database) BalanceForAddressAtMilestone(ctx context.Context, address hornet.Hash, targetIndex milestone.Index) (uint64, milestone.Index, error) {
+
+	solidMilestoneIndex := db.SolidMilestoneIndex()
+	if ledgerIndex := db.LedgerIndex(); solidMilestoneIndex != ledgerIndex {
+		return 0, 0, ierrors.Wrapf(ErrLedgerIndexMismatch, "solid milestone index: %d, ledger index: %d", solidMilestoneIndex, ledgerIndex)
+	}
+
+	if targetIndex == 0 {
+		targetIndex = solidMilestoneIndex
+	}
+
+	if targetIndex > solidMilestoneIndex {
+		return 0, 0, ierrors.Wrapf(ErrOutOfRange, "target index is too new. maximum: %d, actual: %d", solidMilestoneIndex, targetIndex)
+	}
+
+	if targetIndex <= db.snapshot.PruningIndex {
+		return 0, 0, ierrors.Wrapf(ErrOutOfRange, "target index is too old. minimum: %d, actual: %d", db.snapshot.PruningIndex+1, targetIndex)
+	}
+
+	if db.HasAddressMilestonesIndex() {
+		return db.balanceForAddressAtMilestoneFromIndex(ctx, address, targetIndex)
+	}
+
+	startIndex := db.ledgerStartIndexForMilestone(targetIndex)
+
+	var balance uint64
+	if startIndex == solidMilestoneIndex {
+		var err error
+
+		balance, _, err = db.BalanceForAddress(address)
+		if err != nil {
+			return 0, 0, err
+		}
+	} else {
+		var err error
+
+		balance, err = db.balanceForAddressAtCheckpoint(address, startIndex)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	applyDiff := func(milestoneIndex milestone.Index, revert bool) error {
+		if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
+			return err
+		}
+
+		change, err := db.ledgerDiffForAddress(address, milestoneIndex)
+		if err != nil {
+			return err
+		}
+
+		if revert {
+			change = -change
+		}
+
+		newBalance := int64(balance) + change
+		if newBalance < 0 {
+			return ierrors.Wrapf(ErrCorrupted, "ledger diff for milestone %d creates negative balance for address %s: current %d, diff %d", milestoneIndex, address.Trytes(), balance, change)
+		}
+		balance = uint64(newBalance)
+
+		return nil
+	}
+
+	// Calculate the balance for targetIndex by walking backwards...
+	for milestoneIndex := startIndex; milestoneIndex > targetIndex; milestoneIndex-- {
+		if err := applyDiff(milestoneIndex, true); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	// ...or forwards, if we started from an older checkpoint
+	for milestoneIndex := startIndex + 1; milestoneIndex <= targetIndex; milestoneIndex++ {
+		if err := applyDiff(milestoneIndex, false); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return balance, targetIndex, nil
+}
+
+// AddressBalanceChange is a change of the balance of an address in a milestone.
+type AddressBalanceChange struct {
+	// MilestoneIndex is the index of the milestone that changed the balance.
+	MilestoneIndex milestone.Index
+	// Change is the balance change in this milestone.
+	Change int64
+	// Balance is the resulting balance after the milestone.
+	Balance uint64
+}
+
+// AddressBalanceChanges returns the balance changes of the given address between startIndex and endIndex (both inclusive),
+// ordered from the newest to the oldest milestone. At most maxResults changes are returned.
+func (db *Database) AddressBalanceChanges(ctx context.Context, address hornet.Hash, startIndex milestone.Index, endIndex milestone.Index, maxResults int) ([]*AddressBalanceChange, error) {
+
+	if startIndex > endIndex {
+		return nil, ierrors.Wrapf(ErrOutOfRange, "start index is bigger than the end index: %d > %d", startIndex, endIndex)
+	}
+
+	if startIndex <= db.snapshot.PruningIndex {
+		return nil, ierrors.Wrapf(ErrOutOfRange, "start index is too old. minimum: %d, actual: %d", db.snapshot.PruningIndex+1, startIndex)
+	}
+
+	if db.HasAddressMilestonesIndex() {
+		return db.addressBalanceChangesFromIndexInRange(ctx, address, startIndex, endIndex, maxResults)
+	}
+
+	balance, _, err := db.BalanceForAddressAtMilestone(ctx, address, endIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]*AddressBalanceChange, 0)
+	for milestoneIndex := endIndex; milestoneIndex >= startIndex && len(changes) < maxResults; milestoneIndex-- {
+		if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
+			return nil, err
+		}
+
+		change, err := db.ledgerDiffForAddress(address, milestoneIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		if change == 0 {
+			continue
+		}
+
+		changes = append(changes, &AddressBalanceChange{
+			MilestoneIndex: milestoneIndex,
+			Change:         change,
+			Balance:        balance,
+		})
+
+		newBalance := int64(balance) - change
+		if newBalance < 0 {
+			return nil, ierrors.Wrapf(ErrCorrupted, "ledger diff for milestone %d creates negative balance for address %s: current %d, diff %d", milestoneIndex, address.Trytes(), balance, -change)
+		}
+		balance = uint64(newBalance)
+	}
+
+	return changes, nil
+}
+
+// ledgerDiffForAddress returns the balance change of the given address in the given milestone.
+func (db *Database) ledgerDiffForAddress(address hornet.Hash, milestoneIndex milestone.Index) (int64, error) {
+	value, err := db.ledgerDiffStore.Get(databaseKeyForLedgerDiff(milestoneIndex, address))
+	if err != nil {
+		if !ierrors.Is(err, kvstore.ErrKeyNotFound) {
+			return 0, ierrors.Wrapf(err, "failed to retrieve ledger diff for milestone %d", milestoneIndex)
+		}
+
+		return 0, nil
+	}
+
+	return diffFromBytes(value), nil
+}
+
 // LedgerDiffForMilestone returns the ledger changes of that specific milestone.
 func (db *Database) LedgerDiffForMilestone(ctx context.Context, targetIndex milestone.Index) (map[string]int64, error) {
 
 	solidMilestoneIndex := db.SolidMilestoneIndex()
 	if targetIndex > solidMilestoneIndex {
-		return nil, ierrors.Errorf("target index is too new. maximum: %d, actual: %d", solidMilestoneIndex, targetIndex)
+		return nil, ierrors.Wrapf(ErrOutOfRange, "target index is too new. maximum: %d, actual: %d", solidMilestoneIndex, targetIndex)
 	}
 
 	if targetIndex <= db.snapshot.PruningIndex {
-		return nil, ierrors.Errorf("target index is too old. minimum: %d, actual: %d", db.snapshot.PruningIndex+1, targetIndex)
+		return nil, ierrors.Wrapf(ErrOutOfRange, "target index is too old. minimum: %d, actual: %d", db.snapshot.PruningIndex+1, targetIndex)
 	}
 
 	diff := make(map[string]int64)
@@ -91,12 +259,15 @@ func (db *Database) LedgerDiffForMilestone(ctx context.Context, targetIndex mile
 	}
 
 	if diffSum != 0 {
-		panic(fmt.Sprintf("LedgerDiffForMilestone(): Ledger diff for milestone %d does not sum up to zero", targetIndex))
+		return nil, ierrors.Wrapf(ErrCorrupted, "ledger diff for milestone %d does not sum up to zero", targetIndex)
 	}
 
 	return diff, nil
 }
 
+// LedgerStateForMilestone returns all balances for the given milestone index.
+// The ledger state is calculated starting from the ledger index or the nearest ledger checkpoint,
+// depending on which one needs less ledger diffs to be applied.
 func (db *Database) LedgerStateForMilestone(ctx context.Context, targetIndex milestone.Index) (map[string]uint64, milestone.Index, error) {
 
 	solidMilestoneIndex := db.SolidMilestoneIndex()
@@ -105,60 +276,127 @@ func (db *Database) LedgerStateForMilestone(ctx context.Context, targetIndex mil
 	}
 
 	if targetIndex > solidMilestoneIndex {
-		return nil, 0, ierrors.Errorf("target index is too new. maximum: %d, actual: %d", solidMilestoneIndex, targetIndex)
+		return nil, 0, ierrors.Wrapf(ErrOutOfRange, "target index is too new. maximum: %d, actual: %d", solidMilestoneIndex, targetIndex)
 	}
 
 	if targetIndex <= db.snapshot.PruningIndex {
-		return nil, 0, ierrors.Errorf("target index is too old. minimum: %d, actual: %d", db.snapshot.PruningIndex+1, targetIndex)
+		return nil, 0, ierrors.Wrapf(ErrOutOfRange, "target index is too old. minimum: %d, actual: %d", db.snapshot.PruningIndex+1, targetIndex)
 	}
 
-	balances, ledgerMilestone, err := db.LedgerStateForLSMI(ctx)
-	if err != nil {
-		if ierrors.Is(err, ErrOperationAborted) {
-			return nil, 0, err
-		}
-
-		return nil, 0, ierrors.Errorf("ledgerStateForLSMI failed! %w", err)
-	}
+	startIndex := db.ledgerStartIndexForMilestone(targetIndex)
 
-	if ledgerMilestone != solidMilestoneIndex {
-		return nil, 0, ierrors.Errorf("ledgerMilestone wrong! %d/%d", ledgerMilestone, solidMilestoneIndex)
-	}
+	var balances map[string]uint64
+	if startIndex == solidMilestoneIndex {
+		var ledgerMilestone milestone.Index
+		var err error
 
-	// Calculate balances for targetIndex
-	for milestoneIndex := solidMilestoneIndex; milestoneIndex > targetIndex; milestoneIndex-- {
-		diff, err := db.LedgerDiffForMilestone(ctx, milestoneIndex)
+		balances, ledgerMilestone, err = db.LedgerStateForLSMI(ctx)
 		if err != nil {
 			if ierrors.Is(err, ErrOperationAborted) {
 				return nil, 0, err
 			}
 
-			return nil, 0, ierrors.Errorf("ledgerDiffForMilestone: %w", err)
+			return nil, 0, ierrors.Errorf("ledgerStateForLSMI failed! %w", err)
 		}
 
-		for address, change := range diff {
-			select {
-			case <-ctx.Done():
-				return nil, 0, ErrOperationAborted
-			default:
+		if ledgerMilestone != solidMilestoneIndex {
+			return nil, 0, ierrors.Errorf("ledgerMilestone wrong! %d/%d", ledgerMilestone, solidMilestoneIndex)
+		}
+	} else {
+		var err error
+
+		balances, err = db.ledgerStateForCheckpoint(ctx, startIndex)
+		if err != nil {
+			if ierrors.Is(err, ErrOperationAborted) {
+				return nil, 0, err
 			}
 
-			newBalance := int64(balances[address]) - change
+			return nil, 0, ierrors.Errorf("ledgerStateForCheckpoint failed! %w", err)
+		}
+	}
 
-			switch {
-			case newBalance < 0:
-				return nil, 0, ierrors.Errorf("ledger diff for milestone %d creates negative balance for address %s: current %d, diff %d", milestoneIndex, hornet.Hash(address).Trytes(), balances[address], change)
-			case newBalance == 0:
-				delete(balances, address)
-			default:
-				balances[address] = uint64(newBalance)
-			}
+	// Calculate balances for targetIndex by walking backwards...
+	for milestoneIndex := startIndex; milestoneIndex > targetIndex; milestoneIndex-- {
+		if err := db.applyLedgerDiffForMilestone(ctx, balances, milestoneIndex, true); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	// ...or forwards, if we started from an older checkpoint
+	for milestoneIndex := startIndex + 1; milestoneIndex <= targetIndex; milestoneIndex++ {
+		if err := db.applyLedgerDiffForMilestone(ctx, balances, milestoneIndex, false); err != nil {
+			return nil, 0, err
 		}
 	}
 
 	return balances, targetIndex, nil
 }
 
+// ledgerStartIndexForMilestone returns the milestone index from which the ledger state of the target index
+// should be calculated. This is either the solid milestone index or the nearest ledger checkpoint,
+// depending on which one needs less ledger diffs to be applied.
+func (db *Database) ledgerStartIndexForMilestone(targetIndex milestone.Index) milestone.Index {
+	solidMilestoneIndex := db.SolidMilestoneIndex()
+
+	checkpointIndex, exists := db.nearestLedgerCheckpoint(targetIndex)
+	if !exists {
+		return solidMilestoneIndex
+	}
+
+	distance := checkpointIndex - targetIndex
+	if checkpointIndex < targetIndex {
+		distance = targetIndex - checkpointIndex
+	}
+
+	if distance < solidMilestoneIndex-targetIndex {
+		return checkpointIndex
+	}
+
+	return solidMilestoneIndex
+}
+
+func (db *Database) applyLedgerDiffForMilestone(ctx context.Context, balances map[string]uint64, milestoneIndex milestone.Index, revert bool) error {
+	diff, err := db.LedgerDiffForMilestone(ctx, milestoneIndex)
+	if err != nil {
+		if ierrors.Is(err, ErrOperationAborted) {
+			return err
+		}
+
+		return ierrors.Errorf("ledgerDiffForMilestone: %w", err)
+	}
+
+	return applyLedgerDiff(ctx, balances, diff, milestoneIndex, revert)
+}
+
+// applyLedgerDiff applies the ledger diff of a milestone to the given balances.
+// If revert is true, the changes are subtracted to get the ledger state before the milestone.
+func applyLedgerDiff(ctx context.Context, balances map[string]uint64, diff map[string]int64, milestoneIndex milestone.Index, revert bool) error {
+	for address, change := range diff {
+		select {
+		case <-ctx.Done():
+			return ErrOperationAborted
+		default:
+		}
+
+		if revert {
+			change = -change
+		}
+
+		newBalance := int64(balances[address]) + change
+
+		switch {
+		case newBalance < 0:
+			return ierrors.Wrapf(ErrCorrupted, "ledger diff for milestone %d creates negative balance for address %s: current %d, diff %d", milestoneIndex, hornet.Hash(address).Trytes(), balances[address], change)
+		case newBalance == 0:
+			delete(balances, address)
+		default:
+			balances[address] = uint64(newBalance)
+		}
+	}
+
+	return nil
+}
+
 // LedgerStateForLSMI returns all balances for the current solid milestone.
 func (db *Database) LedgerStateForLSMI(ctx context.Context) (map[string]uint64, milestone.Index, error) {
 
@@ -192,7 +430,7 @@ func (db *Database) LedgerStateForLSMI(ctx context.Context) (map[string]uint64,
 	}
 
 	if total != consts.TotalSupply {
-		panic(fmt.Sprintf("total does not match supply: %d != %d", total, consts.TotalSupply))
+		return nil, db.LedgerIndex(), ierrors.Wrapf(ErrCorrupted, "total does not match supply: %d != %d", total, consts.TotalSupply)
 	}
 
 	return balances, db.LedgerIndex(), err
@@ -0,0 +1,234 @@
+package database
+
+import (
+	"context"
+	"encoding/binary"
+	"sort"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hive.go/runtime/contextutils"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+)
+
+const (
+	ledgerCheckpointsPrefixInfo     byte = 0
+	ledgerCheckpointsPrefixIndexes  byte = 1
+	ledgerCheckpointsPrefixBalances byte = 2
+
+	ledgerCheckpointsLedgerIndexKey = "ledgerIndex"
+)
+
+func databaseKeyForLedgerCheckpointsLedgerIndex() []byte {
+	return append([]byte{ledgerCheckpointsPrefixInfo}, []byte(ledgerCheckpointsLedgerIndexKey)...)
+}
+
+func databaseKeyForLedgerCheckpointIndex(milestoneIndex milestone.Index) []byte {
+	return append([]byte{ledgerCheckpointsPrefixIndexes}, databaseKeyForMilestoneIndex(milestoneIndex)...)
+}
+
+func databaseKeyPrefixForLedgerCheckpointBalances(milestoneIndex milestone.Index) []byte {
+	return append([]byte{ledgerCheckpointsPrefixBalances}, databaseKeyForMilestoneIndex(milestoneIndex)...)
+}
+
+func (db *Database) loadLedgerCheckpoints() error {
+	value, err := db.ledgerCheckpointsStore.Get(databaseKeyForLedgerCheckpointsLedgerIndex())
+	if err != nil {
+		if !ierrors.Is(err, kvstore.ErrKeyNotFound) {
+			return ierrors.Wrap(err, "failed to load ledger index of the ledger checkpoints")
+		}
+
+		// no checkpoints were built
+		return nil
+	}
+
+	if ledgerIndex := milestoneIndexFromBytes(value); ledgerIndex != db.LedgerIndex() {
+		return ierrors.Errorf("ledger checkpoints were built for another ledger index: %d != %d", ledgerIndex, db.LedgerIndex())
+	}
+
+	var checkpoints []milestone.Index
+	if err := db.ledgerCheckpointsStore.IterateKeys([]byte{ledgerCheckpointsPrefixIndexes}, func(key kvstore.Key) bool {
+		checkpoints = append(checkpoints, milestoneIndexFromDatabaseKey(key[1:]))
+
+		return true
+	}); err != nil {
+		return ierrors.Wrap(err, "failed to load ledger checkpoints")
+	}
+
+	sort.Slice(checkpoints, func(i, j int) bool { return checkpoints[i] < checkpoints[j] })
+	db.ledgerCheckpoints = checkpoints
+
+	return nil
+}
+
+// LedgerCheckpoints returns the milestone indexes of all available ledger checkpoints.
+func (db *Database) LedgerCheckpoints() []milestone.Index {
+	return db.ledgerCheckpoints
+}
+
+// nearestLedgerCheckpoint returns the ledger checkpoint with the smallest distance to the target index.
+func (db *Database) nearestLedgerCheckpoint(targetIndex milestone.Index) (milestone.Index, bool) {
+	if len(db.ledgerCheckpoints) == 0 {
+		return 0, false
+	}
+
+	// index of the first checkpoint that is bigger or equal to the target index
+	pos := sort.Search(len(db.ledgerCheckpoints), func(i int) bool { return db.ledgerCheckpoints[i] >= targetIndex })
+
+	switch {
+	case pos == len(db.ledgerCheckpoints):
+		return db.ledgerCheckpoints[pos-1], true
+	case pos == 0:
+		return db.ledgerCheckpoints[0], true
+	}
+
+	above := db.ledgerCheckpoints[pos]
+	below := db.ledgerCheckpoints[pos-1]
+	if above-targetIndex < targetIndex-below {
+		return above, true
+	}
+
+	return below, true
+}
+
+// ledgerStateForCheckpoint returns all balances of the ledger checkpoint at the given milestone index.
+func (db *Database) ledgerStateForCheckpoint(ctx context.Context, checkpointIndex milestone.Index) (map[string]uint64, error) {
+
+	balances := make(map[string]uint64)
+	keyPrefix := databaseKeyPrefixForLedgerCheckpointBalances(checkpointIndex)
+
+	aborted := false
+	if err := db.ledgerCheckpointsStore.Iterate(keyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		select {
+		case <-ctx.Done():
+			aborted = true
+
+			return false
+		default:
+		}
+
+		balances[string(key[len(keyPrefix):len(keyPrefix)+hornet.HashSize])] = balanceFromBytes(value)
+
+		return true
+	}); err != nil {
+		return nil, err
+	}
+
+	if aborted {
+		return nil, ErrOperationAborted
+	}
+
+	return balances, nil
+}
+
+// balanceForAddressAtCheckpoint returns the balance of the given address in the ledger checkpoint at the given milestone index.
+func (db *Database) balanceForAddressAtCheckpoint(address hornet.Hash, checkpointIndex milestone.Index) (uint64, error) {
+	value, err := db.ledgerCheckpointsStore.Get(concatBytes(databaseKeyPrefixForLedgerCheckpointBalances(checkpointIndex), databaseKeyForAddress(address)))
+	if err != nil {
+		if !ierrors.Is(err, kvstore.ErrKeyNotFound) {
+			return 0, ierrors.Wrapf(err, "failed to retrieve balance of ledger checkpoint %d", checkpointIndex)
+		}
+
+		return 0, nil
+	}
+
+	return balanceFromBytes(value), nil
+}
+
+// BuildLedgerCheckpoints walks the ledger backwards from the ledger index to the pruning index and stores
+// the full ledger state of every milestone that is a multiple of the interval in the given index database.
+// Existing checkpoints are removed before.
+func BuildLedgerCheckpoints(ctx context.Context, log *logger.Logger, db *Database, indexDatabase kvstore.KVStore, interval milestone.Index) error {
+
+	if interval == 0 {
+		return ierrors.New("the interval of the ledger checkpoints must be greater than zero")
+	}
+
+	checkpointsStore, err := indexDatabase.WithRealm([]byte{IndexStorePrefixLedgerCheckpoints})
+	if err != nil {
+		return err
+	}
+
+	if err := checkpointsStore.Clear(); err != nil {
+		return ierrors.Wrap(err, "failed to remove existing ledger checkpoints")
+	}
+
+	balances, ledgerIndex, err := db.LedgerStateForLSMI(ctx)
+	if err != nil {
+		return ierrors.Wrap(err, "failed to load the ledger state")
+	}
+
+	storeCheckpoint := func(checkpointIndex milestone.Index) error {
+		batch, err := checkpointsStore.Batched()
+		if err != nil {
+			return err
+		}
+
+		keyPrefix := databaseKeyPrefixForLedgerCheckpointBalances(checkpointIndex)
+		for address, balance := range balances {
+			value := make([]byte, 8)
+			binary.LittleEndian.PutUint64(value, balance)
+
+			if err := batch.Set(concatBytes(keyPrefix, []byte(address)), value); err != nil {
+				batch.Cancel()
+
+				return err
+			}
+		}
+
+		// the checkpoint is only marked as available after all balances were written
+		if err := batch.Set(databaseKeyForLedgerCheckpointIndex(checkpointIndex), []byte{}); err != nil {
+			batch.Cancel()
+
+			return err
+		}
+
+		return batch.Commit()
+	}
+
+	lastStatusTime := time.Now()
+	var checkpointsCounter int
+
+	for milestoneIndex := ledgerIndex; milestoneIndex > db.snapshot.PruningIndex; milestoneIndex-- {
+		// print status to show progress
+		if time.Since(lastStatusTime) >= PrintStatusInterval {
+			lastStatusTime = time.Now()
+
+			// check if the context was already canceled
+			if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
+				return err
+			}
+
+			log.Infof("	analyzed milestone %d, stored %d checkpoints", milestoneIndex, checkpointsCounter)
+		}
+
+		if milestoneIndex%interval == 0 {
+			if err := storeCheckpoint(milestoneIndex); err != nil {
+				return ierrors.Wrapf(err, "failed to store ledger checkpoint for milestone %d", milestoneIndex)
+			}
+			checkpointsCounter++
+		}
+
+		diff, err := db.LedgerDiffForMilestone(ctx, milestoneIndex)
+		if err != nil {
+			return ierrors.Wrapf(err, "failed to load ledger diff for milestone %d", milestoneIndex)
+		}
+
+		if err := applyLedgerDiff(ctx, balances, diff, milestoneIndex, true); err != nil {
+			return err
+		}
+	}
+
+	value := make([]byte, milestone.IndexByteSize)
+	binary.LittleEndian.PutUint32(value, uint32(ledgerIndex))
+	if err := checkpointsStore.Set(databaseKeyForLedgerCheckpointsLedgerIndex(), value); err != nil {
+		return ierrors.Wrap(err, "failed to store ledger index of the ledger checkpoints")
+	}
+
+	log.Infof("stored %d ledger checkpoints", checkpointsCounter)
+
+	return indexDatabase.Flush()
+}
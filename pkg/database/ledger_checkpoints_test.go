@@ -0,0 +1,166 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+	"github.com/iotaledger/iota.go/consts"
+)
+
+// newLedgerTestDatabase builds a database that only contains the ledger.
+// Every milestone after the first one moves a single token from address A to address B,
+// so B has a balance of msIndex-1 at every milestone.
+func newLedgerTestDatabase(t *testing.T, ledgerIndex milestone.Index) *Database {
+	t.Helper()
+
+	tangleStore := mapdb.NewMapDB()
+	snapshotStore := mapdb.NewMapDB()
+	spentStore := mapdb.NewMapDB()
+
+	builder, err := NewBuilder(tangleStore, snapshotStore, spentStore)
+	if err != nil {
+		t.Fatalf("failed to create builder: %v", err)
+	}
+
+	addressA := hornet.HashFromAddressTrytes(testAddressA)
+	addressB := hornet.HashFromAddressTrytes(testAddressB)
+
+	for _, step := range []func() error{
+		func() error { return builder.StoreLedgerIndex(ledgerIndex) },
+		func() error { return builder.StoreLedgerBalance(addressA, consts.TotalSupply-uint64(ledgerIndex-1)) },
+		func() error { return builder.StoreLedgerBalance(addressB, uint64(ledgerIndex-1)) },
+		func() error {
+			return builder.StoreSnapshotInfo(&SnapshotInfo{
+				CoordinatorAddress: hornet.HashFromAddressTrytes(testCoordinatorAddress),
+				Hash:               hornet.HashFromHashTrytes(strings.Repeat("9", 81)),
+				SnapshotIndex:      1,
+				EntryPointIndex:    1,
+				PruningIndex:       0,
+				Timestamp:          1_600_000_000,
+			})
+		},
+		func() error { return builder.StoreSolidEntryPoints(NewSolidEntryPoints()) },
+	} {
+		if err := step(); err != nil {
+			t.Fatalf("failed to store ledger: %v", err)
+		}
+	}
+
+	for msIndex := milestone.Index(2); msIndex <= ledgerIndex; msIndex++ {
+		if err := builder.StoreLedgerDiff(msIndex, addressA, -1); err != nil {
+			t.Fatalf("failed to store ledger diff: %v", err)
+		}
+		if err := builder.StoreLedgerDiff(msIndex, addressB, 1); err != nil {
+			t.Fatalf("failed to store ledger diff: %v", err)
+		}
+	}
+
+	if err := builder.Flush(); err != nil {
+		t.Fatalf("failed to flush builder: %v", err)
+	}
+
+	db, err := NewWithStores(context.Background(), nil, tangleStore, snapshotStore, spentStore, false, CacheSizes{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	return db
+}
+
+func TestLedgerStateForMilestoneWithCheckpoints(t *testing.T) {
+	db := newLedgerTestDatabase(t, 20)
+
+	// the ledger states are calculated from the ledger index before the checkpoints are loaded
+	targetIndexes := []milestone.Index{1, 5, 8, 10, 15, 16, 18, 20}
+	expectedStates := make(map[milestone.Index]map[string]uint64, len(targetIndexes))
+	for _, targetIndex := range targetIndexes {
+		balances, ledgerIndex, err := db.LedgerStateForMilestone(context.Background(), targetIndex)
+		if err != nil || ledgerIndex != targetIndex {
+			t.Fatalf("failed to get ledger state at milestone %d: %d (%v)", targetIndex, ledgerIndex, err)
+		}
+
+		if balanceB := balances[string(hornet.HashFromAddressTrytes(testAddressB))]; balanceB != uint64(targetIndex-1) {
+			t.Fatalf("expected balance %d of B at milestone %d, got %d", targetIndex-1, targetIndex, balanceB)
+		}
+		expectedStates[targetIndex] = balances
+	}
+
+	indexStore := mapdb.NewMapDB()
+	if err := BuildLedgerCheckpoints(context.Background(), logger.NewExampleLogger("test"), db, indexStore, 8); err != nil {
+		t.Fatalf("failed to build ledger checkpoints: %v", err)
+	}
+
+	if err := db.loadIndexStore(indexStore); err != nil {
+		t.Fatalf("failed to load index store: %v", err)
+	}
+
+	if checkpoints := db.LedgerCheckpoints(); len(checkpoints) != 2 || checkpoints[0] != 8 || checkpoints[1] != 16 {
+		t.Fatalf("unexpected ledger checkpoints: %v", checkpoints)
+	}
+
+	// the checkpoints are used for targets on both sides of them,
+	// unless the ledger index is at least as close as the nearest checkpoint.
+	expectedStartIndexes := map[milestone.Index]milestone.Index{
+		1:  8,
+		5:  8,
+		8:  8,
+		10: 8,
+		15: 16,
+		16: 16,
+		18: 20,
+		20: 20,
+	}
+
+	for _, targetIndex := range targetIndexes {
+		if startIndex := db.ledgerStartIndexForMilestone(targetIndex); startIndex != expectedStartIndexes[targetIndex] {
+			t.Fatalf("expected start index %d for milestone %d, got %d", expectedStartIndexes[targetIndex], targetIndex, startIndex)
+		}
+
+		balances, ledgerIndex, err := db.LedgerStateForMilestone(context.Background(), targetIndex)
+		if err != nil || ledgerIndex != targetIndex {
+			t.Fatalf("failed to get ledger state at milestone %d with checkpoints: %d (%v)", targetIndex, ledgerIndex, err)
+		}
+
+		expected := expectedStates[targetIndex]
+		if len(balances) != len(expected) {
+			t.Fatalf("expected %d balances at milestone %d, got %d", len(expected), targetIndex, len(balances))
+		}
+		for address, balance := range expected {
+			if balances[address] != balance {
+				t.Fatalf("expected balance %d of %s at milestone %d, got %d", balance, hornet.Hash(address).Trytes(), targetIndex, balances[address])
+			}
+		}
+	}
+}
+
+func TestNearestLedgerCheckpoint(t *testing.T) {
+	db := &Database{ledgerCheckpoints: []milestone.Index{10, 20, 40}}
+
+	tests := []struct {
+		targetIndex milestone.Index
+		expected    milestone.Index
+	}{
+		{targetIndex: 1, expected: 10},
+		{targetIndex: 10, expected: 10},
+		{targetIndex: 14, expected: 10},
+		{targetIndex: 15, expected: 10},
+		{targetIndex: 16, expected: 20},
+		{targetIndex: 31, expected: 40},
+		{targetIndex: 100, expected: 40},
+	}
+
+	for _, test := range tests {
+		if checkpointIndex, exists := db.nearestLedgerCheckpoint(test.targetIndex); !exists || checkpointIndex != test.expected {
+			t.Fatalf("expected checkpoint %d for milestone %d, got %d", test.expected, test.targetIndex, checkpointIndex)
+		}
+	}
+
+	if _, exists := (&Database{}).nearestLedgerCheckpoint(10); exists {
+		t.Fatal("expected no checkpoint without ledger checkpoints")
+	}
+}
@@ -0,0 +1,249 @@
+package database
+
+import (
+	"context"
+	"sort"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/runtime/contextutils"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+	"github.com/iotaledger/iota.go/consts"
+)
+
+// LedgerBalanceConsumer is a function that consumes the balance of an address.
+// Returning an error stops the iteration.
+type LedgerBalanceConsumer func(address hornet.Hash, balance uint64) error
+
+// LedgerDiffConsumer is a function that consumes the balance change of an address.
+// Returning an error stops the iteration.
+type LedgerDiffConsumer func(address hornet.Hash, change int64) error
+
+// LedgerStateStream streams the balances of a milestone sorted by the address bytes.
+// It merges the sorted balances of the ledger index or the nearest ledger checkpoint
+// with the accumulated ledger diffs between that milestone and the target index.
+type LedgerStateStream struct {
+	db          *Database
+	targetIndex milestone.Index
+	startIndex  milestone.Index
+
+	// the accumulated changes that need to be applied to the balances of the start index
+	changes         map[string]int64
+	sortedAddresses []string
+}
+
+// NewLedgerStateStream prepares the stream of all balances for the given milestone index.
+// All fallible setup is done here (range checks and walking the ledger diffs), so errors can be reported
+// before any balance was streamed. Only the accumulated ledger diffs between the target index
+// and the ledger index or the nearest ledger checkpoint are kept in memory, so the memory usage
+// grows with the amount of addresses changed in between, not with the size of the ledger state.
+func (db *Database) NewLedgerStateStream(ctx context.Context, targetIndex milestone.Index) (*LedgerStateStream, error) {
+
+	solidMilestoneIndex := db.SolidMilestoneIndex()
+	if targetIndex == 0 {
+		targetIndex = solidMilestoneIndex
+	}
+
+	if targetIndex > solidMilestoneIndex {
+		return nil, ierrors.Wrapf(ErrOutOfRange, "target index is too new. maximum: %d, actual: %d", solidMilestoneIndex, targetIndex)
+	}
+
+	if targetIndex <= db.snapshot.PruningIndex {
+		return nil, ierrors.Wrapf(ErrOutOfRange, "target index is too old. minimum: %d, actual: %d", db.snapshot.PruningIndex+1, targetIndex)
+	}
+
+	startIndex := db.ledgerStartIndexForMilestone(targetIndex)
+
+	changes := make(map[string]int64)
+	accumulateDiff := func(milestoneIndex milestone.Index, revert bool) error {
+		diff, err := db.LedgerDiffForMilestone(ctx, milestoneIndex)
+		if err != nil {
+			if ierrors.Is(err, ErrOperationAborted) {
+				return err
+			}
+
+			return ierrors.Errorf("ledgerDiffForMilestone: %w", err)
+		}
+
+		for address, change := range diff {
+			if revert {
+				change = -change
+			}
+			changes[address] += change
+		}
+
+		return nil
+	}
+
+	// accumulate the changes by walking backwards...
+	for milestoneIndex := startIndex; milestoneIndex > targetIndex; milestoneIndex-- {
+		if err := accumulateDiff(milestoneIndex, true); err != nil {
+			return nil, err
+		}
+	}
+
+	// ...or forwards, if we start from an older checkpoint
+	for milestoneIndex := startIndex + 1; milestoneIndex <= targetIndex; milestoneIndex++ {
+		if err := accumulateDiff(milestoneIndex, false); err != nil {
+			return nil, err
+		}
+	}
+
+	// addresses that are not part of the ledger state of the start index need to be merged
+	// into the sorted iteration over the balances.
+	sortedAddresses := make([]string, 0, len(changes))
+	for address := range changes {
+		sortedAddresses = append(sortedAddresses, address)
+	}
+	sort.Strings(sortedAddresses)
+
+	return &LedgerStateStream{
+		db:              db,
+		targetIndex:     targetIndex,
+		startIndex:      startIndex,
+		changes:         changes,
+		sortedAddresses: sortedAddresses,
+	}, nil
+}
+
+// TargetIndex returns the milestone index of the streamed ledger state.
+func (s *LedgerStateStream) TargetIndex() milestone.Index {
+	return s.targetIndex
+}
+
+// Stream passes all balances to the consumer, sorted by the address bytes.
+// The consumer may already have received balances if the streamed total does not match the supply,
+// in that case ErrCorrupted is returned at the end of the stream.
+func (s *LedgerStateStream) Stream(ctx context.Context, consumer LedgerBalanceConsumer) error {
+
+	store := s.db.ledgerBalanceStore
+	keyPrefix := kvstore.EmptyPrefix
+	if s.startIndex != s.db.SolidMilestoneIndex() {
+		store = s.db.ledgerCheckpointsStore
+		keyPrefix = databaseKeyPrefixForLedgerCheckpointBalances(s.startIndex)
+	}
+
+	var total uint64
+	consumeBalance := func(address string, balance int64) error {
+		if balance < 0 {
+			return ierrors.Wrapf(ErrCorrupted, "negative balance for address %s: %d", hornet.Hash(address).Trytes(), balance)
+		}
+
+		if balance == 0 {
+			return nil
+		}
+
+		total += uint64(balance)
+
+		return consumer(hornet.Hash(address), uint64(balance))
+	}
+
+	var pos int
+	var innerErr error
+	if err := store.Iterate(keyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
+			innerErr = err
+			return false
+		}
+
+		address := string(key[len(keyPrefix) : len(keyPrefix)+hornet.HashSize])
+
+		for ; pos < len(s.sortedAddresses) && s.sortedAddresses[pos] < address; pos++ {
+			if err := consumeBalance(s.sortedAddresses[pos], s.changes[s.sortedAddresses[pos]]); err != nil {
+				innerErr = err
+				return false
+			}
+		}
+
+		balance := int64(balanceFromBytes(value))
+		if pos < len(s.sortedAddresses) && s.sortedAddresses[pos] == address {
+			balance += s.changes[address]
+			pos++
+		}
+
+		if err := consumeBalance(address, balance); err != nil {
+			innerErr = err
+			return false
+		}
+
+		return true
+	}); err != nil {
+		return err
+	}
+
+	if innerErr != nil {
+		return innerErr
+	}
+
+	for ; pos < len(s.sortedAddresses); pos++ {
+		if err := consumeBalance(s.sortedAddresses[pos], s.changes[s.sortedAddresses[pos]]); err != nil {
+			return err
+		}
+	}
+
+	if total != consts.TotalSupply {
+		return ierrors.Wrapf(ErrCorrupted, "total does not match supply: %d != %d", total, consts.TotalSupply)
+	}
+
+	return nil
+}
+
+// StreamLedgerStateForMilestone passes all balances for the given milestone index to the consumer,
+// sorted by the address bytes. In contrast to LedgerStateForMilestone, the ledger state is not loaded into memory.
+func (db *Database) StreamLedgerStateForMilestone(ctx context.Context, targetIndex milestone.Index, consumer LedgerBalanceConsumer) error {
+	stream, err := db.NewLedgerStateStream(ctx, targetIndex)
+	if err != nil {
+		return err
+	}
+
+	return stream.Stream(ctx, consumer)
+}
+
+// StreamLedgerDiffForMilestone passes the ledger changes of that specific milestone to the consumer,
+// sorted by the address bytes. If the changes do not sum up to zero, ErrCorrupted is returned
+// after all changes were passed to the consumer.
+func (db *Database) StreamLedgerDiffForMilestone(ctx context.Context, targetIndex milestone.Index, consumer LedgerDiffConsumer) error {
+
+	solidMilestoneIndex := db.SolidMilestoneIndex()
+	if targetIndex > solidMilestoneIndex {
+		return ierrors.Wrapf(ErrOutOfRange, "target index is too new. maximum: %d, actual: %d", solidMilestoneIndex, targetIndex)
+	}
+
+	if targetIndex <= db.snapshot.PruningIndex {
+		return ierrors.Wrapf(ErrOutOfRange, "target index is too old. minimum: %d, actual: %d", db.snapshot.PruningIndex+1, targetIndex)
+	}
+
+	keyPrefix := databaseKeyForMilestoneIndex(targetIndex)
+
+	var diffSum int64
+	var innerErr error
+	if err := db.ledgerDiffStore.Iterate(keyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
+			innerErr = err
+			return false
+		}
+
+		change := diffFromBytes(value)
+		diffSum += change
+
+		if err := consumer(hornet.Hash(key[len(keyPrefix):len(keyPrefix)+hornet.HashSize]), change); err != nil {
+			innerErr = err
+			return false
+		}
+
+		return true
+	}); err != nil {
+		return err
+	}
+
+	if innerErr != nil {
+		return innerErr
+	}
+
+	if diffSum != 0 {
+		return ierrors.Wrapf(ErrCorrupted, "ledger diff for milestone %d does not sum up to zero", targetIndex)
+	}
+
+	return nil
+}
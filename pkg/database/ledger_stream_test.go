@@ -0,0 +1,184 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+)
+
+func checkStreamedLedgerStates(t *testing.T, db *Database) {
+	t.Helper()
+
+	addressA := hornet.HashFromAddressTrytes(testAddressA)
+	addressB := hornet.HashFromAddressTrytes(testAddressB)
+
+	for msIndex, expectedBalances := range expectedTestBalances {
+		stream, err := db.NewLedgerStateStream(context.Background(), msIndex)
+		if err != nil {
+			t.Fatalf("failed to prepare ledger state stream for milestone %d: %v", msIndex, err)
+		}
+
+		if stream.TargetIndex() != msIndex {
+			t.Fatalf("expected target index %d, got %d", msIndex, stream.TargetIndex())
+		}
+
+		balances := make(map[string]uint64)
+		var lastAddress hornet.Hash
+		if err := stream.Stream(context.Background(), func(address hornet.Hash, balance uint64) error {
+			if lastAddress != nil && bytes.Compare(lastAddress, address) >= 0 {
+				t.Fatalf("addresses are not sorted")
+			}
+			lastAddress = address
+			balances[string(address)] = balance
+
+			return nil
+		}); err != nil {
+			t.Fatalf("failed to stream ledger state for milestone %d: %v", msIndex, err)
+		}
+
+		// addresses without balance are not streamed
+		expected := make(map[string]uint64)
+		for i, address := range []hornet.Hash{addressA, addressB} {
+			if expectedBalances[i] != 0 {
+				expected[string(address)] = expectedBalances[i]
+			}
+		}
+
+		if len(balances) != len(expected) {
+			t.Fatalf("expected %d balances at milestone %d, got %d", len(expected), msIndex, len(balances))
+		}
+		for address, balance := range expected {
+			if balances[address] != balance {
+				t.Fatalf("expected balance %d at milestone %d, got %d", balance, msIndex, balances[address])
+			}
+		}
+	}
+}
+
+func TestStreamLedgerState(t *testing.T) {
+	db, _ := newTestDatabase(t)
+
+	checkStreamedLedgerStates(t, db)
+
+	for _, msIndex := range []milestone.Index{4, 100} {
+		if _, err := db.NewLedgerStateStream(context.Background(), msIndex); !ierrors.Is(err, ErrOutOfRange) {
+			t.Fatalf("expected ErrOutOfRange for milestone %d, got %v", msIndex, err)
+		}
+	}
+}
+
+func TestStreamLedgerStateWithCheckpoints(t *testing.T) {
+	db, _ := newTestDatabase(t)
+
+	indexStore := mapdb.NewMapDB()
+	if err := BuildLedgerCheckpoints(context.Background(), logger.NewExampleLogger("test"), db, indexStore, 2); err != nil {
+		t.Fatalf("failed to build ledger checkpoints: %v", err)
+	}
+
+	if err := db.loadIndexStore(indexStore); err != nil {
+		t.Fatalf("failed to load index store: %v", err)
+	}
+
+	checkStreamedLedgerStates(t, db)
+}
+
+func TestStreamLedgerStateConsumerError(t *testing.T) {
+	db, _ := newTestDatabase(t)
+
+	stream, err := db.NewLedgerStateStream(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("failed to prepare ledger state stream: %v", err)
+	}
+
+	errStop := ierrors.New("stop")
+	if err := stream.Stream(context.Background(), func(_ hornet.Hash, _ uint64) error {
+		return errStop
+	}); !ierrors.Is(err, errStop) {
+		t.Fatalf("expected the error of the consumer, got %v", err)
+	}
+}
+
+// newCorruptedLedgerTestDatabase builds the test database with one additional token for address B
+// in the ledger state and in the ledger diff of milestone 3.
+func newCorruptedLedgerTestDatabase(t *testing.T) *Database {
+	t.Helper()
+
+	tangleStore := mapdb.NewMapDB()
+	snapshotStore := mapdb.NewMapDB()
+	spentStore := mapdb.NewMapDB()
+
+	buildTestDatabase(t, tangleStore, snapshotStore, spentStore)
+
+	builder, err := NewBuilder(tangleStore, snapshotStore, spentStore)
+	if err != nil {
+		t.Fatalf("failed to create builder: %v", err)
+	}
+
+	addressB := hornet.HashFromAddressTrytes(testAddressB)
+	for _, step := range []func() error{
+		func() error { return builder.StoreLedgerBalance(addressB, 31) },
+		func() error { return builder.StoreLedgerDiff(3, addressB, 11) },
+		builder.Flush,
+	} {
+		if err := step(); err != nil {
+			t.Fatalf("failed to corrupt ledger: %v", err)
+		}
+	}
+
+	db, err := NewWithStores(context.Background(), nil, tangleStore, snapshotStore, spentStore, false, CacheSizes{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	return db
+}
+
+func TestStreamLedgerStateTotalSupplyMismatch(t *testing.T) {
+	db := newCorruptedLedgerTestDatabase(t)
+
+	stream, err := db.NewLedgerStateStream(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("failed to prepare ledger state stream: %v", err)
+	}
+
+	var streamed int
+	if err := stream.Stream(context.Background(), func(_ hornet.Hash, _ uint64) error {
+		streamed++
+		return nil
+	}); !ierrors.Is(err, ErrCorrupted) {
+		t.Fatalf("expected ErrCorrupted, got %v", err)
+	}
+
+	// the mismatch is only known after all balances were streamed
+	if streamed != 2 {
+		t.Fatalf("expected 2 streamed balances, got %d", streamed)
+	}
+}
+
+func TestStreamLedgerDiffNonZeroSum(t *testing.T) {
+	db := newCorruptedLedgerTestDatabase(t)
+
+	if err := db.StreamLedgerDiffForMilestone(context.Background(), 2, func(_ hornet.Hash, _ int64) error {
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to stream ledger diff of milestone 2: %v", err)
+	}
+
+	var streamed int
+	if err := db.StreamLedgerDiffForMilestone(context.Background(), 3, func(_ hornet.Hash, _ int64) error {
+		streamed++
+		return nil
+	}); !ierrors.Is(err, ErrCorrupted) {
+		t.Fatalf("expected ErrCorrupted, got %v", err)
+	}
+
+	if streamed != 2 {
+		t.Fatalf("expected 2 streamed changes, got %d", streamed)
+	}
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+	"github.com/iotaledger/iota.go/consts"
+)
+
+// expectedTestBalances are the balances of the test database at every milestone.
+var expectedTestBalances = map[milestone.Index][2]uint64{
+	1: {consts.TotalSupply, 0},
+	2: {consts.TotalSupply - 20, 20},
+	3: {consts.TotalSupply - 30, 30},
+}
+
+func checkBalancesAtMilestones(t *testing.T, db *Database) {
+	t.Helper()
+
+	addresses := []hornet.Hash{hornet.HashFromAddressTrytes(testAddressA), hornet.HashFromAddressTrytes(testAddressB)}
+
+	for msIndex, expectedBalances := range expectedTestBalances {
+		for i, address := range addresses {
+			balance, ledgerIndex, err := db.BalanceForAddressAtMilestone(context.Background(), address, msIndex)
+			if err != nil {
+				t.Fatalf("failed to get balance at milestone %d: %v", msIndex, err)
+			}
+
+			if balance != expectedBalances[i] || ledgerIndex != msIndex {
+				t.Fatalf("expected balance %d at milestone %d, got %d at %d", expectedBalances[i], msIndex, balance, ledgerIndex)
+			}
+		}
+	}
+}
+
+func TestBalanceForAddressAtMilestone(t *testing.T) {
+	db, _ := newTestDatabase(t)
+
+	checkBalancesAtMilestones(t, db)
+
+	// the ledger index is used if no target index is given
+	balance, ledgerIndex, err := db.BalanceForAddressAtMilestone(context.Background(), hornet.HashFromAddressTrytes(testAddressB), 0)
+	if err != nil || balance != 30 || ledgerIndex != 3 {
+		t.Fatalf("unexpected balance at the ledger index: %d at %d (%v)", balance, ledgerIndex, err)
+	}
+
+	for _, msIndex := range []milestone.Index{4, 100} {
+		if _, _, err := db.BalanceForAddressAtMilestone(context.Background(), hornet.HashFromAddressTrytes(testAddressB), msIndex); !ierrors.Is(err, ErrOutOfRange) {
+			t.Fatalf("expected ErrOutOfRange for milestone %d, got %v", msIndex, err)
+		}
+	}
+}
+
+func TestBalanceForAddressAtMilestoneWithCheckpoints(t *testing.T) {
+	db, _ := newTestDatabase(t)
+
+	indexStore := mapdb.NewMapDB()
+	if err := BuildLedgerCheckpoints(context.Background(), logger.NewExampleLogger("test"), db, indexStore, 2); err != nil {
+		t.Fatalf("failed to build ledger checkpoints: %v", err)
+	}
+
+	if err := db.loadIndexStore(indexStore); err != nil {
+		t.Fatalf("failed to load index store: %v", err)
+	}
+
+	if checkpoints := db.LedgerCheckpoints(); len(checkpoints) != 1 || checkpoints[0] != 2 {
+		t.Fatalf("unexpected ledger checkpoints: %v", checkpoints)
+	}
+
+	checkBalancesAtMilestones(t, db)
+}
+
+func TestBalanceForAddressAtMilestoneWithAddressMilestonesIndex(t *testing.T) {
+	db, _ := newTestDatabase(t)
+
+	indexStore := mapdb.NewMapDB()
+	if err := BuildAddressMilestonesIndex(context.Background(), logger.NewExampleLogger("test"), db, indexStore); err != nil {
+		t.Fatalf("failed to build address milestones index: %v", err)
+	}
+
+	if err := db.loadIndexStore(indexStore); err != nil {
+		t.Fatalf("failed to load index store: %v", err)
+	}
+
+	if !db.HasAddressMilestonesIndex() {
+		t.Fatal("expected the address milestones index to be available")
+	}
+
+	checkBalancesAtMilestones(t, db)
+}
@@ -0,0 +1,366 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hive.go/runtime/contextutils"
+)
+
+const (
+	// DatabaseNameTangle is the name of the tangle database.
+	DatabaseNameTangle = "tangle"
+	// DatabaseNameSnapshot is the name of the snapshot database.
+	DatabaseNameSnapshot = "snapshot"
+	// DatabaseNameSpent is the name of the spent database.
+	DatabaseNameSpent = "spent"
+)
+
+const (
+	// the amount of analyzed entries after which the migrated entries are written together with a progress checkpoint.
+	migrationBatchSize = 10_000
+)
+
+var (
+	// ErrMigrationRequired is returned if the database version is outdated and the database needs to be migrated.
+	ErrMigrationRequired = ierrors.New("database migration required")
+
+	// errMigrationDryRun is used to abort the version update of the store after a dry-run.
+	errMigrationDryRun = ierrors.New("migration dry-run")
+)
+
+// MigrationEntryFunc migrates a single entry of a realm.
+// It returns nil if the entry doesn't need to be changed.
+// Interrupted migrations are resumed from the last checkpoint, so the function needs to
+// skip entries that were already migrated.
+type MigrationEntryFunc func(key kvstore.Key, value kvstore.Value) (kvstore.Value, error)
+
+// MigrationStep migrates the entries of a realm of a database from one version to the next one.
+type MigrationStep struct {
+	// Name describes the migration step.
+	Name        string
+	FromVersion byte
+	ToVersion   byte
+	// Realm is the store prefix of the migrated entries.
+	Realm byte
+	// NewEntryFunc creates the function that migrates the entries.
+	// The given store is the whole database, so other realms can be accessed as well.
+	// If it is nil, only the database version is updated.
+	NewEntryFunc func(ctx context.Context, log *logger.Logger, store kvstore.KVStore) (MigrationEntryFunc, error)
+}
+
+// MigrationStepReport contains the result of a migration step.
+type MigrationStepReport struct {
+	Name        string `json:"name"`
+	FromVersion byte   `json:"fromVersion"`
+	ToVersion   byte   `json:"toVersion"`
+	// Resumed is true if the step was continued from a progress checkpoint.
+	Resumed bool `json:"resumed"`
+	// Analyzed is the amount of entries that were analyzed, including the ones before the checkpoint.
+	Analyzed uint64 `json:"analyzed"`
+	// Changed is the amount of entries that were changed, or would be changed in a dry-run.
+	Changed uint64 `json:"changed"`
+}
+
+// MigrationReport contains the result of the migration of a database.
+type MigrationReport struct {
+	Database       string                 `json:"database"`
+	CurrentVersion byte                   `json:"currentVersion"`
+	TargetVersion  byte                   `json:"targetVersion"`
+	DryRun         bool                   `json:"dryRun"`
+	Steps          []*MigrationStepReport `json:"steps"`
+}
+
+// migrationCheckpoint is the persisted progress of a migration step.
+type migrationCheckpoint struct {
+	lastKey  kvstore.Key
+	analyzed uint64
+	changed  uint64
+}
+
+func (c *migrationCheckpoint) Bytes() []byte {
+	value := make([]byte, 0, 8+8+len(c.lastKey))
+	value = binary.LittleEndian.AppendUint64(value, c.analyzed)
+	value = binary.LittleEndian.AppendUint64(value, c.changed)
+	value = append(value, c.lastKey...)
+
+	return value
+}
+
+func migrationCheckpointFromBytes(value []byte) (*migrationCheckpoint, error) {
+	if len(value) < 16 {
+		return nil, ierrors.Wrapf(ErrCorrupted, "invalid migration checkpoint length: %d", len(value))
+	}
+
+	return &migrationCheckpoint{
+		analyzed: binary.LittleEndian.Uint64(value[:8]),
+		changed:  binary.LittleEndian.Uint64(value[8:16]),
+		// the key is copied, because it is overwritten while the migration continues.
+		lastKey: append(kvstore.Key{}, value[16:]...),
+	}, nil
+}
+
+// databaseKeyForMigrationCheckpoint returns the key of the progress checkpoint of the migration step.
+// The checkpoint is stored in the migrated database itself, so it can be written atomically with the migrated entries.
+func databaseKeyForMigrationCheckpoint(step *MigrationStep) []byte {
+	return []byte{StorePrefixMigration, step.FromVersion, step.ToVersion}
+}
+
+// migrationSteps returns the registered migration steps of the database with the given name.
+func migrationSteps(databaseName string) ([]*MigrationStep, error) {
+	switch databaseName {
+	case DatabaseNameTangle:
+		return tangleMigrationSteps, nil
+	case DatabaseNameSnapshot:
+		return snapshotMigrationSteps, nil
+	case DatabaseNameSpent:
+		return spentMigrationSteps, nil
+	default:
+		return nil, ierrors.Errorf("unknown database: %s", databaseName)
+	}
+}
+
+// Migrate migrates the store of the database with the given name to the current database version.
+// The progress of every step is checkpointed, so an interrupted migration is resumed on the next call.
+// The database version is only updated after all steps were successful.
+// In dry-run mode, nothing is written and the report contains the entries that would be changed.
+func Migrate(ctx context.Context, log *logger.Logger, databaseName string, store kvstore.KVStore, dryRun bool) (*MigrationReport, error) {
+
+	steps, err := migrationSteps(databaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &MigrationReport{
+		Database:       databaseName,
+		CurrentVersion: DBVersion,
+		TargetVersion:  DBVersion,
+		DryRun:         dryRun,
+		Steps:          make([]*MigrationStepReport, 0),
+	}
+
+	healthTracker, err := kvstore.NewStoreHealthTracker(store, kvstore.KeyPrefix{StorePrefixHealth}, DBVersion, func(oldVersion byte, newVersion byte) error {
+		report.CurrentVersion = oldVersion
+
+		for version := oldVersion; version < newVersion; {
+			step := stepForVersion(steps, version)
+			if step == nil {
+				return ierrors.Errorf("unsupported database version migration from %d to %d", version, newVersion)
+			}
+
+			log.Infof("Migrating %s database from version %d to %d (%s)...", databaseName, step.FromVersion, step.ToVersion, step.Name)
+
+			stepReport, err := runMigrationStep(ctx, log, store, step, dryRun)
+			if err != nil {
+				return ierrors.Wrapf(err, "migration step from version %d to %d failed", step.FromVersion, step.ToVersion)
+			}
+			report.Steps = append(report.Steps, stepReport)
+
+			version = step.ToVersion
+		}
+
+		if dryRun {
+			return errMigrationDryRun
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := healthTracker.UpdateStoreVersion(); err != nil {
+		if dryRun && ierrors.Is(err, errMigrationDryRun) {
+			return report, nil
+		}
+
+		return nil, err
+	}
+
+	if !dryRun {
+		for _, step := range steps {
+			if err := store.Delete(databaseKeyForMigrationCheckpoint(step)); err != nil {
+				return nil, ierrors.Wrap(err, "failed to delete migration checkpoint")
+			}
+		}
+
+		if err := store.Flush(); err != nil {
+			return nil, ierrors.Wrap(err, "failed to flush database")
+		}
+	}
+
+	return report, nil
+}
+
+func stepForVersion(steps []*MigrationStep, version byte) *MigrationStep {
+	for _, step := range steps {
+		if step.FromVersion == version {
+			return step
+		}
+	}
+
+	return nil
+}
+
+// runMigrationStep migrates all entries of the realm of the step, starting after the last checkpoint.
+// The migrated entries are written in batches together with the checkpoint.
+// A checkpoint is written every migrationBatchSize analyzed entries, even if none of them were changed.
+func runMigrationStep(ctx context.Context, log *logger.Logger, store kvstore.KVStore, step *MigrationStep, dryRun bool) (*MigrationStepReport, error) {
+
+	report := &MigrationStepReport{
+		Name:        step.Name,
+		FromVersion: step.FromVersion,
+		ToVersion:   step.ToVersion,
+	}
+
+	if step.NewEntryFunc == nil {
+		return report, nil
+	}
+
+	checkpoint := &migrationCheckpoint{}
+	checkpointKey := databaseKeyForMigrationCheckpoint(step)
+
+	value, err := store.Get(checkpointKey)
+	if err != nil && !ierrors.Is(err, kvstore.ErrKeyNotFound) {
+		return nil, ierrors.Wrap(err, "failed to load migration checkpoint")
+	}
+	if err == nil {
+		if checkpoint, err = migrationCheckpointFromBytes(value); err != nil {
+			return nil, err
+		}
+		report.Resumed = true
+
+		log.Infof("	resuming from checkpoint, analyzed %d entries, changed %d entries", checkpoint.analyzed, checkpoint.changed)
+	}
+
+	migrateEntry, err := step.NewEntryFunc(ctx, log, store)
+	if err != nil {
+		return nil, err
+	}
+
+	realmStore, err := store.WithRealm([]byte{step.Realm})
+	if err != nil {
+		return nil, err
+	}
+
+	var batch kvstore.BatchedMutations
+	// the amount of entries that were analyzed since the last checkpoint
+	var batchEntries int
+
+	// commitBatch writes the migrated entries and the checkpoint atomically.
+	commitBatch := func() error {
+		if dryRun || batchEntries == 0 {
+			return nil
+		}
+
+		if batch == nil {
+			var err error
+			if batch, err = store.Batched(); err != nil {
+				return err
+			}
+		}
+
+		if err := batch.Set(checkpointKey, checkpoint.Bytes()); err != nil {
+			return err
+		}
+
+		if err := batch.Commit(); err != nil {
+			return ierrors.Wrap(err, "failed to commit batch")
+		}
+		batch = nil
+		batchEntries = 0
+
+		return nil
+	}
+
+	lastStatusTime := time.Now()
+
+	var innerErr error
+	if err := realmStore.Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		if len(checkpoint.lastKey) > 0 && bytes.Compare(key, checkpoint.lastKey) <= 0 {
+			// skip all entries that were migrated before the interruption
+			return true
+		}
+
+		// check if the context was already canceled
+		if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
+			innerErr = err
+			return false
+		}
+
+		// print status to show progress
+		if time.Since(lastStatusTime) >= PrintStatusInterval {
+			lastStatusTime = time.Now()
+
+			log.Infof("	analyzed %d entries, changed %d entries", checkpoint.analyzed, checkpoint.changed)
+		}
+
+		newValue, err := migrateEntry(key, value)
+		if err != nil {
+			innerErr = err
+			return false
+		}
+
+		checkpoint.analyzed++
+		checkpoint.lastKey = append(checkpoint.lastKey[:0], key...)
+		batchEntries++
+
+		if newValue != nil {
+			checkpoint.changed++
+
+			if !dryRun {
+				if batch == nil {
+					if batch, err = store.Batched(); err != nil {
+						innerErr = err
+						return false
+					}
+				}
+
+				if err := batch.Set(concatBytes([]byte{step.Realm}, key), newValue); err != nil {
+					innerErr = err
+					return false
+				}
+			}
+		}
+
+		// the checkpoint is also written if no entries were changed,
+		// so an interrupted migration doesn't need to analyze the unchanged entries again.
+		if batchEntries >= migrationBatchSize {
+			if err := commitBatch(); err != nil {
+				innerErr = err
+				return false
+			}
+		}
+
+		return true
+	}); err != nil {
+		if batch != nil {
+			batch.Cancel()
+		}
+
+		return nil, ierrors.Wrap(err, "failed to iterate over the entries")
+	}
+
+	if innerErr != nil {
+		// the entries in the batch are persisted, because they were migrated successfully.
+		if err := commitBatch(); err != nil {
+			return nil, err
+		}
+
+		return nil, innerErr
+	}
+
+	if err := commitBatch(); err != nil {
+		return nil, err
+	}
+
+	report.Analyzed = checkpoint.analyzed
+	report.Changed = checkpoint.changed
+
+	return report, nil
+}
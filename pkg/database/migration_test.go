@@ -0,0 +1,267 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/hive.go/logger"
+	pebblestore "github.com/iotaledger/inx-api-core-v0/pkg/database/engine/pebble"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+)
+
+// newMigrationTestStore builds the test database with a tangle store of version 2.
+// It returns the tangle store and the metadata of the transactions in version 3 by their hash.
+func newMigrationTestStore(t *testing.T) (kvstore.KVStore, map[string][]byte) {
+	t.Helper()
+
+	tangleStore := mapdb.NewMapDB()
+
+	// the builder doesn't overwrite the version of the store
+	if _, err := kvstore.NewStoreHealthTracker(tangleStore, kvstore.KeyPrefix{StorePrefixHealth}, DBVersion-1, nil); err != nil {
+		t.Fatalf("failed to set database version: %v", err)
+	}
+
+	buildTestDatabase(t, tangleStore, mapdb.NewMapDB(), mapdb.NewMapDB())
+
+	metadataStore, err := tangleStore.WithRealm([]byte{StorePrefixTransactionMetadata})
+	if err != nil {
+		t.Fatalf("failed to create realm: %v", err)
+	}
+
+	// convert the metadata to the format of version 2
+	metadata := make(map[string][]byte)
+	if err := metadataStore.Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		metadata[string(key)] = append([]byte{}, value...)
+
+		return true
+	}); err != nil {
+		t.Fatalf("failed to iterate metadata: %v", err)
+	}
+
+	for txHash, value := range metadata {
+		txMeta := NewTransactionMetadata([]byte(txHash))
+		if err := txMeta.Unmarshal(value); err != nil {
+			t.Fatalf("failed to unmarshal metadata: %v", err)
+		}
+
+		valueV2 := make([]byte, 0, transactionMetadataSizeV2)
+		valueV2 = append(valueV2, byte(txMeta.metadata.ModifyBit(TransactionMetadataIsHead, false).
+			ModifyBit(TransactionMetadataIsTail, false).
+			ModifyBit(TransactionMetadataIsValue, false).
+			ModifyBit(TransactionMetadataIsMilestone, false)))
+		valueV2 = binary.LittleEndian.AppendUint32(valueV2, 0)
+		valueV2 = binary.LittleEndian.AppendUint32(valueV2, uint32(txMeta.confirmationIndex))
+		valueV2 = append(valueV2, make([]byte, 3*milestone.IndexByteSize)...)
+		valueV2 = append(valueV2, txMeta.trunkHash...)
+		valueV2 = append(valueV2, txMeta.branchHash...)
+		valueV2 = append(valueV2, txMeta.bundleHash...)
+
+		if err := metadataStore.Set([]byte(txHash), valueV2); err != nil {
+			t.Fatalf("failed to store metadata: %v", err)
+		}
+	}
+
+	return tangleStore, metadata
+}
+
+func storeContent(t *testing.T, store kvstore.KVStore) map[string]string {
+	t.Helper()
+
+	content := make(map[string]string)
+	if err := store.Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		content[string(key)] = string(value)
+
+		return true
+	}); err != nil {
+		t.Fatalf("failed to iterate store: %v", err)
+	}
+
+	return content
+}
+
+func checkStoreVersion(t *testing.T, store kvstore.KVStore, expectedCorrect bool) {
+	t.Helper()
+
+	healthTracker, err := kvstore.NewStoreHealthTracker(store, kvstore.KeyPrefix{StorePrefixHealth}, DBVersion, nil)
+	if err != nil {
+		t.Fatalf("failed to create health tracker: %v", err)
+	}
+
+	if correct, err := healthTracker.CheckCorrectStoreVersion(); err != nil || correct != expectedCorrect {
+		t.Fatalf("expected correct store version %v, got %v (%v)", expectedCorrect, correct, err)
+	}
+}
+
+func TestMigrateDryRun(t *testing.T) {
+	store, metadata := newMigrationTestStore(t)
+	contentBefore := storeContent(t, store)
+
+	report, err := Migrate(context.Background(), logger.NewExampleLogger("test"), DatabaseNameTangle, store, true)
+	if err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	if !report.DryRun || report.CurrentVersion != DBVersion-1 || report.TargetVersion != DBVersion || len(report.Steps) != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	if step := report.Steps[0]; step.Analyzed != uint64(len(metadata)) || step.Changed != uint64(len(metadata)) || step.Resumed {
+		t.Fatalf("unexpected step report: %+v", step)
+	}
+
+	contentAfter := storeContent(t, store)
+	if len(contentAfter) != len(contentBefore) {
+		t.Fatalf("expected %d entries after the dry-run, got %d", len(contentBefore), len(contentAfter))
+	}
+	for key, value := range contentBefore {
+		if contentAfter[key] != value {
+			t.Fatalf("entry %x was changed by the dry-run", key)
+		}
+	}
+
+	checkStoreVersion(t, store, false)
+}
+
+func TestMigrateResume(t *testing.T) {
+	store, metadata := newMigrationTestStore(t)
+
+	// the first run is canceled after two entries
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	step := *tangleMigrationSteps[0]
+	step.NewEntryFunc = func(ctx context.Context, log *logger.Logger, store kvstore.KVStore) (MigrationEntryFunc, error) {
+		migrateEntry, err := tangleMigrationSteps[0].NewEntryFunc(ctx, log, store)
+		if err != nil {
+			return nil, err
+		}
+
+		var migrated int
+
+		return func(key kvstore.Key, value kvstore.Value) (kvstore.Value, error) {
+			if migrated++; migrated == 2 {
+				cancel()
+			}
+
+			return migrateEntry(key, value)
+		}, nil
+	}
+
+	if _, err := runMigrationStep(ctx, logger.NewExampleLogger("test"), store, &step, false); !ierrors.Is(err, ErrOperationAborted) {
+		t.Fatalf("expected the migration to be aborted, got %v", err)
+	}
+
+	value, err := store.Get(databaseKeyForMigrationCheckpoint(&step))
+	if err != nil {
+		t.Fatalf("failed to load checkpoint: %v", err)
+	}
+	checkpoint, err := migrationCheckpointFromBytes(value)
+	if err != nil || checkpoint.analyzed != 2 || checkpoint.changed != 2 {
+		t.Fatalf("unexpected checkpoint: %+v (%v)", checkpoint, err)
+	}
+
+	checkStoreVersion(t, store, false)
+
+	// the second run continues after the checkpoint
+	report, err := Migrate(context.Background(), logger.NewExampleLogger("test"), DatabaseNameTangle, store, false)
+	if err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	if len(report.Steps) != 1 || !report.Steps[0].Resumed || report.Steps[0].Analyzed != uint64(len(metadata)) || report.Steps[0].Changed != uint64(len(metadata)) {
+		t.Fatalf("unexpected step reports: %+v", report.Steps)
+	}
+
+	for txHash, expected := range metadata {
+		value, err := store.Get(concatBytes([]byte{StorePrefixTransactionMetadata}, []byte(txHash)))
+		if err != nil || !bytes.Equal(value, expected) {
+			t.Fatalf("unexpected migrated metadata: %x != %x (%v)", value, expected, err)
+		}
+	}
+
+	if has, err := store.Has(databaseKeyForMigrationCheckpoint(&step)); err != nil || has {
+		t.Fatalf("expected the checkpoint to be deleted (%v)", err)
+	}
+
+	checkStoreVersion(t, store, true)
+}
+
+func TestMigrationStepCheckpointWithoutChanges(t *testing.T) {
+	const entriesCount = 25_000
+
+	// a pebble store is used, because the checkpoints are committed while the realm is iterated.
+	db, err := pebble.Open("", &pebble.Options{FS: vfs.NewMem()})
+	if err != nil {
+		t.Fatalf("failed to open pebble database: %v", err)
+	}
+	store := pebblestore.New(db)
+	defer func() { _ = store.Close() }()
+
+	for i := uint32(0); i < entriesCount; i++ {
+		if err := store.Set(binary.BigEndian.AppendUint32([]byte{StorePrefixTransactionMetadata}, i), []byte{}); err != nil {
+			t.Fatalf("failed to store entry: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var migrated int
+	var periodicCheckpoint *migrationCheckpoint
+
+	var step *MigrationStep
+	step = &MigrationStep{
+		Name:        "test",
+		FromVersion: DBVersion - 1,
+		ToVersion:   DBVersion,
+		Realm:       StorePrefixTransactionMetadata,
+		NewEntryFunc: func(_ context.Context, _ *logger.Logger, _ kvstore.KVStore) (MigrationEntryFunc, error) {
+			return func(key kvstore.Key, _ kvstore.Value) (kvstore.Value, error) {
+				migrated++
+
+				switch binary.BigEndian.Uint32(key) {
+				case 12_000:
+					value, err := store.Get(databaseKeyForMigrationCheckpoint(step))
+					if err != nil {
+						return nil, err
+					}
+					if periodicCheckpoint, err = migrationCheckpointFromBytes(value); err != nil {
+						return nil, err
+					}
+				case 15_000:
+					cancel()
+				}
+
+				// none of the entries are changed
+				return nil, nil
+			}, nil
+		},
+	}
+
+	if _, err := runMigrationStep(ctx, logger.NewExampleLogger("test"), store, step, false); !ierrors.Is(err, ErrOperationAborted) {
+		t.Fatalf("expected the migration to be aborted, got %v", err)
+	}
+
+	if periodicCheckpoint == nil || periodicCheckpoint.analyzed != migrationBatchSize || periodicCheckpoint.changed != 0 {
+		t.Fatalf("unexpected checkpoint during the migration: %+v", periodicCheckpoint)
+	}
+
+	// the entries are migrated one after another, so the checkpoint of the aborted run contains the entries 0 to 15000
+	migrated = 0
+	report, err := runMigrationStep(context.Background(), logger.NewExampleLogger("test"), store, step, false)
+	if err != nil {
+		t.Fatalf("failed to resume the migration: %v", err)
+	}
+
+	if !report.Resumed || report.Analyzed != entriesCount || report.Changed != 0 || migrated != entriesCount-15_001 {
+		t.Fatalf("unexpected resumed migration: %+v, migrated %d entries", report, migrated)
+	}
+}
@@ -1,10 +1,14 @@
 package database
 
 import (
+	"bytes"
+	"context"
 	"encoding/binary"
+	"sort"
 
 	"github.com/iotaledger/hive.go/ierrors"
 	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/runtime/contextutils"
 	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
 	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
 )
@@ -20,11 +24,15 @@ func milestoneIndexFromDatabaseKey(key []byte) milestone.Index {
 	return milestone.Index(binary.LittleEndian.Uint32(key))
 }
 
-func milestoneFactory(key []byte, data []byte) *Milestone {
+func milestoneFactory(key []byte, data []byte) (*Milestone, error) {
+	if len(data) < hornet.HashSize {
+		return nil, ierrors.Wrapf(ErrCorrupted, "invalid milestone length: %d", len(data))
+	}
+
 	return &Milestone{
 		Index: milestoneIndexFromDatabaseKey(key),
 		Hash:  hornet.Hash(data[:hornet.HashSize]),
-	}
+	}, nil
 }
 
 type Milestone struct {
@@ -32,29 +40,38 @@ type Milestone struct {
 	Hash  hornet.Hash
 }
 
-func (db *Database) MilestoneOrNil(milestoneIndex milestone.Index) *Milestone {
-	key := databaseKeyForMilestoneIndex(milestoneIndex)
+// MilestoneOrNil returns the milestone with the given index or nil if it doesn't exist.
+func (db *Database) MilestoneOrNil(milestoneIndex milestone.Index) (*Milestone, error) {
+	return db.caches.milestones.Get(milestoneIndex, func() (*Milestone, error) {
+		key := databaseKeyForMilestoneIndex(milestoneIndex)
 
-	data, err := db.milestoneStore.Get(key)
-	if err != nil {
-		if !ierrors.Is(err, kvstore.ErrKeyNotFound) {
-			panic(ierrors.Errorf("failed to get value from database: %w", err))
-		}
+		data, err := db.milestoneStore.Get(key)
+		if err != nil {
+			if !ierrors.Is(err, kvstore.ErrKeyNotFound) {
+				return nil, ierrors.Wrap(err, "failed to get value from database")
+			}
 
-		return nil
-	}
+			return nil, nil
+		}
 
-	milestone := milestoneFactory(key, data)
+		milestone, err := milestoneFactory(key, data)
+		if err != nil {
+			return nil, ierrors.Wrapf(err, "milestone %d", milestoneIndex)
+		}
 
-	return milestone
+		return milestone, nil
+	})
 }
 
 // MilestoneBundleOrNil returns the Bundle of a milestone index or nil if it doesn't exist.
-func (db *Database) MilestoneBundleOrNil(milestoneIndex milestone.Index) *Bundle {
+func (db *Database) MilestoneBundleOrNil(milestoneIndex milestone.Index) (*Bundle, error) {
 
-	milestone := db.MilestoneOrNil(milestoneIndex)
+	milestone, err := db.MilestoneOrNil(milestoneIndex)
+	if err != nil {
+		return nil, err
+	}
 	if milestone == nil {
-		return nil
+		return nil, nil
 	}
 
 	return db.BundleOrNil(milestone.Hash)
@@ -63,28 +80,114 @@ func (db *Database) MilestoneBundleOrNil(milestoneIndex milestone.Index) *Bundle
 // MilestoneTimestamp returns the timestamp of a milestone.
 func (db *Database) MilestoneTimestamp(milestoneIndex milestone.Index) (uint64, error) {
 
-	milestone := db.MilestoneOrNil(milestoneIndex)
+	milestone, err := db.MilestoneOrNil(milestoneIndex)
+	if err != nil {
+		return 0, err
+	}
 	if milestone == nil {
-		return 0, ierrors.Errorf("milestone %d not found", milestoneIndex)
+		return 0, ierrors.Wrapf(ErrMilestoneNotFound, "index: %d", milestoneIndex)
 	}
 
-	tx := db.TransactionOrNil(milestone.Hash)
+	tx, err := db.TransactionOrNil(milestone.Hash)
+	if err != nil {
+		return 0, err
+	}
 	if tx == nil {
-		return 0, ierrors.Errorf("milestone %d tail transaction not found", milestoneIndex)
+		// databases that were imported from a local snapshot only contain the milestone entry of the snapshot milestone,
+		// its timestamp is part of the snapshot info.
+		if milestoneIndex == db.snapshot.SnapshotIndex && bytes.Equal(milestone.Hash, db.snapshot.Hash) && db.snapshot.Timestamp >= 0 {
+			return uint64(db.snapshot.Timestamp), nil
+		}
+
+		return 0, ierrors.Wrapf(ErrCorrupted, "milestone %d tail transaction not found", milestoneIndex)
 	}
 
 	return tx.Tx.Timestamp, nil
 }
 
-func (db *Database) LedgerIndex() milestone.Index {
-	db.ledgerMilestoneIndexOnce.Do(func() {
-		value, err := db.ledgerStore.Get([]byte(ledgerMilestoneIndexKey))
+// MilestoneIndexByHash returns the index of the milestone with the given tail transaction hash.
+func (db *Database) MilestoneIndexByHash(milestoneHash hornet.Hash) (milestone.Index, error) {
+	txMeta, err := db.TxMetadataOrNil(milestoneHash)
+	if err != nil {
+		return 0, err
+	}
+	if txMeta == nil || !txMeta.IsMilestone() {
+		return 0, ierrors.Wrapf(ErrMilestoneNotFound, "hash: %s", milestoneHash.Trytes())
+	}
+
+	// check that the milestone of that index is the given transaction,
+	// because the transaction metadata is also set for invalid milestone candidates.
+	ms, err := db.MilestoneOrNil(txMeta.MilestoneIndex())
+	if err != nil {
+		return 0, err
+	}
+	if ms == nil || !bytes.Equal(ms.Hash, milestoneHash) {
+		return 0, ierrors.Wrapf(ErrMilestoneNotFound, "hash: %s", milestoneHash.Trytes())
+	}
+
+	return ms.Index, nil
+}
+
+// MilestoneIndexByTimestamp returns the index of the latest milestone with a timestamp lower or equal
+// to the given unix timestamp, which is the milestone that was in effect at that time.
+// Only the solid milestones between the pruning index and the solid milestone index are binary searched,
+// which relies on the milestone timestamps being monotonic.
+func (db *Database) MilestoneIndexByTimestamp(timestamp uint64) (milestone.Index, error) {
+	lowestIndex := db.PruningIndex() + 1
+	highestIndex := db.SolidMilestoneIndex()
+	if highestIndex < lowestIndex {
+		return 0, ierrors.Wrap(ErrMilestoneNotFound, "no milestones available")
+	}
+
+	var innerErr error
+	count := int(highestIndex - lowestIndex + 1)
+
+	// search the first milestone that is newer than the given timestamp
+	pos := sort.Search(count, func(i int) bool {
+		if innerErr != nil {
+			return true
+		}
+
+		msTimestamp, err := db.MilestoneTimestamp(lowestIndex + milestone.Index(i))
 		if err != nil {
-			panic(ierrors.Errorf("%w: failed to load ledger milestone index", err))
+			innerErr = err
+			return true
 		}
-		db.ledgerMilestoneIndex = milestoneIndexFromBytes(value)
+
+		return msTimestamp > timestamp
 	})
+	if innerErr != nil {
+		return 0, innerErr
+	}
+
+	if pos == 0 {
+		return 0, ierrors.Wrapf(ErrMilestoneNotFound, "no milestone before timestamp %d", timestamp)
+	}
+
+	return lowestIndex + milestone.Index(pos-1), nil
+}
+
+func (db *Database) loadLedgerIndex() error {
+	value, err := db.ledgerStore.Get([]byte(ledgerMilestoneIndexKey))
+	if err != nil {
+		if ierrors.Is(err, kvstore.ErrKeyNotFound) {
+			return ierrors.Wrap(ErrCorrupted, "ledger milestone index not found")
+		}
+
+		return ierrors.Wrap(err, "failed to load ledger milestone index")
+	}
+
+	if len(value) != milestone.IndexByteSize {
+		return ierrors.Wrapf(ErrCorrupted, "invalid ledger milestone index length: %d", len(value))
+	}
+	db.ledgerMilestoneIndex = milestoneIndexFromBytes(value)
 
+	return nil
+}
+
+// LedgerIndex returns the milestone index of the ledger state.
+// It is loaded when the database is opened.
+func (db *Database) LedgerIndex() milestone.Index {
 	return db.ledgerMilestoneIndex
 }
 
@@ -95,15 +198,108 @@ func (db *Database) SolidMilestoneIndex() milestone.Index {
 }
 
 // LatestSolidMilestoneBundle returns the latest solid milestone bundle.
-func (db *Database) LatestSolidMilestoneBundle() *Bundle {
-	db.latestSolidMilestoneBundleOnce.Do(func() {
-		latestSolidMilestoneIndex := db.SolidMilestoneIndex()
-		latestSolidMilestoneBundle := db.MilestoneBundleOrNil(latestSolidMilestoneIndex)
-		if latestSolidMilestoneBundle == nil {
-			panic(ierrors.Errorf("latest solid milestone bundle not found: %d", latestSolidMilestoneIndex))
-		}
-		db.latestSolidMilestoneBundle = latestSolidMilestoneBundle
+func (db *Database) LatestSolidMilestoneBundle() (*Bundle, error) {
+	db.latestSolidMilestoneBundleLock.Lock()
+	defer db.latestSolidMilestoneBundleLock.Unlock()
+
+	if db.latestSolidMilestoneBundle != nil {
+		return db.latestSolidMilestoneBundle, nil
+	}
+
+	latestSolidMilestoneIndex := db.SolidMilestoneIndex()
+	latestSolidMilestoneBundle, err := db.MilestoneBundleOrNil(latestSolidMilestoneIndex)
+	if err != nil {
+		return nil, err
+	}
+	if latestSolidMilestoneBundle == nil {
+		return nil, ierrors.Wrapf(ErrCorrupted, "latest solid milestone bundle not found: %d", latestSolidMilestoneIndex)
+	}
+	db.latestSolidMilestoneBundle = latestSolidMilestoneBundle
+
+	return db.latestSolidMilestoneBundle, nil
+}
+
+// MilestoneConfirmedTransactions returns the metadata of all transactions that were confirmed by the given milestone,
+// including zero-value transactions. The result is sorted by the transaction hash.
+// The result is cached, so it must not be modified by the caller.
+//
+// Concurrent calls for the same milestone share a single walk of the cone. The walk is not bound to the context
+// of the caller that started it, so a canceled request doesn't abort it for the others.
+// The walk is only canceled if all callers gave up waiting, in that case nothing is cached.
+func (db *Database) MilestoneConfirmedTransactions(ctx context.Context, milestoneIndex milestone.Index) ([]*TransactionMetadata, error) {
+	confirmedTxs, err := db.milestoneConeWalks.Do(ctx, milestoneIndex, func(walkCtx context.Context) (*[]*TransactionMetadata, error) {
+		return db.caches.milestoneConfirmedTransactions.Get(milestoneIndex, func() (*[]*TransactionMetadata, error) {
+			confirmedTxs, err := db.walkMilestoneConfirmedTransactions(walkCtx, milestoneIndex)
+			if err != nil {
+				return nil, err
+			}
+
+			return &confirmedTxs, nil
+		})
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return *confirmedTxs, nil
+}
+
+// walkMilestoneConfirmedTransactions walks the cone of the given milestone to collect the transactions it confirmed.
+func (db *Database) walkMilestoneConfirmedTransactions(ctx context.Context, milestoneIndex milestone.Index) ([]*TransactionMetadata, error) {
+
+	msBndl, err := db.MilestoneBundleOrNil(milestoneIndex)
+	if err != nil {
+		return nil, err
+	}
+	if msBndl == nil {
+		return nil, ierrors.Wrapf(ErrMilestoneNotFound, "index: %d", milestoneIndex)
+	}
+
+	confirmedTxs := make([]*TransactionMetadata, 0)
+	visited := make(map[string]struct{})
+	txsToTraverse := hornet.Hashes{msBndl.TailHash()}
+
+	for len(txsToTraverse) > 0 {
+		if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
+			return nil, err
+		}
+
+		txHash := txsToTraverse[len(txsToTraverse)-1]
+		txsToTraverse = txsToTraverse[:len(txsToTraverse)-1]
+
+		if _, exists := visited[string(txHash)]; exists {
+			continue
+		}
+		visited[string(txHash)] = struct{}{}
+
+		if db.SolidEntryPointsContain(txHash) {
+			// ignore solid entry points (snapshot milestone included)
+			continue
+		}
+
+		txMeta, err := db.TxMetadataOrNil(txHash)
+		if err != nil {
+			return nil, err
+		}
+		if txMeta == nil {
+			return nil, ierrors.Wrapf(ErrCorrupted, "transaction in the cone of milestone %d not found: %s", milestoneIndex, txHash.Trytes())
+		}
+
+		confirmed, at := txMeta.ConfirmedWithIndex()
+		if !confirmed {
+			return nil, ierrors.Wrapf(ErrCorrupted, "transaction in the cone of milestone %d not confirmed: %s", milestoneIndex, txHash.Trytes())
+		}
+
+		if at != milestoneIndex {
+			// ignore all transactions that were confirmed by another milestone
+			continue
+		}
+
+		confirmedTxs = append(confirmedTxs, txMeta)
+		txsToTraverse = append(txsToTraverse, txMeta.TrunkHash(), txMeta.BranchHash())
+	}
+
+	sort.Slice(confirmedTxs, func(i, j int) bool { return bytes.Compare(confirmedTxs[i].TxHash(), confirmedTxs[j].TxHash()) < 0 })
 
-	return db.latestSolidMilestoneBundle
+	return confirmedTxs, nil
 }
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+)
+
+func TestMilestoneIndexByTimestamp(t *testing.T) {
+	db, _ := newTestDatabase(t)
+
+	tests := []struct {
+		name      string
+		timestamp uint64
+		expected  milestone.Index
+	}{
+		{name: "first milestone", timestamp: testMilestoneTimestamp(1), expected: 1},
+		{name: "between milestones", timestamp: testMilestoneTimestamp(2) + 30, expected: 2},
+		{name: "latest milestone", timestamp: testMilestoneTimestamp(3), expected: 3},
+		{name: "after the solid milestone", timestamp: testMilestoneTimestamp(100), expected: 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			msIndex, err := db.MilestoneIndexByTimestamp(test.timestamp)
+			if err != nil {
+				t.Fatalf("failed to find milestone: %v", err)
+			}
+
+			if msIndex != test.expected {
+				t.Fatalf("expected milestone %d, got %d", test.expected, msIndex)
+			}
+		})
+	}
+
+	if _, err := db.MilestoneIndexByTimestamp(testMilestoneTimestamp(1) - 1); !ierrors.Is(err, ErrMilestoneNotFound) {
+		t.Fatalf("expected ErrMilestoneNotFound before the first milestone, got %v", err)
+	}
+}
+
+func TestMilestoneConfirmedTransactions(t *testing.T) {
+	db, fixture := newTestDatabase(t)
+	db.caches = newCaches(CacheSizes{MilestoneConfirmedTransactions: 10})
+
+	// the cone of milestone 2 only contains its own tail and milestone 1, which was confirmed before
+	expected := map[string]struct{}{
+		string(fixture.milestoneTails[2]): {},
+	}
+
+	for i := 0; i < 2; i++ {
+		confirmedTxs, err := db.MilestoneConfirmedTransactions(context.Background(), 2)
+		if err != nil {
+			t.Fatalf("failed to get confirmed transactions: %v", err)
+		}
+
+		if len(confirmedTxs) != len(expected) {
+			t.Fatalf("expected %d confirmed transactions, got %d", len(expected), len(confirmedTxs))
+		}
+
+		for j, txMeta := range confirmedTxs {
+			if _, exists := expected[string(txMeta.TxHash())]; !exists {
+				t.Fatalf("unexpected confirmed transaction: %s", txMeta.TxHash().Trytes())
+			}
+
+			if j > 0 && bytes.Compare(confirmedTxs[j-1].TxHash(), txMeta.TxHash()) >= 0 {
+				t.Fatalf("confirmed transactions are not sorted")
+			}
+		}
+	}
+
+	// the cone is only walked once
+	if metrics := db.CacheMetrics()[CacheNameMilestoneConfirmedTransactions]; metrics.Misses != 1 || metrics.Hits != 1 {
+		t.Fatalf("expected 1 miss and 1 hit, got %d misses and %d hits", metrics.Misses, metrics.Hits)
+	}
+}
+
+func TestMilestoneConfirmedTransactionsCanceledRequest(t *testing.T) {
+	db, _ := newTestDatabase(t)
+	db.caches = newCaches(CacheSizes{MilestoneConfirmedTransactions: 10})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// the canceled request may or may not wait for the walk, if it gave up, the walk is canceled
+	if _, err := db.MilestoneConfirmedTransactions(ctx, 3); err != nil && !ierrors.Is(err, ErrOperationAborted) {
+		t.Fatalf("expected ErrOperationAborted, got %v", err)
+	}
+
+	confirmedTxs, err := db.MilestoneConfirmedTransactions(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("failed to get confirmed transactions: %v", err)
+	}
+
+	// milestone 3 confirmed its own tail and the value bundle
+	if len(confirmedTxs) != 3 {
+		t.Fatalf("expected 3 confirmed transactions, got %d", len(confirmedTxs))
+	}
+
+	// the second request either hit the cache or walked the cone again, because the canceled walk cached nothing
+	if metrics := db.CacheMetrics()[CacheNameMilestoneConfirmedTransactions]; metrics.Misses < 1 || metrics.Misses > 2 {
+		t.Fatalf("expected 1 or 2 misses, got %d", metrics.Misses)
+	}
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"sync"
+)
+
+// sharedCall is a call that is shared by all concurrent callers with the same key.
+type sharedCall[T any] struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	// the amount of callers that still wait for the result
+	waiters int
+
+	value T
+	err   error
+}
+
+// sharedCalls deduplicates concurrent calls with the same key, similar to singleflight.Group.
+// In contrast to singleflight, the shared call is canceled as soon as the last waiting caller gave up,
+// so abandoned calls don't keep running in the background.
+type sharedCalls[K comparable, T any] struct {
+	lock  sync.Mutex
+	calls map[K]*sharedCall[T]
+}
+
+// Do executes fn once for all concurrent callers with the same key and returns its result.
+// The context passed to fn is independent of the context of the caller that started the call,
+// it is only canceled if the contexts of all waiting callers are done.
+// Callers whose context is done return ErrOperationAborted.
+func (g *sharedCalls[K, T]) Do(ctx context.Context, key K, fn func(ctx context.Context) (T, error)) (T, error) {
+	g.lock.Lock()
+	if g.calls == nil {
+		g.calls = make(map[K]*sharedCall[T])
+	}
+
+	call, exists := g.calls[key]
+	if !exists {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+		call = &sharedCall[T]{
+			cancel: cancel,
+			done:   make(chan struct{}),
+		}
+		g.calls[key] = call
+
+		go func() {
+			defer cancel()
+
+			call.value, call.err = fn(callCtx)
+
+			g.lock.Lock()
+			g.remove(key, call)
+			g.lock.Unlock()
+
+			close(call.done)
+		}()
+	}
+	call.waiters++
+	g.lock.Unlock()
+
+	select {
+	case <-call.done:
+		return call.value, call.err
+
+	case <-ctx.Done():
+		g.lock.Lock()
+		defer g.lock.Unlock()
+
+		call.waiters--
+		if call.waiters == 0 {
+			// nobody waits for the result anymore, later callers start a new call
+			call.cancel()
+			g.remove(key, call)
+		}
+
+		var zero T
+
+		return zero, ErrOperationAborted
+	}
+}
+
+// remove removes the call, if it is still the current call for the key.
+// The lock must be held by the caller.
+func (g *sharedCalls[K, T]) remove(key K, call *sharedCall[T]) {
+	if g.calls[key] == call {
+		delete(g.calls, key)
+	}
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+)
+
+// startBlockingCall starts a shared call that blocks until release is closed and returns the context the call runs with.
+func startBlockingCall(ctx context.Context, calls *sharedCalls[int, int], release <-chan struct{}, results chan<- error) <-chan context.Context {
+	callCtxChan := make(chan context.Context, 1)
+	go func() {
+		_, err := calls.Do(ctx, 1, func(callCtx context.Context) (int, error) {
+			callCtxChan <- callCtx
+
+			select {
+			case <-release:
+				return 42, nil
+			case <-callCtx.Done():
+				return 0, ErrOperationAborted
+			}
+		})
+		results <- err
+	}()
+
+	return callCtxChan
+}
+
+func TestSharedCallsLastWaiterCancels(t *testing.T) {
+	var calls sharedCalls[int, int]
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results := make(chan error, 1)
+	callCtx := <-startBlockingCall(ctx, &calls, make(chan struct{}), results)
+
+	cancel()
+	if err := <-results; !ierrors.Is(err, ErrOperationAborted) {
+		t.Fatalf("expected ErrOperationAborted, got %v", err)
+	}
+
+	select {
+	case <-callCtx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the call to be canceled after the last waiter gave up")
+	}
+
+	// a later caller starts a new call
+	value, err := calls.Do(context.Background(), 1, func(context.Context) (int, error) { return 7, nil })
+	if err != nil || value != 7 {
+		t.Fatalf("expected a new call to return 7, got %d, %v", value, err)
+	}
+}
+
+func TestSharedCallsRemainingWaiter(t *testing.T) {
+	var calls sharedCalls[int, int]
+
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	results := make(chan error, 1)
+	callCtx := <-startBlockingCall(ctx, &calls, release, results)
+
+	// the second caller joins the running call
+	joined := make(chan int, 1)
+	go func() {
+		value, err := calls.Do(context.Background(), 1, func(context.Context) (int, error) {
+			t.Error("expected the running call to be shared")
+
+			return 0, nil
+		})
+		if err != nil {
+			t.Errorf("failed to get the shared result: %v", err)
+		}
+		joined <- value
+	}()
+
+	// wait until the second caller is registered as a waiter
+	for {
+		calls.lock.Lock()
+		waiters := calls.calls[1].waiters
+		calls.lock.Unlock()
+		if waiters == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	if err := <-results; !ierrors.Is(err, ErrOperationAborted) {
+		t.Fatalf("expected ErrOperationAborted, got %v", err)
+	}
+	if callCtx.Err() != nil {
+		t.Fatal("expected the call to keep running while another caller waits")
+	}
+
+	close(release)
+	if value := <-joined; value != 42 {
+		t.Fatalf("expected 42, got %d", value)
+	}
+}
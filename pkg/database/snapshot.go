@@ -39,6 +39,11 @@ func (db *Database) readSnapshotInfo() (*SnapshotInfo, error) {
 	return info, nil
 }
 
+// PruningIndex returns the milestone index up to which the database was pruned.
+func (db *Database) PruningIndex() milestone.Index {
+	return db.snapshot.PruningIndex
+}
+
 func (db *Database) loadSnapshotInfo() error {
 	info, err := db.readSnapshotInfo()
 	if err != nil {
@@ -84,6 +89,21 @@ func snapshotInfoFromBytes(bytes []byte) (*SnapshotInfo, error) {
 	}, nil
 }
 
+// Bytes returns the binary representation of the snapshot info as it is stored in the database.
+func (i *SnapshotInfo) Bytes() []byte {
+	bytes := make([]byte, 119)
+
+	copy(bytes[:hornet.HashSize], i.CoordinatorAddress)
+	copy(bytes[hornet.HashSize:2*hornet.HashSize], i.Hash)
+	binary.LittleEndian.PutUint32(bytes[2*hornet.HashSize:102], uint32(i.SnapshotIndex))
+	binary.LittleEndian.PutUint32(bytes[102:106], uint32(i.EntryPointIndex))
+	binary.LittleEndian.PutUint32(bytes[106:110], uint32(i.PruningIndex))
+	binary.LittleEndian.PutUint64(bytes[110:118], uint64(i.Timestamp))
+	bytes[118] = byte(i.Metadata)
+
+	return bytes
+}
+
 func (i *SnapshotInfo) IsSpentAddressesEnabled() bool {
 	return i.Metadata.HasBit(SnapshotMetadataSpentAddressesEnabled)
 }
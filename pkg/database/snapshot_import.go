@@ -0,0 +1,220 @@
+package database
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"io"
+	"time"
+
+	"github.com/iotaledger/hive.go/ds/bitmask"
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hive.go/runtime/contextutils"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+	"github.com/iotaledger/iota.go/consts"
+)
+
+const (
+	// LegacySnapshotFileVersion is the supported version of the legacy local snapshot files.
+	LegacySnapshotFileVersion byte = 4
+)
+
+// LegacySnapshotHeader is the header of a legacy local snapshot file.
+type LegacySnapshotHeader struct {
+	// MilestoneHash is the hash of the tail transaction of the snapshot milestone.
+	MilestoneHash         hornet.Hash
+	MilestoneIndex        milestone.Index
+	MilestoneTimestamp    int64
+	SolidEntryPointsCount int32
+	SeenMilestonesCount   int32
+	LedgerEntriesCount    int32
+	SpentAddressesCount   int32
+}
+
+// LegacySnapshotImportResult contains the header of the imported legacy local snapshot file and the amount of imported entries.
+type LegacySnapshotImportResult struct {
+	Header         *LegacySnapshotHeader
+	LedgerEntries  int
+	TotalBalance   uint64
+	SpentAddresses int
+}
+
+// ImportLegacySnapshot reads a gzip compressed legacy local snapshot file and writes the ledger state,
+// the spent addresses, the solid entry points and the snapshot info with the given Builder.
+// The resulting databases only contain the ledger state at the snapshot milestone, there are no transactions.
+//
+// The file layout is (all integers little endian):
+//
+//	version                byte
+//	milestoneHash          [49]byte
+//	milestoneIndex         uint32
+//	milestoneTimestamp     int64
+//	solidEntryPointsCount  int32
+//	seenMilestonesCount    int32
+//	ledgerEntriesCount     int32
+//	spentAddressesCount    int32
+//	solidEntryPoints       solidEntryPointsCount * (hash [49]byte, milestoneIndex uint32)
+//	seenMilestones         seenMilestonesCount * (hash [49]byte, milestoneIndex uint32)
+//	ledgerEntries          ledgerEntriesCount * (address [49]byte, balance uint64)
+//	spentAddresses         spentAddressesCount * address [49]byte
+func ImportLegacySnapshot(ctx context.Context, log *logger.Logger, reader io.Reader, builder *Builder, coordinatorAddress hornet.Hash) (*LegacySnapshotImportResult, error) {
+
+	gzipReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, ierrors.Wrap(err, "failed to open gzip stream")
+	}
+	defer func() { _ = gzipReader.Close() }()
+
+	var version byte
+	if err := binary.Read(gzipReader, binary.LittleEndian, &version); err != nil {
+		return nil, ierrors.Wrap(err, "failed to read file version")
+	}
+
+	if version != LegacySnapshotFileVersion {
+		return nil, ierrors.Errorf("unsupported local snapshot file version: %d != %d", version, LegacySnapshotFileVersion)
+	}
+
+	header := &LegacySnapshotHeader{
+		MilestoneHash: make(hornet.Hash, hornet.HashSize),
+	}
+
+	if _, err := io.ReadFull(gzipReader, header.MilestoneHash); err != nil {
+		return nil, ierrors.Wrap(err, "failed to read milestone hash")
+	}
+
+	for _, field := range []struct {
+		name  string
+		value interface{}
+	}{
+		{"milestone index", &header.MilestoneIndex},
+		{"milestone timestamp", &header.MilestoneTimestamp},
+		{"solid entry points count", &header.SolidEntryPointsCount},
+		{"seen milestones count", &header.SeenMilestonesCount},
+		{"ledger entries count", &header.LedgerEntriesCount},
+		{"spent addresses count", &header.SpentAddressesCount},
+	} {
+		if err := binary.Read(gzipReader, binary.LittleEndian, field.value); err != nil {
+			return nil, ierrors.Wrapf(err, "failed to read %s", field.name)
+		}
+	}
+
+	if header.SolidEntryPointsCount < 0 || header.SeenMilestonesCount < 0 || header.LedgerEntriesCount < 0 || header.SpentAddressesCount < 0 {
+		return nil, ierrors.New("invalid negative entries count in header")
+	}
+
+	// the pruning index is set to the milestone before the snapshot
+	if header.MilestoneIndex == 0 {
+		return nil, ierrors.New("invalid milestone index 0 in header")
+	}
+
+	result := &LegacySnapshotImportResult{
+		Header: header,
+	}
+
+	log.Infof("importing local snapshot (milestone: %d, solid entry points: %d, ledger entries: %d, spent addresses: %d)",
+		header.MilestoneIndex, header.SolidEntryPointsCount, header.LedgerEntriesCount, header.SpentAddressesCount)
+
+	// readEntries reads the given amount of entries with a hash and a value of the given size.
+	readEntries := func(name string, count int32, valueSize int, consumer func(hash hornet.Hash, value []byte) error) error {
+		lastStatusTime := time.Now()
+
+		for i := int32(0); i < count; i++ {
+			entry := make([]byte, hornet.HashSize+valueSize)
+			if _, err := io.ReadFull(gzipReader, entry); err != nil {
+				return ierrors.Wrapf(err, "failed to read %s %d", name, i)
+			}
+
+			if err := consumer(entry[:hornet.HashSize], entry[hornet.HashSize:]); err != nil {
+				return err
+			}
+
+			if time.Since(lastStatusTime) >= PrintStatusInterval {
+				lastStatusTime = time.Now()
+
+				// check if the context was already canceled
+				if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
+					return err
+				}
+
+				log.Infof("	imported %d/%d %s", i, count, name)
+			}
+		}
+
+		return nil
+	}
+
+	solidEntryPoints := NewSolidEntryPoints()
+	if err := readEntries("solid entry points", header.SolidEntryPointsCount, milestone.IndexByteSize, func(hash hornet.Hash, value []byte) error {
+		solidEntryPoints.Add(hash, milestone.Index(binary.LittleEndian.Uint32(value)))
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	// the snapshot milestone itself is always a solid entry point
+	solidEntryPoints.Add(header.MilestoneHash, header.MilestoneIndex)
+
+	// the seen milestones are newer than the snapshot and therefore not part of the imported ledger state
+	if err := readEntries("seen milestones", header.SeenMilestonesCount, milestone.IndexByteSize, func(_ hornet.Hash, _ []byte) error {
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := readEntries("ledger entries", header.LedgerEntriesCount, 8, func(address hornet.Hash, value []byte) error {
+		balance := binary.LittleEndian.Uint64(value)
+		result.TotalBalance += balance
+		result.LedgerEntries++
+
+		return builder.StoreLedgerBalance(address, balance)
+	}); err != nil {
+		return nil, err
+	}
+
+	if result.TotalBalance != consts.TotalSupply {
+		return nil, ierrors.Wrapf(ErrCorrupted, "total balance of the snapshot does not match supply: %d != %d", result.TotalBalance, consts.TotalSupply)
+	}
+
+	if err := readEntries("spent addresses", header.SpentAddressesCount, 0, func(address hornet.Hash, _ []byte) error {
+		result.SpentAddresses++
+
+		return builder.StoreSpentAddress(address)
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := builder.StoreSolidEntryPoints(solidEntryPoints); err != nil {
+		return nil, err
+	}
+
+	// the milestone entry is needed to resolve the hash of the latest solid milestone.
+	// there is no tail transaction, so the timestamp of the milestone is taken from the snapshot info.
+	if err := builder.StoreMilestone(header.MilestoneIndex, header.MilestoneHash); err != nil {
+		return nil, err
+	}
+
+	if err := builder.StoreLedgerIndex(header.MilestoneIndex); err != nil {
+		return nil, err
+	}
+
+	// there are no ledger diffs, so everything before the snapshot index is pruned.
+	if err := builder.StoreSnapshotInfo(&SnapshotInfo{
+		CoordinatorAddress: coordinatorAddress,
+		Hash:               header.MilestoneHash,
+		SnapshotIndex:      header.MilestoneIndex,
+		EntryPointIndex:    header.MilestoneIndex,
+		PruningIndex:       header.MilestoneIndex - 1,
+		Timestamp:          header.MilestoneTimestamp,
+		Metadata:           bitmask.BitMask(0).ModifyBit(SnapshotMetadataSpentAddressesEnabled, true),
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := builder.Flush(); err != nil {
+		return nil, ierrors.Wrap(err, "failed to flush databases")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,212 @@
+package database
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/trinary"
+)
+
+const (
+	testSnapshotIndex     milestone.Index = 100
+	testSnapshotTimestamp int64           = 1_600_000_000
+)
+
+var testSnapshotMilestoneHash = hornet.HashFromHashTrytes(strings.Repeat("M", 81))
+
+// testLegacySnapshot returns the uncompressed content of a legacy local snapshot file with the given version.
+// The ledger contains the addresses A (supply - 10) and B (10), A is spent.
+func testLegacySnapshot(version byte) []byte {
+	var buf bytes.Buffer
+
+	write := func(value interface{}) {
+		_ = binary.Write(&buf, binary.LittleEndian, value)
+	}
+
+	addressA := hornet.HashFromAddressTrytes(testAddressA)
+	addressB := hornet.HashFromAddressTrytes(testAddressB)
+
+	write(version)
+	write([]byte(testSnapshotMilestoneHash))
+	write(uint32(testSnapshotIndex))
+	write(testSnapshotTimestamp)
+	write(int32(1)) // solid entry points
+	write(int32(1)) // seen milestones
+	write(int32(2)) // ledger entries
+	write(int32(1)) // spent addresses
+
+	write([]byte(hornet.HashFromHashTrytes(strings.Repeat("S", 81))))
+	write(uint32(testSnapshotIndex - 1))
+
+	write([]byte(hornet.HashFromHashTrytes(strings.Repeat("N", 81))))
+	write(uint32(testSnapshotIndex + 1))
+
+	write([]byte(addressA))
+	write(consts.TotalSupply - uint64(10))
+	write([]byte(addressB))
+	write(uint64(10))
+
+	write([]byte(addressA))
+
+	return buf.Bytes()
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	if _, err := gzipWriter.Write(data); err != nil {
+		t.Fatalf("failed to compress snapshot: %v", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatalf("failed to compress snapshot: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func importTestLegacySnapshot(t *testing.T, data []byte) (*LegacySnapshotImportResult, *Database, error) {
+	t.Helper()
+
+	tangleStore := mapdb.NewMapDB()
+	snapshotStore := mapdb.NewMapDB()
+	spentStore := mapdb.NewMapDB()
+
+	builder, err := NewBuilder(tangleStore, snapshotStore, spentStore)
+	if err != nil {
+		t.Fatalf("failed to create builder: %v", err)
+	}
+
+	result, err := ImportLegacySnapshot(context.Background(), logger.NewExampleLogger("test"), bytes.NewReader(gzipBytes(t, data)), builder, hornet.HashFromAddressTrytes(testCoordinatorAddress))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := NewWithStores(context.Background(), nil, tangleStore, snapshotStore, spentStore, false, CacheSizes{})
+	if err != nil {
+		t.Fatalf("failed to open imported database: %v", err)
+	}
+
+	return result, db, nil
+}
+
+func TestImportLegacySnapshot(t *testing.T) {
+	result, db, err := importTestLegacySnapshot(t, testLegacySnapshot(LegacySnapshotFileVersion))
+	if err != nil {
+		t.Fatalf("failed to import snapshot: %v", err)
+	}
+
+	if result.Header.MilestoneIndex != testSnapshotIndex || result.Header.MilestoneTimestamp != testSnapshotTimestamp || !bytes.Equal(result.Header.MilestoneHash, testSnapshotMilestoneHash) {
+		t.Fatalf("unexpected header: %+v", result.Header)
+	}
+
+	if result.LedgerEntries != 2 || result.SpentAddresses != 1 || result.TotalBalance != consts.TotalSupply {
+		t.Fatalf("unexpected import result: %+v", result)
+	}
+
+	if db.SolidMilestoneIndex() != testSnapshotIndex || db.PruningIndex() != testSnapshotIndex-1 {
+		t.Fatalf("unexpected milestone indexes: solid %d, pruning %d", db.SolidMilestoneIndex(), db.PruningIndex())
+	}
+
+	var totalBalance uint64
+	for address, expected := range map[trinary.Trytes]uint64{testAddressA: consts.TotalSupply - 10, testAddressB: 10} {
+		balance, ledgerIndex, err := db.BalanceForAddress(hornet.HashFromAddressTrytes(address))
+		if err != nil || balance != expected || ledgerIndex != testSnapshotIndex {
+			t.Fatalf("unexpected balance of %s: %d at %d (%v)", address, balance, ledgerIndex, err)
+		}
+		totalBalance += balance
+	}
+	if totalBalance != consts.TotalSupply {
+		t.Fatalf("expected the total supply, got %d", totalBalance)
+	}
+
+	for address, expected := range map[trinary.Trytes]bool{testAddressA: true, testAddressB: false} {
+		if spent, err := db.WasAddressSpentFrom(hornet.HashFromAddressTrytes(address)); err != nil || spent != expected {
+			t.Fatalf("expected spent state %v of %s, got %v (%v)", expected, address, spent, err)
+		}
+	}
+
+	// the snapshot milestone has no tail transaction, the timestamp is taken from the snapshot info
+	if timestamp, err := db.MilestoneTimestamp(testSnapshotIndex); err != nil || timestamp != uint64(testSnapshotTimestamp) {
+		t.Fatalf("unexpected timestamp of the snapshot milestone: %d (%v)", timestamp, err)
+	}
+
+	if msIndex, err := db.MilestoneIndexByTimestamp(uint64(testSnapshotTimestamp)); err != nil || msIndex != testSnapshotIndex {
+		t.Fatalf("unexpected milestone by timestamp: %d (%v)", msIndex, err)
+	}
+
+	syncState, err := db.LatestSyncState()
+	if err != nil || syncState.LatestSolidSubtangleMilestone != testSnapshotMilestoneHash.Trytes() {
+		t.Fatalf("unexpected sync state: %+v (%v)", syncState, err)
+	}
+}
+
+func TestImportLegacySnapshotInvalid(t *testing.T) {
+	valid := testLegacySnapshot(LegacySnapshotFileVersion)
+
+	// the balance of B is the last 8 bytes before the spent address
+	invalidSupply := bytes.Clone(valid)
+	invalidSupply[len(invalidSupply)-hornet.HashSize-8]++
+
+	// the milestone index follows the version and the milestone hash
+	zeroIndex := bytes.Clone(valid)
+	binary.LittleEndian.PutUint32(zeroIndex[1+hornet.HashSize:], 0)
+
+	tests := []struct {
+		name  string
+		data  []byte
+		check func(err error) bool
+	}{
+		{
+			name:  "wrong version",
+			data:  testLegacySnapshot(LegacySnapshotFileVersion - 1),
+			check: func(err error) bool { return strings.Contains(err.Error(), "unsupported local snapshot file version") },
+		},
+		{
+			name:  "milestone index 0",
+			data:  zeroIndex,
+			check: func(err error) bool { return strings.Contains(err.Error(), "invalid milestone index 0") },
+		},
+		{
+			name:  "truncated header",
+			data:  valid[:1+hornet.HashSize+2],
+			check: func(err error) bool { return ierrors.Is(err, io.ErrUnexpectedEOF) },
+		},
+		{
+			name:  "truncated ledger entry",
+			data:  valid[:len(valid)-hornet.HashSize-4],
+			check: func(err error) bool { return ierrors.Is(err, io.ErrUnexpectedEOF) },
+		},
+		{
+			name:  "missing spent address",
+			data:  valid[:len(valid)-hornet.HashSize],
+			check: func(err error) bool { return ierrors.Is(err, io.EOF) },
+		},
+		{
+			name:  "total supply mismatch",
+			data:  invalidSupply,
+			check: func(err error) bool { return ierrors.Is(err, ErrCorrupted) },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := importTestLegacySnapshot(t, test.data)
+			if err == nil || !test.check(err) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
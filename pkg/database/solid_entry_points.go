@@ -3,6 +3,7 @@ package database
 import (
 	"bytes"
 	"encoding/binary"
+	"sort"
 
 	"github.com/iotaledger/hive.go/ierrors"
 	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
@@ -13,7 +14,8 @@ type SolidEntryPoints struct {
 	entryPointsMap map[string]milestone.Index
 }
 
-func newSolidEntryPoints() *SolidEntryPoints {
+// NewSolidEntryPoints creates an empty set of solid entry points.
+func NewSolidEntryPoints() *SolidEntryPoints {
 	return &SolidEntryPoints{
 		entryPointsMap: make(map[string]milestone.Index),
 	}
@@ -31,8 +33,27 @@ func (s *SolidEntryPoints) contains(txHash hornet.Hash) bool {
 	return exists
 }
 
+// Bytes returns the binary representation of the solid entry points as it is stored in the database.
+// The entries are sorted by their hash to get a deterministic result.
+func (s *SolidEntryPoints) Bytes() []byte {
+	txHashes := make([]string, 0, len(s.entryPointsMap))
+	for txHash := range s.entryPointsMap {
+		txHashes = append(txHashes, txHash)
+	}
+	sort.Strings(txHashes)
+
+	buf := bytes.NewBuffer(make([]byte, 0, len(txHashes)*(hornet.HashSize+milestone.IndexByteSize)))
+	for _, txHash := range txHashes {
+		// writing to a bytes.Buffer never fails
+		_ = binary.Write(buf, binary.BigEndian, []byte(txHash))
+		_ = binary.Write(buf, binary.BigEndian, s.entryPointsMap[txHash])
+	}
+
+	return buf.Bytes()
+}
+
 func solidEntryPointsFromBytes(solidEntryPointsBytes []byte) (*SolidEntryPoints, error) {
-	s := newSolidEntryPoints()
+	s := NewSolidEntryPoints()
 
 	bytesReader := bytes.NewReader(solidEntryPointsBytes)
 
@@ -1,10 +1,89 @@
 package database
 
 import (
-	"github.com/iotaledger/hive.go/lo"
+	"context"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/runtime/contextutils"
+	"github.com/iotaledger/inx-api-core-v0/pkg/bloom"
 	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
 )
 
-func (db *Database) WasAddressSpentFrom(address hornet.Hash) bool {
-	return lo.PanicOnErr(db.spentAddressesStore.Has(address[:hornet.HashSize]))
+const (
+	// SpentAddressesFilterElementEncoding describes the elements of the spent addresses filter:
+	// the ASCII bytes of the 81 address trytes without checksum.
+	SpentAddressesFilterElementEncoding = "ascii-address-trytes-without-checksum"
+)
+
+// SpentAddressConsumer is a function that consumes a spent address.
+// Returning an error aborts the iteration.
+type SpentAddressConsumer func(address hornet.Hash) error
+
+// WasAddressSpentFrom returns if the given address was spent from.
+func (db *Database) WasAddressSpentFrom(address hornet.Hash) (bool, error) {
+	spent, err := db.spentAddressesStore.Has(address[:hornet.HashSize])
+	if err != nil {
+		return false, ierrors.Wrapf(err, "failed to check spent address %s", address.Trytes())
+	}
+
+	return spent, nil
+}
+
+// ForEachSpentAddress iterates over all spent addresses, sorted by their binary representation.
+func (db *Database) ForEachSpentAddress(ctx context.Context, consumer SpentAddressConsumer) error {
+
+	var innerErr error
+	if err := db.spentAddressesStore.IterateKeys(kvstore.EmptyPrefix, func(key kvstore.Key) bool {
+		// check if the context was already canceled
+		if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
+			innerErr = err
+			return false
+		}
+
+		if len(key) != hornet.HashSize {
+			innerErr = ierrors.Wrapf(ErrCorrupted, "invalid spent address length: %d", len(key))
+			return false
+		}
+
+		if err := consumer(hornet.Hash(key)); err != nil {
+			innerErr = err
+			return false
+		}
+
+		return true
+	}); err != nil {
+		return ierrors.Wrap(err, "failed to iterate over all spent addresses")
+	}
+
+	return innerErr
+}
+
+// SpentAddressesFilter builds a Bloom filter over all spent addresses with the given false positive rate.
+// The elements of the filter are encoded as described by SpentAddressesFilterElementEncoding.
+func (db *Database) SpentAddressesFilter(ctx context.Context, falsePositiveRate float64) (*bloom.Filter, error) {
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		return nil, ierrors.Errorf("invalid false positive rate: %f", falsePositiveRate)
+	}
+
+	// the filter is sized for the amount of spent addresses, so they are counted first.
+	var spentAddressesCount uint64
+	if err := db.ForEachSpentAddress(ctx, func(_ hornet.Hash) error {
+		spentAddressesCount++
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	filter := bloom.NewWithEstimates(spentAddressesCount, falsePositiveRate)
+	if err := db.ForEachSpentAddress(ctx, func(address hornet.Hash) error {
+		filter.Add([]byte(address.Trytes()))
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
 }
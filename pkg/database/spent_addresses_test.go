@@ -0,0 +1,45 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/iotaledger/inx-api-core-v0/pkg/bloom"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+)
+
+func TestSpentAddressesFilter(t *testing.T) {
+	db, _ := newTestDatabase(t)
+
+	filter, err := db.SpentAddressesFilter(context.Background(), 0.000001)
+	if err != nil {
+		t.Fatalf("failed to build spent addresses filter: %v", err)
+	}
+
+	if filter.ElementsCount() != 1 {
+		t.Fatalf("expected 1 spent address in the filter, got %d", filter.ElementsCount())
+	}
+
+	// the elements are the 81 address trytes without checksum
+	expected := bloom.NewWithEstimates(1, 0.000001)
+	expected.Add([]byte(testAddressA))
+
+	if !bytes.Equal(filter.Bytes(), expected.Bytes()) {
+		t.Fatalf("unexpected filter bits: %x != %x", filter.Bytes(), expected.Bytes())
+	}
+
+	if !filter.Test([]byte(testAddressA)) {
+		t.Fatal("expected the spent address to be in the filter")
+	}
+
+	if filter.Test([]byte(testAddressA+"999999999")) || filter.Test(hornet.HashFromAddressTrytes(testAddressA)) {
+		t.Fatal("expected the address with a checksum and the binary address not to be in the filter")
+	}
+
+	for _, falsePositiveRate := range []float64{0, 1, -0.5} {
+		if _, err := db.SpentAddressesFilter(context.Background(), falsePositiveRate); err == nil {
+			t.Fatalf("expected an error for the false positive rate %f", falsePositiveRate)
+		}
+	}
+}
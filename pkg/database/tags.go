@@ -1,7 +1,11 @@
 package database
 
 import (
-	"github.com/iotaledger/hive.go/lo"
+	"bytes"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/inx-api-core-v0/pkg/compressed"
 	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
 )
 
@@ -23,7 +27,36 @@ func (db *Database) TagHashes(txTag hornet.Hash, maxFind ...int) hornet.Hashes {
 	return tagHashes
 }
 
-// ContainsTag returns if the given tag exists in the cache/persistence layer.
-func (db *Database) ContainsTag(txTag hornet.Hash, txHash hornet.Hash) bool {
-	return lo.PanicOnErr(db.tagsStore.Has(append(txTag, txHash...)))
+// ContainsTag returns if the given transaction has the given tag.
+func (db *Database) ContainsTag(txTag hornet.Hash, txHash hornet.Hash) (bool, error) {
+	contains, err := db.tagsStore.Has(concatBytes(txTag, txHash))
+	if err != nil {
+		return false, ierrors.Wrapf(err, "failed to check tag of transaction %s", txHash.Trytes())
+	}
+
+	return contains, nil
+}
+
+// ContainsTagPrefix returns if the tag of the given transaction starts with the given binary tag prefix.
+// Only the tag bytes of the stored transaction are decoded, the transaction itself is not parsed.
+func (db *Database) ContainsTagPrefix(tagPrefix hornet.Hash, txHash hornet.Hash) (bool, error) {
+	if len(tagPrefix) == hornet.TagSize {
+		return db.ContainsTag(tagPrefix, txHash)
+	}
+
+	data, err := db.txStore.Get(txHash)
+	if err != nil {
+		if ierrors.Is(err, kvstore.ErrKeyNotFound) {
+			return false, nil
+		}
+
+		return false, ierrors.Wrapf(err, "failed to get transaction %s", txHash.Trytes())
+	}
+
+	tag, err := compressed.TagFromCompressedBytes(data)
+	if err != nil {
+		return false, ierrors.Wrapf(ErrCorrupted, "failed to decode tag of transaction %s: %s", txHash.Trytes(), err)
+	}
+
+	return bytes.HasPrefix(tag, tagPrefix), nil
 }
@@ -1,10 +1,12 @@
 package database
 
 import (
+	"context"
 	"sync"
 
 	"github.com/iotaledger/hive.go/ierrors"
 	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/runtime/contextutils"
 	"github.com/iotaledger/inx-api-core-v0/pkg/compressed"
 	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
 	"github.com/iotaledger/iota.go/transaction"
@@ -90,7 +92,7 @@ func (tx *Transaction) Unmarshal(data []byte) error {
 
 	transaction, err := compressed.TransactionFromCompressedBytes(data, transactionHash)
 	if err != nil {
-		panic(err)
+		return ierrors.Wrapf(ErrCorrupted, "failed to decode transaction: %s", err)
 	}
 	tx.Tx = transaction
 
@@ -119,22 +121,65 @@ func metadataFactory(key []byte, data []byte) (*TransactionMetadata, error) {
 	return txMeta, nil
 }
 
-func (db *Database) TransactionOrNil(txHash hornet.Hash) *Transaction {
-	key := txHash
+// TransactionOrNil returns the transaction with the given hash or nil if it doesn't exist.
+func (db *Database) TransactionOrNil(txHash hornet.Hash) (*Transaction, error) {
+	return db.caches.transactions.Get(string(txHash), func() (*Transaction, error) {
+		key := txHash
 
-	data, err := db.txStore.Get(key)
-	if err != nil {
-		if !ierrors.Is(err, kvstore.ErrKeyNotFound) {
-			panic(ierrors.Errorf("failed to get value from database: %w", err))
+		data, err := db.txStore.Get(key)
+		if err != nil {
+			if !ierrors.Is(err, kvstore.ErrKeyNotFound) {
+				return nil, ierrors.Wrap(err, "failed to get value from database")
+			}
+
+			return nil, nil
 		}
 
-		return nil
-	}
+		tx, err := transactionFactory(key, data)
+		if err != nil {
+			return nil, ierrors.Wrapf(err, "transaction %s", txHash.Trytes())
+		}
 
-	tx, err := transactionFactory(key, data)
-	if err != nil {
-		panic(err)
+		return tx, nil
+	})
+}
+
+// TransactionConsumer is a function that consumes the compressed bytes and the metadata of a transaction.
+// Returning an error stops the iteration.
+type TransactionConsumer func(txHash hornet.Hash, txBytes []byte, txMeta *TransactionMetadata) error
+
+// ForEachTransaction passes all transactions together with their metadata to the consumer, sorted by the transaction hash.
+// The passed bytes are only valid during the call of the consumer.
+func (db *Database) ForEachTransaction(ctx context.Context, consumer TransactionConsumer) error {
+
+	var innerErr error
+	if err := db.txStore.Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		if err := contextutils.ReturnErrIfCtxDone(ctx, ErrOperationAborted); err != nil {
+			innerErr = err
+			return false
+		}
+
+		txHash := hornet.Hash(key[:hornet.HashSize])
+
+		txMeta, err := db.TxMetadataOrNil(txHash)
+		if err != nil {
+			innerErr = err
+			return false
+		}
+		if txMeta == nil {
+			innerErr = ierrors.Wrapf(ErrCorrupted, "metadata not found for transaction: %s", txHash.Trytes())
+			return false
+		}
+
+		if err := consumer(txHash, value, txMeta); err != nil {
+			innerErr = err
+			return false
+		}
+
+		return true
+	}); err != nil {
+		return err
 	}
 
-	return tx
+	return innerErr
 }
@@ -67,6 +67,14 @@ func (m *TransactionMetadata) BundleHash() hornet.Hash {
 	return m.bundleHash
 }
 
+func (m *TransactionMetadata) IsHead() bool {
+	return m.metadata.HasBit(TransactionMetadataIsHead)
+}
+
+func (m *TransactionMetadata) IsValue() bool {
+	return m.metadata.HasBit(TransactionMetadataIsValue)
+}
+
 func (m *TransactionMetadata) IsTail() bool {
 	return m.metadata.HasBit(TransactionMetadataIsTail)
 }
@@ -129,6 +137,10 @@ func (m *TransactionMetadata) Unmarshal(data []byte) error {
 		4 bytes  uint32 	milestoneIndex
 	*/
 
+	if len(data) != TransactionMetadataSize {
+		return ierrors.Wrapf(ErrCorrupted, "invalid transaction metadata length: %d != %d", len(data), TransactionMetadataSize)
+	}
+
 	hashSize := hornet.HashSize
 	msIndexSize := milestone.IndexByteSize
 
@@ -142,22 +154,25 @@ func (m *TransactionMetadata) Unmarshal(data []byte) error {
 	return nil
 }
 
-func (db *Database) TxMetadataOrNil(txHash hornet.Hash) *TransactionMetadata {
-	key := txHash
+// TxMetadataOrNil returns the metadata of the transaction with the given hash or nil if it doesn't exist.
+func (db *Database) TxMetadataOrNil(txHash hornet.Hash) (*TransactionMetadata, error) {
+	return db.caches.transactionMetadata.Get(string(txHash), func() (*TransactionMetadata, error) {
+		key := txHash
 
-	data, err := db.metadataStore.Get(key)
-	if err != nil {
-		if !ierrors.Is(err, kvstore.ErrKeyNotFound) {
-			panic(ierrors.Errorf("failed to get value from database: %w", err))
-		}
+		data, err := db.metadataStore.Get(key)
+		if err != nil {
+			if !ierrors.Is(err, kvstore.ErrKeyNotFound) {
+				return nil, ierrors.Wrap(err, "failed to get value from database")
+			}
 
-		return nil
-	}
+			return nil, nil
+		}
 
-	txMeta, err := metadataFactory(key, data)
-	if err != nil {
-		panic(err)
-	}
+		txMeta, err := metadataFactory(key, data)
+		if err != nil {
+			return nil, ierrors.Wrapf(err, "transaction metadata %s", txHash.Trytes())
+		}
 
-	return txMeta
+		return txMeta, nil
+	})
 }
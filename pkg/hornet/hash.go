@@ -2,6 +2,7 @@ package hornet
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/iotaledger/iota.go/consts"
 	"github.com/iotaledger/iota.go/encoding/t5b1"
@@ -18,6 +19,11 @@ const (
 	hashTrytesSize    = consts.HashTrytesSize
 	tagTrytesSize     = consts.TagTrinarySize / consts.TritsPerTryte
 	HashSize          = 49
+	// TagSize is the size of the binary representation of a tag.
+	TagSize = 17
+
+	// the amount of trits that are encoded in a single byte in t5b1 encoding.
+	tritsPerByte = 5
 )
 
 // Hash is the binary representation of a trinary Hash.
@@ -53,6 +59,65 @@ func HashFromTagTrytes(trytes trinary.Trytes) Hash {
 	return t5b1.EncodeTrytes(trytes)
 }
 
+// TagPrefixesFromTrytes returns the binary prefixes of all tags that start with the given tag trytes prefix.
+// 5 trits are encoded in one byte (t5b1), so a prefix that doesn't end at a byte boundary only determines
+// the lowest trits of the last byte. All possible values of that byte are returned as separate prefixes,
+// sorted by their binary representation, so they can be used for prefix scans in the tags index.
+// It panics when the prefix is longer than a tag.
+func TagPrefixesFromTrytes(prefix trinary.Trytes) Hashes {
+	if len(prefix) > tagTrytesSize {
+		panic("invalid tag prefix length")
+	}
+
+	trits := trinary.MustTrytesToTrits(prefix)
+
+	fullBytes := len(trits) / tritsPerByte
+	knownTrits := trits[fullBytes*tritsPerByte:]
+
+	fullBytesPrefix := Hash(t5b1.EncodeTrits(trits[:fullBytes*tritsPerByte]))
+	if len(knownTrits) == 0 {
+		return Hashes{fullBytesPrefix}
+	}
+
+	// the last byte of a tag contains padding trits, which are always zero.
+	tritsInByte := consts.TagTrinarySize - fullBytes*tritsPerByte
+	if tritsInByte > tritsPerByte {
+		tritsInByte = tritsPerByte
+	}
+	unknownTrits := tritsInByte - len(knownTrits)
+
+	combinations := 1
+	for i := 0; i < unknownTrits; i++ {
+		combinations *= 3
+	}
+
+	lastBytes := make([]byte, 0, combinations)
+	for combination := 0; combination < combinations; combination++ {
+		group := make(trinary.Trits, tritsPerByte)
+		copy(group, knownTrits)
+
+		// set the unknown trits to the balanced ternary digits of the combination
+		value := combination
+		for i := len(knownTrits); i < tritsInByte; i++ {
+			group[i] = int8(value%3) - 1
+			value /= 3
+		}
+
+		lastBytes = append(lastBytes, t5b1.EncodeTrits(group)[0])
+	}
+	sort.Slice(lastBytes, func(i, j int) bool { return lastBytes[i] < lastBytes[j] })
+
+	prefixes := make(Hashes, 0, len(lastBytes))
+	for _, lastByte := range lastBytes {
+		tagPrefix := make(Hash, 0, fullBytes+1)
+		tagPrefix = append(tagPrefix, fullBytesPrefix...)
+		tagPrefix = append(tagPrefix, lastByte)
+		prefixes = append(prefixes, tagPrefix)
+	}
+
+	return prefixes
+}
+
 // Trytes converts the binary Hash to its tryte representation.
 // It panics when the binary encoding is invalid.
 func (h Hash) Trytes() trinary.Trytes {
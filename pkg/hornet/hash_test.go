@@ -0,0 +1,69 @@
+package hornet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/iotaledger/iota.go/trinary"
+)
+
+func TestTagPrefixesFromTrytes(t *testing.T) {
+	tests := []struct {
+		name             string
+		prefix           trinary.Trytes
+		expectedPrefixes int
+		expectedLength   int
+	}{
+		{name: "empty prefix", prefix: "", expectedPrefixes: 1, expectedLength: 0},
+		{name: "1 tryte, 2 unknown trits", prefix: "A", expectedPrefixes: 9, expectedLength: 1},
+		{name: "2 trytes, 4 unknown trits", prefix: "AB", expectedPrefixes: 81, expectedLength: 2},
+		{name: "4 trytes, 3 unknown trits", prefix: "ABCD", expectedPrefixes: 27, expectedLength: 3},
+		{name: "5 trytes, byte boundary", prefix: "ABCDE", expectedPrefixes: 1, expectedLength: 3},
+		{name: "26 trytes, 2 unknown trits", prefix: strings.Repeat("Z", 26), expectedPrefixes: 9, expectedLength: 16},
+		{name: "full tag, padding trits", prefix: strings.Repeat("9", 26) + "Z", expectedPrefixes: 1, expectedLength: 17},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			prefixes := TagPrefixesFromTrytes(test.prefix)
+			if len(prefixes) != test.expectedPrefixes {
+				t.Fatalf("expected %d prefixes, got %d", test.expectedPrefixes, len(prefixes))
+			}
+
+			for i, prefix := range prefixes {
+				if len(prefix) != test.expectedLength {
+					t.Fatalf("expected prefix length %d, got %d", test.expectedLength, len(prefix))
+				}
+				if i > 0 && bytes.Compare(prefixes[i-1], prefix) >= 0 {
+					t.Fatalf("prefixes are not sorted and unique at index %d", i)
+				}
+			}
+
+			// every tag that starts with the trytes prefix must match exactly one of the binary prefixes
+			for _, suffixTryte := range []string{"9", "A", "M", "N", "Z"} {
+				tag := test.prefix + trinary.Trytes(strings.Repeat(suffixTryte, tagTrytesSize-len(test.prefix)))
+
+				var matches int
+				for _, prefix := range prefixes {
+					if bytes.HasPrefix(HashFromTagTrytes(tag), prefix) {
+						matches++
+					}
+				}
+				if matches != 1 {
+					t.Fatalf("tag %s matched %d prefixes", tag, matches)
+				}
+			}
+		})
+	}
+}
+
+func TestTagPrefixesFromTrytesTooLong(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic for a prefix that is longer than a tag")
+		}
+	}()
+
+	TagPrefixesFromTrytes(trinary.Trytes(strings.Repeat("A", tagTrytesSize+1)))
+}
@@ -8,8 +8,10 @@ import (
 	"github.com/iotaledger/hive.go/ierrors"
 	"github.com/iotaledger/inx-app/pkg/httpserver"
 	"github.com/iotaledger/iota.go/address"
+	"github.com/iotaledger/iota.go/trinary"
 
 	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
 )
 
 func (s *DatabaseServer) rpcGetBalances(c echo.Context) (interface{}, error) {
@@ -29,24 +31,50 @@ func (s *DatabaseServer) rpcGetBalances(c echo.Context) (interface{}, error) {
 		}
 	}
 
+	if err := s.checkLedgerIndex(request.LedgerIndex); err != nil {
+		return nil, err
+	}
+
 	result := &GetBalancesResponse{}
 
 	for _, addr := range request.Addresses {
 
-		balance, _, err := s.Database.BalanceForAddress(hornet.HashFromAddressTrytes(addr[:81]))
+		balance, err := s.balanceForAddress(c, hornet.HashFromAddressTrytes(addr[:81]), request.LedgerIndex)
 		if err != nil {
-			return nil, ierrors.Wrap(echo.ErrInternalServerError, err.Error())
+			return nil, err
 		}
 
 		// Address balance
 		result.Balances = append(result.Balances, strconv.FormatUint(balance, 10))
 	}
 
-	latestSolidMilestoneBundle := s.Database.LatestSolidMilestoneBundle()
+	if request.LedgerIndex != 0 {
+		ms, err := s.Database.MilestoneOrNil(request.LedgerIndex)
+		if err != nil {
+			return nil, databaseError(err)
+		}
+		if ms == nil {
+			return nil, ierrors.Wrapf(echo.ErrInternalServerError, "milestone not found: %d", request.LedgerIndex)
+		}
+
+		// The index of the requested milestone
+		result.MilestoneIndex = ms.Index
+		result.References = []string{ms.Hash.Trytes()}
+
+		return result, nil
+	}
+
+	latestSolidMilestone, err := s.Database.MilestoneOrNil(s.Database.LedgerIndex())
+	if err != nil {
+		return nil, databaseError(err)
+	}
+	if latestSolidMilestone == nil {
+		return nil, ierrors.Wrapf(echo.ErrInternalServerError, "milestone not found: %d", s.Database.LedgerIndex())
+	}
 
 	// The index of the milestone that confirmed the most recent balance
-	result.MilestoneIndex = latestSolidMilestoneBundle.MilestoneIndex()
-	result.References = []string{latestSolidMilestoneBundle.MilestoneHash().Trytes()}
+	result.MilestoneIndex = latestSolidMilestone.Index
+	result.References = []string{latestSolidMilestone.Hash.Trytes()}
 
 	return result, nil
 }
@@ -57,14 +85,175 @@ func (s *DatabaseServer) addressBalance(c echo.Context) (interface{}, error) {
 		return nil, err
 	}
 
-	balance, _, err := s.Database.BalanceForAddress(addr)
+	ledgerIndex, err := parseMilestoneIndexQueryParam(c, QueryParameterLedgerIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkLedgerIndex(ledgerIndex); err != nil {
+		return nil, err
+	}
+
+	balance, err := s.balanceForAddress(c, addr, ledgerIndex)
 	if err != nil {
-		return nil, ierrors.Wrap(echo.ErrInternalServerError, err.Error())
+		return nil, err
+	}
+
+	if ledgerIndex == 0 {
+		ledgerIndex = s.Database.LedgerIndex()
 	}
 
 	return &balanceResponse{
 		Address:     addr.Trytes(),
 		Balance:     strconv.FormatUint(balance, 10),
-		LedgerIndex: s.Database.LedgerIndex(),
+		LedgerIndex: ledgerIndex,
+	}, nil
+}
+
+// checkLedgerIndex checks if the balances can be calculated for the given ledger index.
+// A ledger index of 0 refers to the latest ledger index.
+func (s *DatabaseServer) checkLedgerIndex(ledgerIndex milestone.Index) error {
+	if ledgerIndex == 0 {
+		return nil
+	}
+
+	smi := s.Database.SolidMilestoneIndex()
+	if ledgerIndex > smi {
+		return ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid ledger index: %d, lsmi is %d", ledgerIndex, smi)
+	}
+
+	pruningIndex := s.Database.PruningIndex()
+	if ledgerIndex <= pruningIndex {
+		return ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid ledger index: %d, pruning index is %d", ledgerIndex, pruningIndex)
+	}
+
+	return nil
+}
+
+// balanceForAddress returns the balance of the address at the given ledger index.
+// A ledger index of 0 refers to the latest ledger index.
+func (s *DatabaseServer) balanceForAddress(c echo.Context, addr hornet.Hash, ledgerIndex milestone.Index) (uint64, error) {
+	if ledgerIndex == 0 {
+		balance, _, err := s.Database.BalanceForAddress(addr)
+		if err != nil {
+			return 0, databaseError(err)
+		}
+
+		return balance, nil
+	}
+
+	balance, _, err := s.Database.BalanceForAddressAtMilestone(c.Request().Context(), addr, ledgerIndex)
+	if err != nil {
+		return 0, databaseError(err)
+	}
+
+	return balance, nil
+}
+
+func (s *DatabaseServer) addressHistory(c echo.Context) (interface{}, error) {
+	addr, err := parseAddressParam(c)
+	if err != nil {
+		return nil, err
+	}
+
+	startIndex, err := parseMilestoneIndexQueryParam(c, QueryParameterStartIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	endIndex, err := parseMilestoneIndexQueryParam(c, QueryParameterEndIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	maxResults, err := parseMaxResultsQueryParam(c, s.RestAPILimitsMaxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	if startIndex == 0 {
+		startIndex = s.Database.PruningIndex() + 1
+	}
+
+	if endIndex == 0 {
+		endIndex = s.Database.SolidMilestoneIndex()
+	}
+
+	if err := s.checkLedgerIndex(startIndex); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkLedgerIndex(endIndex); err != nil {
+		return nil, err
+	}
+
+	if startIndex > endIndex {
+		return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid milestone range: %s %d is bigger than %s %d", QueryParameterStartIndex, startIndex, QueryParameterEndIndex, endIndex)
+	}
+
+	// without the address milestones index, the ledger diff of every milestone in the range is looked up,
+	// so the amount of scanned milestone indexes is limited, not the amount of found balance changes.
+	scanStartIndex := startIndex
+	if !s.Database.HasAddressMilestonesIndex() && int(endIndex-startIndex) >= maxResults {
+		scanStartIndex = endIndex - milestone.Index(maxResults) + 1
+	}
+
+	changes, err := s.Database.AddressBalanceChanges(c.Request().Context(), addr, scanStartIndex, endIndex, maxResults)
+	if err != nil {
+		return nil, databaseError(err)
+	}
+
+	// the bundles that moved funds of the address are resolved from the cones of the milestones in this page only.
+	milestoneIndexes := make(map[milestone.Index]struct{}, len(changes))
+	for _, change := range changes {
+		milestoneIndexes[change.MilestoneIndex] = struct{}{}
+	}
+
+	tails, err := s.Database.ConfirmedValueBundleTails(c.Request().Context(), addr, milestoneIndexes)
+	if err != nil {
+		return nil, databaseError(err)
+	}
+
+	history := make([]*addressHistoryEntry, 0, len(changes))
+	for _, change := range changes {
+		timestamp, err := s.Database.MilestoneTimestamp(change.MilestoneIndex)
+		if err != nil {
+			return nil, databaseError(err)
+		}
+
+		tailTxHashes := make([]trinary.Hash, 0, len(tails[change.MilestoneIndex]))
+		for _, tailTxHash := range tails[change.MilestoneIndex] {
+			tailTxHashes = append(tailTxHashes, tailTxHash.Trytes())
+		}
+
+		history = append(history, &addressHistoryEntry{
+			MilestoneIndex:     change.MilestoneIndex,
+			MilestoneTimestamp: timestamp,
+			Change:             strconv.FormatInt(change.Change, 10),
+			Balance:            strconv.FormatUint(change.Balance, 10),
+			TailTxHashes:       tailTxHashes,
+		})
+	}
+
+	var nextEndIndex milestone.Index
+	switch {
+	case len(changes) == maxResults:
+		// there might be more balance changes in older milestones
+		if oldestIndex := changes[len(changes)-1].MilestoneIndex; oldestIndex > startIndex {
+			nextEndIndex = oldestIndex - 1
+		}
+
+	case scanStartIndex > startIndex:
+		// the older milestones of the range were not scanned yet
+		nextEndIndex = scanStartIndex - 1
+	}
+
+	return &addressHistoryResponse{
+		Address:      addr.Trytes(),
+		History:      history,
+		StartIndex:   startIndex,
+		EndIndex:     endIndex,
+		NextEndIndex: nextEndIndex,
+		LedgerIndex:  s.Database.LedgerIndex(),
 	}, nil
 }
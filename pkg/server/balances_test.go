@@ -0,0 +1,52 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+)
+
+func TestAddressHistoryScannedMilestones(t *testing.T) {
+	s, _ := newBundleTestServer(t)
+	s.RestAPILimitsMaxResults = 1
+
+	tests := []struct {
+		name         string
+		query        string
+		nextEndIndex milestone.Index
+	}{
+		{
+			// without the address milestones index, only one milestone is scanned per page
+			name:         "default range",
+			query:        "",
+			nextEndIndex: 1,
+		},
+		{
+			name:         "next page",
+			query:        QueryParameterEndIndex + "=1",
+			nextEndIndex: 0,
+		},
+		{
+			name:         "single milestone",
+			query:        QueryParameterStartIndex + "=2",
+			nextEndIndex: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := s.addressHistory(newBundleTestContext(ParameterAddress, testBundleAddressC, test.query))
+			if err != nil {
+				t.Fatalf("failed to get address history: %v", err)
+			}
+
+			history := resp.(*addressHistoryResponse)
+			if len(history.History) != 0 {
+				t.Fatalf("expected no balance changes, got %d", len(history.History))
+			}
+			if history.NextEndIndex != test.nextEndIndex {
+				t.Fatalf("expected next end index %d, got %d", test.nextEndIndex, history.NextEndIndex)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"encoding/base64"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+	"github.com/iotaledger/inx-app/pkg/httpserver"
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+)
+
+func newBundleTransactionREST(tx *transaction.Transaction) *bundleTransaction {
+	return &bundleTransaction{
+		TxHash:       tx.Hash,
+		Address:      tx.Address,
+		Index:        uint32(tx.CurrentIndex),
+		Value:        strconv.FormatInt(tx.Value, 10),
+		Tag:          tx.Tag,
+		Timestamp:    tx.Timestamp,
+		TrunkTxHash:  tx.TrunkTransaction,
+		BranchTxHash: tx.BranchTransaction,
+	}
+}
+
+func (s *DatabaseServer) bundle(c echo.Context) (interface{}, error) {
+	tailTxHash, err := parseTailTxHashParam(c)
+	if err != nil {
+		return nil, err
+	}
+
+	bndl, err := s.Database.BundleOrNil(tailTxHash)
+	if err != nil {
+		return nil, databaseError(err)
+	}
+	if bndl == nil {
+		return nil, ierrors.Wrapf(echo.ErrNotFound, "bundle not found: %s", tailTxHash.Trytes())
+	}
+
+	txs, err := bndl.TransactionsOrdered()
+	if err != nil {
+		return nil, databaseError(err)
+	}
+
+	transactions := make([]*bundleTransaction, 0, len(txs))
+	for _, tx := range txs {
+		transactions = append(transactions, newBundleTransactionREST(tx.Tx))
+	}
+
+	// the confirmation of a bundle is stored in the metadata of its transactions
+	var referencedByMilestoneIndex milestone.Index
+	var milestoneTimestampReferenced uint64
+	tailTxMeta, err := s.Database.TxMetadataOrNil(tailTxHash)
+	if err != nil {
+		return nil, databaseError(err)
+	}
+	if tailTxMeta != nil {
+		if confirmed, at := tailTxMeta.ConfirmedWithIndex(); confirmed {
+			referencedByMilestoneIndex = at
+
+			if milestoneTimestampReferenced, err = s.Database.MilestoneTimestamp(referencedByMilestoneIndex); err != nil {
+				return nil, databaseError(err)
+			}
+		}
+	}
+
+	var milestoneIndex milestone.Index
+	if bndl.IsMilestone() {
+		if milestoneIndex, err = bndl.MilestoneIndex(); err != nil {
+			return nil, databaseError(err)
+		}
+	}
+
+	ledgerChanges := make(map[trinary.Hash]string)
+	for address, change := range bndl.LedgerChanges() {
+		ledgerChanges[hornet.Hash(address).Trytes()] = strconv.FormatInt(change, 10)
+	}
+
+	return &bundleResponse{
+		Bundle:                       bndl.Hash().Trytes(),
+		TailTxHash:                   tailTxHash.Trytes(),
+		HeadTxHash:                   bndl.HeadHash().Trytes(),
+		LastIndex:                    bndl.LastIndex(),
+		Transactions:                 transactions,
+		IsSolid:                      bndl.IsSolid(),
+		IsValid:                      bndl.IsValid(),
+		IsConfirmed:                  bndl.IsConfirmed(),
+		IsConflicting:                bndl.IsConflicting(),
+		IsMilestone:                  bndl.IsMilestone(),
+		IsValueSpam:                  bndl.IsValueSpam(),
+		IsValidStrictSemantics:       bndl.IsValidStrictSemantics(),
+		IsInvalidPastCone:            bndl.IsInvalidPastCone(),
+		ReferencedByMilestoneIndex:   referencedByMilestoneIndex,
+		MilestoneTimestampReferenced: milestoneTimestampReferenced,
+		MilestoneIndex:               milestoneIndex,
+		LedgerChanges:                ledgerChanges,
+		LedgerIndex:                  s.Database.LedgerIndex(),
+	}, nil
+}
+
+func (s *DatabaseServer) bundleAttachments(c echo.Context) (interface{}, error) {
+	bundleHash, err := parseBundleHashParam(c)
+	if err != nil {
+		return nil, err
+	}
+
+	maxResults, err := parseMaxResultsQueryParam(c, s.RestAPILimitsMaxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	// the cursor is the hash of the last returned tail transaction
+	var cursor hornet.Hash
+	if value := c.QueryParam(QueryParameterCursor); len(value) > 0 {
+		cursorBytes, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil || len(cursorBytes) != hornet.HashSize {
+			return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid cursor provided: %s", value)
+		}
+		cursor = cursorBytes
+	}
+
+	tailTxHashes, moreResults, err := s.Database.BundleTailTransactionHashes(c.Request().Context(), bundleHash, cursor, maxResults)
+	if err != nil {
+		return nil, databaseError(err)
+	}
+	if len(tailTxHashes) == 0 && cursor == nil {
+		return nil, ierrors.Wrapf(echo.ErrNotFound, "bundle not found: %s", bundleHash.Trytes())
+	}
+
+	response := &bundleAttachmentsResponse{
+		Bundle:      bundleHash.Trytes(),
+		Attachments: make([]*bundleAttachment, 0, len(tailTxHashes)),
+		LedgerIndex: s.Database.LedgerIndex(),
+	}
+
+	if moreResults {
+		response.Cursor = base64.RawURLEncoding.EncodeToString(tailTxHashes[len(tailTxHashes)-1])
+	}
+
+	for _, tailTxHash := range tailTxHashes {
+		attachment := &bundleAttachment{
+			TailTxHash: tailTxHash.Trytes(),
+		}
+
+		// the bundle only exists if all transactions of the attachment are known
+		bndl, err := s.Database.BundleOrNil(tailTxHash)
+		if err != nil {
+			return nil, databaseError(err)
+		}
+		if bndl != nil {
+			attachment.IsSolid = bndl.IsSolid()
+			attachment.IsValid = bndl.IsValid()
+		}
+
+		tailTxMeta, err := s.Database.TxMetadataOrNil(tailTxHash)
+		if err != nil {
+			return nil, databaseError(err)
+		}
+		if tailTxMeta != nil {
+			confirmed, at := tailTxMeta.ConfirmedWithIndex()
+			attachment.IsConfirmed = confirmed
+			attachment.IsConflicting = tailTxMeta.IsConflicting()
+
+			if confirmed {
+				attachment.ReferencedByMilestoneIndex = at
+
+				if attachment.MilestoneTimestampReferenced, err = s.Database.MilestoneTimestamp(at); err != nil {
+					return nil, databaseError(err)
+				}
+			}
+		}
+
+		response.Attachments = append(response.Attachments, attachment)
+	}
+
+	// the confirmed attachment is resolved over all attachments, independent of the returned page
+	confirmedTailTxHash, referencedByMilestoneIndex, err := s.Database.FirstConfirmedBundleTailTransactionHash(c.Request().Context(), bundleHash)
+	if err != nil {
+		return nil, databaseError(err)
+	}
+	if confirmedTailTxHash != nil {
+		response.ConfirmedTailTxHash = confirmedTailTxHash.Trytes()
+		response.ReferencedByMilestoneIndex = referencedByMilestoneIndex
+	}
+
+	return response, nil
+}
@@ -0,0 +1,331 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+)
+
+var (
+	testBundleAddressA = trinary.Trytes(strings.Repeat("A", 81))
+	testBundleAddressB = trinary.Trytes(strings.Repeat("B", 81))
+	testBundleAddressC = trinary.Trytes(strings.Repeat("C", 81))
+	testValueBundle    = trinary.Hash(strings.Repeat("V", 81))
+)
+
+// bundleTestFixture contains the hashes of the objects written by newBundleTestServer.
+type bundleTestFixture struct {
+	milestoneTails map[milestone.Index]hornet.Hash
+	// the transactions of the value bundle ordered by their current index, confirmed by milestone 2.
+	valueBundleTxs hornet.Hashes
+	// the tail of an attachment of the value bundle that is not confirmed.
+	pendingTail hornet.Hash
+	// the tail of an attachment of the value bundle that was confirmed by milestone 1, but conflicts.
+	conflictingTail hornet.Hash
+	// the tail of a bundle that references a milestone which doesn't exist.
+	brokenTail hornet.Hash
+}
+
+// testBundleMilestoneTimestamp returns the timestamp of the milestone with the given index in the bundle test database.
+func testBundleMilestoneTimestamp(msIndex milestone.Index) uint64 {
+	return 1_600_000_000 + uint64(msIndex)*60
+}
+
+// testBundleTransaction returns a transaction of a bundle, the trunk is set by storeTestBundle.
+func testBundleTransaction(address trinary.Trytes, value int64, bundleHash trinary.Hash, currentIndex uint64, lastIndex uint64) *transaction.Transaction {
+	return &transaction.Transaction{
+		SignatureMessageFragment: strings.Repeat("9", 2187),
+		Address:                  address,
+		Value:                    value,
+		ObsoleteTag:              strings.Repeat("9", 27),
+		Timestamp:                1_600_000_000,
+		CurrentIndex:             currentIndex,
+		LastIndex:                lastIndex,
+		Bundle:                   bundleHash,
+		TrunkTransaction:         testNullHash,
+		BranchTransaction:        testNullHash,
+		Tag:                      strings.Repeat("9", 27),
+		Nonce:                    strings.Repeat("9", 27),
+	}
+}
+
+// storeTestBundle stores the transactions of a bundle from the head to the tail, so every transaction approves the next one.
+// The transactions are returned ordered by their current index.
+func storeTestBundle(t *testing.T, builder *database.Builder, txs []*transaction.Transaction, state database.TransactionState) hornet.Hashes {
+	t.Helper()
+
+	trunk := testNullHash
+	txHashes := make(hornet.Hashes, len(txs))
+	for i := len(txs) - 1; i >= 0; i-- {
+		txs[i].TrunkTransaction = trunk
+
+		txHash, err := builder.StoreTransaction(txs[i], state)
+		if err != nil {
+			t.Fatalf("failed to store transaction: %v", err)
+		}
+
+		txHashes[i] = txHash
+		trunk = txs[i].Hash
+	}
+
+	// the builder gets the head first, the order of the transactions must not matter
+	shuffled := append([]*transaction.Transaction{txs[len(txs)-1]}, txs[:len(txs)-1]...)
+	if _, err := builder.StoreBundle(shuffled, true); err != nil {
+		t.Fatalf("failed to store bundle: %v", err)
+	}
+
+	return txHashes
+}
+
+// testValueBundleTransactions returns the transactions of the value bundle that moves 10 tokens from address A to address B.
+// Different attachment timestamps result in different attachments of the same bundle.
+func testValueBundleTransactions(attachmentTimestamp int64) []*transaction.Transaction {
+	txs := []*transaction.Transaction{
+		testBundleTransaction(testBundleAddressA, -10, testValueBundle, 0, 2),
+		testBundleTransaction(testBundleAddressB, 10, testValueBundle, 1, 2),
+		testBundleTransaction(testBundleAddressC, 0, testValueBundle, 2, 2),
+	}
+
+	for _, tx := range txs {
+		tx.AttachmentTimestamp = attachmentTimestamp
+	}
+
+	return txs
+}
+
+// newBundleTestServer builds a legacy database in memory with two milestones and three attachments of a value bundle
+// that moves 10 tokens from address A to address B. One attachment is confirmed by milestone 2, one is pending
+// and one was confirmed by milestone 1, but conflicts.
+func newBundleTestServer(t *testing.T) (*DatabaseServer, *bundleTestFixture) {
+	t.Helper()
+
+	tangleStore := mapdb.NewMapDB()
+	snapshotStore := mapdb.NewMapDB()
+	spentStore := mapdb.NewMapDB()
+
+	builder, err := database.NewBuilder(tangleStore, snapshotStore, spentStore)
+	if err != nil {
+		t.Fatalf("failed to create builder: %v", err)
+	}
+
+	fixture := &bundleTestFixture{
+		milestoneTails: make(map[milestone.Index]hornet.Hash),
+	}
+
+	for msIndex := milestone.Index(1); msIndex <= 2; msIndex++ {
+		milestoneTx := testBundleTransaction(strings.Repeat("M", 81), 0, strings.Repeat(string(rune('M'+msIndex)), 81), 0, 0)
+		milestoneTx.ObsoleteTag = trinary.IntToTrytes(int64(msIndex), 27)
+		milestoneTx.Timestamp = testBundleMilestoneTimestamp(msIndex)
+
+		tailTxHash := storeTestBundle(t, builder, []*transaction.Transaction{milestoneTx}, database.TransactionState{ConfirmationIndex: msIndex, MilestoneIndex: msIndex})[0]
+		if err := builder.StoreMilestone(msIndex, tailTxHash); err != nil {
+			t.Fatalf("failed to store milestone: %v", err)
+		}
+		fixture.milestoneTails[msIndex] = tailTxHash
+	}
+
+	fixture.valueBundleTxs = storeTestBundle(t, builder, testValueBundleTransactions(0), database.TransactionState{ConfirmationIndex: 2})
+	fixture.pendingTail = storeTestBundle(t, builder, testValueBundleTransactions(1), database.TransactionState{})[0]
+	fixture.conflictingTail = storeTestBundle(t, builder, testValueBundleTransactions(2), database.TransactionState{ConfirmationIndex: 1, Conflicting: true})[0]
+
+	fixture.brokenTail = storeTestBundle(t, builder, []*transaction.Transaction{
+		testBundleTransaction(testBundleAddressC, 0, strings.Repeat("W", 81), 0, 0),
+	}, database.TransactionState{ConfirmationIndex: 5})[0]
+
+	solidEntryPoints := database.NewSolidEntryPoints()
+	solidEntryPoints.Add(hornet.HashFromHashTrytes(testNullHash), 0)
+
+	for _, step := range []func() error{
+		func() error { return builder.StoreLedgerIndex(2) },
+		func() error {
+			return builder.StoreSnapshotInfo(&database.SnapshotInfo{
+				CoordinatorAddress: hornet.HashFromAddressTrytes(strings.Repeat("M", 81)),
+				Hash:               fixture.milestoneTails[1],
+				SnapshotIndex:      1,
+				EntryPointIndex:    1,
+				PruningIndex:       0,
+				Timestamp:          int64(testBundleMilestoneTimestamp(1)),
+			})
+		},
+		func() error { return builder.StoreSolidEntryPoints(solidEntryPoints) },
+		builder.Flush,
+	} {
+		if err := step(); err != nil {
+			t.Fatalf("failed to build database: %v", err)
+		}
+	}
+
+	db, err := database.NewWithStores(context.Background(), nil, tangleStore, snapshotStore, spentStore, false, database.CacheSizes{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	return &DatabaseServer{
+		Database:                    db,
+		RestAPILimitsMaxResults:     10,
+		RestAPILimitsMaxScannedKeys: 0,
+		RPCEndpoints:                make(map[string]rpcEndpoint),
+	}, fixture
+}
+
+func newBundleTestContext(paramName string, paramValue string, query string) echo.Context {
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/?"+query, nil), httptest.NewRecorder())
+	c.SetParamNames(paramName)
+	c.SetParamValues(paramValue)
+
+	return c
+}
+
+func TestBundle(t *testing.T) {
+	s, fixture := newBundleTestServer(t)
+
+	resp, err := s.bundle(newBundleTestContext(ParameterTailTxHash, fixture.valueBundleTxs[0].Trytes(), ""))
+	if err != nil {
+		t.Fatalf("failed to get value bundle: %v", err)
+	}
+	bndl := resp.(*bundleResponse)
+
+	if bndl.Bundle != testValueBundle || bndl.TailTxHash != fixture.valueBundleTxs[0].Trytes() || bndl.HeadTxHash != fixture.valueBundleTxs[2].Trytes() || bndl.LastIndex != 2 {
+		t.Fatalf("unexpected bundle: %+v", bndl)
+	}
+
+	// the transactions are ordered by their current index
+	if len(bndl.Transactions) != len(fixture.valueBundleTxs) {
+		t.Fatalf("expected %d transactions, got %d", len(fixture.valueBundleTxs), len(bndl.Transactions))
+	}
+	for i, tx := range bndl.Transactions {
+		if tx.Index != uint32(i) || tx.TxHash != fixture.valueBundleTxs[i].Trytes() {
+			t.Fatalf("unexpected transaction at position %d: index %d, hash %s", i, tx.Index, tx.TxHash)
+		}
+	}
+
+	if !bndl.IsSolid || !bndl.IsValid || !bndl.IsConfirmed || bndl.IsConflicting || bndl.IsMilestone || bndl.IsValueSpam || !bndl.IsValidStrictSemantics || bndl.IsInvalidPastCone {
+		t.Fatalf("unexpected flags of the value bundle: %+v", bndl)
+	}
+
+	// the zero-value transaction doesn't change the ledger
+	if len(bndl.LedgerChanges) != 2 || bndl.LedgerChanges[testBundleAddressA] != "-10" || bndl.LedgerChanges[testBundleAddressB] != "10" {
+		t.Fatalf("unexpected ledger changes: %v", bndl.LedgerChanges)
+	}
+
+	if bndl.ReferencedByMilestoneIndex != 2 || bndl.MilestoneTimestampReferenced != testBundleMilestoneTimestamp(2) || bndl.MilestoneIndex != 0 {
+		t.Fatalf("unexpected confirmation: milestone %d, timestamp %d", bndl.ReferencedByMilestoneIndex, bndl.MilestoneTimestampReferenced)
+	}
+
+	// milestone bundles confirm themselves
+	resp, err = s.bundle(newBundleTestContext(ParameterTailTxHash, fixture.milestoneTails[1].Trytes(), ""))
+	if err != nil {
+		t.Fatalf("failed to get milestone bundle: %v", err)
+	}
+	bndl = resp.(*bundleResponse)
+
+	if !bndl.IsMilestone || bndl.MilestoneIndex != 1 || !bndl.IsValueSpam || len(bndl.LedgerChanges) != 0 ||
+		bndl.ReferencedByMilestoneIndex != 1 || bndl.MilestoneTimestampReferenced != testBundleMilestoneTimestamp(1) {
+		t.Fatalf("unexpected milestone bundle: %+v", bndl)
+	}
+}
+
+func TestBundleErrors(t *testing.T) {
+	s, fixture := newBundleTestServer(t)
+
+	if _, err := s.bundle(newBundleTestContext(ParameterTailTxHash, strings.Repeat("X", 81), "")); !ierrors.Is(err, echo.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for an unknown bundle, got %v", err)
+	}
+
+	if _, err := s.bundle(newBundleTestContext(ParameterTailTxHash, "INVALID", "")); err == nil {
+		t.Fatal("expected an error for an invalid tail transaction hash")
+	}
+
+	// the timestamp of the confirming milestone is not silently dropped
+	if _, err := s.bundle(newBundleTestContext(ParameterTailTxHash, fixture.brokenTail.Trytes(), "")); !ierrors.Is(err, echo.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for the missing confirming milestone, got %v", err)
+	}
+}
+
+func TestBundleAttachments(t *testing.T) {
+	s, fixture := newBundleTestServer(t)
+
+	confirmedTail := fixture.valueBundleTxs[0]
+
+	attachments := make(map[trinary.Hash]*bundleAttachment)
+	var cursor string
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatalf("cursor did not terminate")
+		}
+
+		resp, err := s.bundleAttachments(newBundleTestContext(ParameterBundleHash, testValueBundle, QueryParameterMaxResults+"=1&"+QueryParameterCursor+"="+cursor))
+		if err != nil {
+			t.Fatalf("failed to get bundle attachments: %v", err)
+		}
+		page := resp.(*bundleAttachmentsResponse)
+
+		// the confirmed attachment is reported on every page, the conflicting attachment is older, but doesn't count
+		if page.ConfirmedTailTxHash != confirmedTail.Trytes() || page.ReferencedByMilestoneIndex != 2 {
+			t.Fatalf("unexpected confirmed attachment on page %d: %s, milestone %d", pages, page.ConfirmedTailTxHash, page.ReferencedByMilestoneIndex)
+		}
+
+		for _, attachment := range page.Attachments {
+			attachments[attachment.TailTxHash] = attachment
+		}
+
+		if page.Cursor == "" {
+			break
+		}
+		cursor = page.Cursor
+	}
+
+	if len(attachments) != 3 {
+		t.Fatalf("expected 3 attachments, got %d", len(attachments))
+	}
+
+	tests := []struct {
+		tailTxHash     hornet.Hash
+		confirmed      bool
+		conflicting    bool
+		milestoneIndex milestone.Index
+	}{
+		{tailTxHash: confirmedTail, confirmed: true, conflicting: false, milestoneIndex: 2},
+		{tailTxHash: fixture.pendingTail, confirmed: false, conflicting: false, milestoneIndex: 0},
+		{tailTxHash: fixture.conflictingTail, confirmed: true, conflicting: true, milestoneIndex: 1},
+	}
+
+	for _, test := range tests {
+		attachment, exists := attachments[test.tailTxHash.Trytes()]
+		if !exists {
+			t.Fatalf("attachment %s not found", test.tailTxHash.Trytes())
+		}
+
+		var expectedTimestamp uint64
+		if test.confirmed {
+			expectedTimestamp = testBundleMilestoneTimestamp(test.milestoneIndex)
+		}
+
+		if !attachment.IsSolid || !attachment.IsValid || attachment.IsConfirmed != test.confirmed || attachment.IsConflicting != test.conflicting ||
+			attachment.ReferencedByMilestoneIndex != test.milestoneIndex || attachment.MilestoneTimestampReferenced != expectedTimestamp {
+			t.Fatalf("unexpected attachment %s: %+v", test.tailTxHash.Trytes(), attachment)
+		}
+	}
+
+	if _, err := s.bundleAttachments(newBundleTestContext(ParameterBundleHash, strings.Repeat("X", 81), "")); !ierrors.Is(err, echo.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for an unknown bundle, got %v", err)
+	}
+
+	// the timestamp of the confirming milestone is not silently dropped
+	if _, err := s.bundleAttachments(newBundleTestContext(ParameterBundleHash, strings.Repeat("W", 81), "")); !ierrors.Is(err, echo.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for the missing confirming milestone, got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-app/pkg/httpserver"
+
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+)
+
+const (
+	ConeDirectionPast   = "past"
+	ConeDirectionFuture = "future"
+)
+
+func (s *DatabaseServer) transactionApprovers(c echo.Context) (interface{}, error) {
+	txHash, err := parseTransactionHashParam(c)
+	if err != nil {
+		return nil, err
+	}
+
+	maxResults, err := parseMaxResultsQueryParam(c, s.RestAPILimitsMaxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	// the cursor is the hash of the last returned approver
+	var cursor hornet.Hash
+	if value := c.QueryParam(QueryParameterCursor); len(value) > 0 {
+		cursorBytes, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil || len(cursorBytes) != hornet.HashSize {
+			return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid cursor provided: %s", value)
+		}
+		cursor = cursorBytes
+	}
+
+	// unknown transactions are not found, even if other transactions approve them
+	txMeta, err := s.Database.TxMetadataOrNil(txHash)
+	if err != nil {
+		return nil, databaseError(err)
+	}
+	if txMeta == nil {
+		return nil, ierrors.Wrapf(echo.ErrNotFound, "transaction not found: %s", txHash.Trytes())
+	}
+
+	approverHashes, moreResults, err := s.Database.ApproverHashesAfter(c.Request().Context(), txHash, cursor, maxResults)
+	if err != nil {
+		return nil, databaseError(err)
+	}
+
+	approvers := make([]string, 0, len(approverHashes))
+	for _, approverHash := range approverHashes {
+		approvers = append(approvers, approverHash.Trytes())
+	}
+
+	response := &approversResponse{
+		TxHash:      txHash.Trytes(),
+		Approvers:   approvers,
+		LedgerIndex: s.Database.LedgerIndex(),
+	}
+
+	if moreResults {
+		response.Cursor = base64.RawURLEncoding.EncodeToString(approverHashes[len(approverHashes)-1])
+	}
+
+	return response, nil
+}
+
+func (s *DatabaseServer) transactionApprovees(c echo.Context) (interface{}, error) {
+	txHash, err := parseTransactionHashParam(c)
+	if err != nil {
+		return nil, err
+	}
+
+	txMeta, err := s.Database.TxMetadataOrNil(txHash)
+	if err != nil {
+		return nil, databaseError(err)
+	}
+	if txMeta == nil {
+		return nil, ierrors.Wrapf(echo.ErrNotFound, "transaction not found: %s", txHash.Trytes())
+	}
+
+	return &approveesResponse{
+		TxHash:       txHash.Trytes(),
+		TrunkTxHash:  txMeta.TrunkHash().Trytes(),
+		BranchTxHash: txMeta.BranchHash().Trytes(),
+		LedgerIndex:  s.Database.LedgerIndex(),
+	}, nil
+}
+
+func (s *DatabaseServer) transactionCone(c echo.Context) (interface{}, error) {
+	txHash, err := parseTransactionHashParam(c)
+	if err != nil {
+		return nil, err
+	}
+
+	directionParam := strings.ToLower(c.QueryParam(QueryParameterDirection))
+	if len(directionParam) == 0 {
+		directionParam = ConeDirectionPast
+	}
+
+	var direction database.ConeDirection
+	switch directionParam {
+	case ConeDirectionPast:
+		direction = database.ConeDirectionPast
+	case ConeDirectionFuture:
+		direction = database.ConeDirectionFuture
+	default:
+		return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid %s: %s, must be \"%s\" or \"%s\"", QueryParameterDirection, directionParam, ConeDirectionPast, ConeDirectionFuture)
+	}
+
+	var maxDepth int
+	if value := c.QueryParam(QueryParameterDepth); len(value) > 0 {
+		depth, err := strconv.ParseUint(value, 10, 31)
+		if err != nil {
+			return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid %s, error: %s", QueryParameterDepth, err)
+		}
+		maxDepth = int(depth)
+	}
+
+	maxNodes, err := parseLimitQueryParam(c, QueryParameterMax, s.RestAPILimitsMaxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	cone, err := s.Database.TraverseCone(c.Request().Context(), txHash, direction, maxDepth, maxNodes)
+	if err != nil {
+		return nil, databaseError(err)
+	}
+
+	nodes := make([]*coneNode, 0, len(cone.Nodes))
+	for _, node := range cone.Nodes {
+		n := &coneNode{
+			TxHash:          node.TxHash.Trytes(),
+			Depth:           node.Depth,
+			Known:           node.Metadata != nil,
+			SolidEntryPoint: node.IsSolidEntryPoint,
+		}
+
+		if node.Metadata != nil {
+			confirmed, at := node.Metadata.ConfirmedWithIndex()
+			n.Solid = node.Metadata.IsSolid()
+			n.Confirmed = confirmed
+			n.Conflicting = node.Metadata.IsConflicting()
+			if confirmed {
+				n.ReferencedByMilestoneIndex = at
+			}
+			if node.Metadata.IsMilestone() {
+				n.MilestoneIndex = node.Metadata.MilestoneIndex()
+			}
+		}
+
+		nodes = append(nodes, n)
+	}
+
+	edges := make([]*coneEdge, 0, len(cone.Edges))
+	for _, edge := range cone.Edges {
+		edges = append(edges, &coneEdge{
+			Approver: edge.Approver.Trytes(),
+			Approvee: edge.Approvee.Trytes(),
+			IsTrunk:  edge.IsTrunk,
+		})
+	}
+
+	return &coneResponse{
+		TxHash:      txHash.Trytes(),
+		Direction:   directionParam,
+		Nodes:       nodes,
+		Edges:       edges,
+		Truncated:   cone.Truncated,
+		LedgerIndex: s.Database.LedgerIndex(),
+	}, nil
+}
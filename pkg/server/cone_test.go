@@ -0,0 +1,53 @@
+package server
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-app/pkg/httpserver"
+)
+
+func TestTransactionApprovers(t *testing.T) {
+	s, fixture := newBundleTestServer(t)
+
+	// the head of the value bundle is only approved by the next transaction of the bundle
+	resp, err := s.transactionApprovers(newBundleTestContext(ParameterTransactionHash, fixture.valueBundleTxs[2].Trytes(), ""))
+	if err != nil {
+		t.Fatalf("failed to get approvers: %v", err)
+	}
+
+	approvers := resp.(*approversResponse)
+	if len(approvers.Approvers) != 1 || approvers.Approvers[0] != fixture.valueBundleTxs[1].Trytes() {
+		t.Fatalf("unexpected approvers: %v", approvers.Approvers)
+	}
+	if approvers.Cursor != "" {
+		t.Fatalf("expected no cursor, got %s", approvers.Cursor)
+	}
+
+	// the page ends after the cursor
+	cursor := base64.RawURLEncoding.EncodeToString(fixture.valueBundleTxs[1])
+	resp, err = s.transactionApprovers(newBundleTestContext(ParameterTransactionHash, fixture.valueBundleTxs[2].Trytes(), QueryParameterCursor+"="+cursor))
+	if err != nil {
+		t.Fatalf("failed to get approvers: %v", err)
+	}
+	if approvers := resp.(*approversResponse); len(approvers.Approvers) != 0 {
+		t.Fatalf("expected no approvers after the cursor, got %v", approvers.Approvers)
+	}
+}
+
+func TestTransactionApproversErrors(t *testing.T) {
+	s, fixture := newBundleTestServer(t)
+
+	// the null hash is approved by many transactions, but it is not a known transaction
+	if _, err := s.transactionApprovers(newBundleTestContext(ParameterTransactionHash, testNullHash, "")); !ierrors.Is(err, echo.ErrNotFound) {
+		t.Fatalf("expected not found for an unknown transaction, got: %v", err)
+	}
+
+	if _, err := s.transactionApprovers(newBundleTestContext(ParameterTransactionHash, fixture.valueBundleTxs[2].Trytes(), QueryParameterCursor+"="+strings.Repeat("A", 10))); !ierrors.Is(err, httpserver.ErrInvalidParameter) {
+		t.Fatalf("expected an invalid parameter for a malformed cursor, got: %v", err)
+	}
+}
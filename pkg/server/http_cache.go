@@ -0,0 +1,242 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
+)
+
+const (
+	headerETag         = "ETag"
+	headerIfNoneMatch  = "If-None-Match"
+	headerCacheControl = "Cache-Control"
+
+	// the request headers that select the representation of a response, they are part of the ETag.
+	varyHeaders = echo.HeaderAccept + ", " + echo.HeaderAcceptEncoding
+)
+
+var (
+	// immutableRoutes are the routes whose responses are bound to a milestone or a transaction,
+	// so they never change as long as the ledger index of the database doesn't change.
+	immutableRoutes = map[string]struct{}{
+		APIRoute + RouteMilestoneByIndex:          {},
+		APIRoute + RouteMilestoneTransactions:     {},
+		APIRoute + RouteMilestoneByHash:           {},
+		APIRoute + RouteTransaction:               {},
+		APIRoute + RouteTransactionTrytes:         {},
+		APIRoute + RouteTransactionMetadata:       {},
+		APIRoute + RouteTransactionApprovers:      {},
+		APIRoute + RouteTransactionApprovees:      {},
+		APIRoute + RouteTransactionCone:           {},
+		APIRoute + RouteBundle:                    {},
+		APIRoute + RouteBundleAttachments:         {},
+		APIRoute + RouteLedgerStateByIndex:        {},
+		APIRoute + RouteLedgerDiffByIndex:         {},
+		APIRoute + RouteLedgerDiffExtendedByIndex: {},
+	}
+)
+
+// HTTPCacheConfig defines the caching headers of the API responses.
+type HTTPCacheConfig struct {
+	// MaxAge is the time that responses which depend on the latest ledger state may be cached.
+	MaxAge time.Duration
+	// ImmutableMaxAge is the time that milestone-bound responses may be cached.
+	ImmutableMaxAge time.Duration
+}
+
+// isImmutableRequest checks if the response of the request is bound to a milestone.
+func isImmutableRequest(c echo.Context) bool {
+	if _, exists := immutableRoutes[c.Path()]; exists {
+		return true
+	}
+
+	// all other routes are milestone-bound if the ledger index is given explicitly
+	return c.QueryParam(QueryParameterLedgerIndex) != ""
+}
+
+// computeETag derives a strong ETag from the ledger index, the route, the parameters and the requested representation.
+// The method is not part of the ETag, so GET and HEAD requests share the same validator.
+func computeETag(ledgerIndex uint32, c echo.Context) string {
+	req := c.Request()
+
+	h := sha256.New()
+	_ = binary.Write(h, binary.LittleEndian, ledgerIndex)
+	h.Write([]byte(req.URL.Path))
+	h.Write([]byte{0})
+	h.Write([]byte(sortedQuery(req.URL.Query())))
+	h.Write([]byte{0})
+	// the same resource has different representations depending on the content negotiation
+	h.Write([]byte(req.Header.Get(echo.HeaderAccept)))
+	h.Write([]byte{0})
+	h.Write([]byte(req.Header.Get(echo.HeaderAcceptEncoding)))
+
+	return fmt.Sprintf(`"%s"`, hex.EncodeToString(h.Sum(nil)[:16]))
+}
+
+// sortedQuery returns the query parameters in a canonical order.
+func sortedQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, key := range keys {
+		paramValues := values[key]
+		sort.Strings(paramValues)
+
+		for _, value := range paramValues {
+			sb.WriteString(url.QueryEscape(key))
+			sb.WriteByte('=')
+			sb.WriteString(url.QueryEscape(value))
+			sb.WriteByte('&')
+		}
+	}
+
+	return sb.String()
+}
+
+// etagMatches checks if the ETag is contained in the value of the "If-None-Match" header.
+// The wildcard "*" is not considered here, it matches any current representation of the resource.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimSpace(candidate) == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isWildcardMatch checks if the value of the "If-None-Match" header is the wildcard "*".
+func isWildcardMatch(ifNoneMatch string) bool {
+	return strings.TrimSpace(ifNoneMatch) == "*"
+}
+
+// isStreamedResponse checks if the response is a newline-delimited JSON stream.
+// The status code of a stream is sent before the first record, so a stream that fails later on
+// would still look like a successful response.
+func isStreamedResponse(res *echo.Response) bool {
+	return strings.HasPrefix(res.Header().Get(echo.HeaderContentType), MIMEApplicationNDJSON)
+}
+
+// notModifiedWriter discards the body of a response that was replaced by "304 Not Modified".
+type notModifiedWriter struct {
+	http.ResponseWriter
+	notModified bool
+}
+
+func (w *notModifiedWriter) Write(b []byte) (int, error) {
+	if w.notModified {
+		return len(b), nil
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush flushes streamed responses.
+func (w *notModifiedWriter) Flush() {
+	if w.notModified {
+		return
+	}
+
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the original writer for the http.ResponseController.
+func (w *notModifiedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// HTTPCacheMiddleware adds strong ETags and Cache-Control headers to successful GET responses of the API
+// and answers requests with a matching "If-None-Match" header with "304 Not Modified".
+// The content of the database never changes for a given ledger index,
+// so the ETag can be derived from the request without rendering the response.
+// ETags are only handed out for successful responses, so a request with a matching ETag is answered
+// without running the handler. The wildcard "*" also matches responses that were never rendered before,
+// so the handler still runs in that case and only successful responses are replaced by "304 Not Modified".
+// Streamed responses are never cached, because they may fail after the status code was sent.
+func HTTPCacheMiddleware(db *database.Database, config *HTTPCacheConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			if req.Method != http.MethodGet && req.Method != http.MethodHead {
+				return next(c)
+			}
+
+			if !strings.HasPrefix(req.URL.Path, APIRoute) {
+				return next(c)
+			}
+
+			etag := computeETag(uint32(db.LedgerIndex()), c)
+
+			maxAge := config.MaxAge
+			cacheControl := "public, max-age=%d"
+			if isImmutableRequest(c) {
+				maxAge = config.ImmutableMaxAge
+				cacheControl = "public, max-age=%d, immutable"
+			}
+			cacheControl = fmt.Sprintf(cacheControl, int64(maxAge.Seconds()))
+
+			res := c.Response()
+
+			setCacheHeaders := func() {
+				res.Header().Set(headerETag, etag)
+				res.Header().Set(headerCacheControl, cacheControl)
+				res.Header().Set(echo.HeaderVary, varyHeaders)
+			}
+
+			ifNoneMatch := req.Header.Get(headerIfNoneMatch)
+			if ifNoneMatch != "" && !acceptsNDJSON(c) && etagMatches(ifNoneMatch, etag) {
+				// the client already has the current representation
+				setCacheHeaders()
+
+				return c.NoContent(http.StatusNotModified)
+			}
+
+			var writer *notModifiedWriter
+			if ifNoneMatch != "" && (isWildcardMatch(ifNoneMatch) || etagMatches(ifNoneMatch, etag)) {
+				writer = &notModifiedWriter{ResponseWriter: res.Writer}
+				res.Writer = writer
+			}
+
+			res.Before(func() {
+				// error responses must not be cached
+				if res.Status < http.StatusOK || res.Status >= http.StatusMultipleChoices {
+					return
+				}
+
+				if isStreamedResponse(res) {
+					res.Header().Set(headerCacheControl, "no-store")
+					return
+				}
+
+				setCacheHeaders()
+
+				if writer != nil {
+					// the client already has the current representation
+					res.Header().Del(echo.HeaderContentType)
+					res.Header().Del(echo.HeaderContentLength)
+					res.Status = http.StatusNotModified
+					writer.notModified = true
+				}
+			})
+
+			return next(c)
+		}
+	}
+}
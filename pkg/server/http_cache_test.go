@@ -0,0 +1,189 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/iota.go/trinary"
+)
+
+const testMutableRoute = APIRoute + "/test"
+
+// newHTTPCacheTestEcho returns an echo instance with the HTTP cache middleware.
+// The milestone 1 exists, milestone 2 is not found and milestone 3 fails.
+// The mutable test route answers with a bad request if the "fail" query parameter is set.
+// The ledger state doesn't match the total supply, so streaming it fails after the first balance.
+func newHTTPCacheTestEcho(t *testing.T) *echo.Echo {
+	t.Helper()
+
+	s, _ := newTestServerWithBalances(t, map[trinary.Hash]uint64{testAddress: 1})
+
+	e := echo.New()
+	e.Use(HTTPCacheMiddleware(s.Database, &HTTPCacheConfig{
+		MaxAge:          time.Minute,
+		ImmutableMaxAge: 24 * time.Hour,
+	}))
+
+	e.GET(APIRoute+RouteMilestoneByIndex, func(c echo.Context) error {
+		switch c.Param(ParameterMilestoneIndex) {
+		case "1":
+			return c.JSON(http.StatusOK, map[string]int{"index": 1})
+		case "2":
+			return echo.ErrNotFound
+		default:
+			return ierrors.New("database failure")
+		}
+	})
+
+	e.GET(testMutableRoute, func(c echo.Context) error {
+		if c.QueryParam("fail") != "" {
+			return echo.ErrBadRequest
+		}
+
+		return c.JSON(http.StatusOK, map[string]string{"value": "test"})
+	})
+
+	e.GET(APIRoute+RouteLedgerStateByIndex, s.ledgerStateByIndexStream)
+
+	return e
+}
+
+func serveHTTPCacheTestRequest(e *echo.Echo, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestHTTPCacheMiddleware(t *testing.T) {
+	e := newHTTPCacheTestEcho(t)
+
+	rec := serveHTTPCacheTestRequest(e, testMutableRoute, nil)
+	etag := rec.Header().Get(headerETag)
+	if rec.Code != http.StatusOK || etag == "" || rec.Header().Get(headerCacheControl) != "public, max-age=60" || rec.Header().Get(echo.HeaderVary) != varyHeaders {
+		t.Fatalf("unexpected response: %d, ETag %q, Cache-Control %q, Vary %q", rec.Code, etag, rec.Header().Get(headerCacheControl), rec.Header().Get(echo.HeaderVary))
+	}
+
+	// the milestone-bound responses never change
+	rec = serveHTTPCacheTestRequest(e, APIRoute+"/milestones/by-index/1", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get(headerCacheControl) != "public, max-age=86400, immutable" {
+		t.Fatalf("unexpected response: %d, Cache-Control %q", rec.Code, rec.Header().Get(headerCacheControl))
+	}
+
+	for _, ifNoneMatch := range []string{etag, `"other", ` + etag, "*"} {
+		rec = serveHTTPCacheTestRequest(e, testMutableRoute, map[string]string{headerIfNoneMatch: ifNoneMatch})
+		if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+			t.Fatalf("expected an empty 304 response for %q, got %d: %s", ifNoneMatch, rec.Code, rec.Body.String())
+		}
+		if rec.Header().Get(headerETag) != etag || rec.Header().Get(headerCacheControl) != "public, max-age=60" || rec.Header().Get(echo.HeaderVary) != varyHeaders {
+			t.Fatalf("unexpected caching headers of the 304 response: ETag %q, Cache-Control %q, Vary %q", rec.Header().Get(headerETag), rec.Header().Get(headerCacheControl), rec.Header().Get(echo.HeaderVary))
+		}
+	}
+
+	rec = serveHTTPCacheTestRequest(e, testMutableRoute, map[string]string{headerIfNoneMatch: `"other"`})
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "test") {
+		t.Fatalf("expected the full response for another ETag, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHTTPCacheMiddlewareErrors(t *testing.T) {
+	e := newHTTPCacheTestEcho(t)
+
+	tests := []struct {
+		path         string
+		expectedCode int
+	}{
+		{path: testMutableRoute + "?fail=1", expectedCode: http.StatusBadRequest},
+		{path: APIRoute + "/milestones/by-index/2", expectedCode: http.StatusNotFound},
+		{path: APIRoute + "/milestones/by-index/3", expectedCode: http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		// error responses are never answered with "304 Not Modified"
+		for _, headers := range []map[string]string{nil, {headerIfNoneMatch: "*"}} {
+			rec := serveHTTPCacheTestRequest(e, test.path, headers)
+
+			if rec.Code != test.expectedCode {
+				t.Fatalf("expected status %d for %s, got %d", test.expectedCode, test.path, rec.Code)
+			}
+			if rec.Header().Get(headerETag) != "" || rec.Header().Get(headerCacheControl) != "" {
+				t.Fatalf("expected no caching headers for %s, got ETag %q, Cache-Control %q", test.path, rec.Header().Get(headerETag), rec.Header().Get(headerCacheControl))
+			}
+		}
+	}
+}
+
+func TestHTTPCacheMiddlewareContentNegotiation(t *testing.T) {
+	e := newHTTPCacheTestEcho(t)
+
+	etagJSON := serveHTTPCacheTestRequest(e, testMutableRoute, map[string]string{echo.HeaderAccept: echo.MIMEApplicationJSON}).Header().Get(headerETag)
+	etagOther := serveHTTPCacheTestRequest(e, testMutableRoute, map[string]string{echo.HeaderAccept: "application/vnd.test"}).Header().Get(headerETag)
+
+	if etagJSON == "" || etagOther == "" || etagJSON == etagOther {
+		t.Fatalf("expected different ETags for different Accept headers, got %q and %q", etagJSON, etagOther)
+	}
+
+	// the ETag of another representation doesn't match
+	rec := serveHTTPCacheTestRequest(e, testMutableRoute, map[string]string{echo.HeaderAccept: "application/vnd.test", headerIfNoneMatch: etagJSON})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the full response for the ETag of another representation, got %d", rec.Code)
+	}
+}
+
+func TestHTTPCacheMiddlewareMatchingETag(t *testing.T) {
+	e := newHTTPCacheTestEcho(t)
+	s, _ := newTestServer(t)
+
+	etagOf := func(method string, path string) string {
+		return computeETag(uint32(s.Database.LedgerIndex()), e.NewContext(httptest.NewRequest(method, path, nil), httptest.NewRecorder()))
+	}
+
+	// GET and HEAD requests share the same validator
+	if etagOf(http.MethodGet, testMutableRoute) != etagOf(http.MethodHead, testMutableRoute) {
+		t.Fatal("expected the same ETag for GET and HEAD requests")
+	}
+
+	// a matching ETag is answered without running the handler, which would fail for this request
+	failingPath := testMutableRoute + "?fail=1"
+	rec := serveHTTPCacheTestRequest(e, failingPath, map[string]string{headerIfNoneMatch: etagOf(http.MethodGet, failingPath)})
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Fatalf("expected an empty 304 response, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHTTPCacheMiddlewareFailingStream(t *testing.T) {
+	e := newHTTPCacheTestEcho(t)
+
+	path := APIRoute + "/ledger/state/by-index/1"
+	for _, headers := range []map[string]string{
+		{echo.HeaderAccept: MIMEApplicationNDJSON},
+		{echo.HeaderAccept: MIMEApplicationNDJSON, headerIfNoneMatch: "*"},
+	} {
+		rec := serveHTTPCacheTestRequest(e, path, headers)
+
+		// the status code was sent with the first record, the failure is reported by the last record
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+		}
+
+		records := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+		if len(records) != 2 || !strings.Contains(records[0], testAddress) || !strings.Contains(records[1], `"error"`) {
+			t.Fatalf("expected a balance and an error record, got: %s", rec.Body.String())
+		}
+
+		if rec.Header().Get(headerETag) != "" || rec.Header().Get(headerCacheControl) != "no-store" {
+			t.Fatalf("expected the stream not to be cached, got ETag %q, Cache-Control %q", rec.Header().Get(headerETag), rec.Header().Get(headerCacheControl))
+		}
+	}
+}
@@ -27,7 +27,10 @@ func (s *DatabaseServer) rpcGetInclusionStates(c echo.Context) (interface{}, err
 
 	for _, tx := range request.Transactions {
 		// get tx data
-		txMeta := s.Database.TxMetadataOrNil(hornet.HashFromHashTrytes(tx))
+		txMeta, err := s.Database.TxMetadataOrNil(hornet.HashFromHashTrytes(tx))
+		if err != nil {
+			return nil, databaseError(err)
+		}
 		if txMeta == nil {
 			// if tx is unknown, return false
 			inclusionStates = append(inclusionStates, false)
@@ -52,7 +55,10 @@ func (s *DatabaseServer) transactionMetadata(c echo.Context) (interface{}, error
 	}
 
 	// get tx data
-	txMeta := s.Database.TxMetadataOrNil(txHash)
+	txMeta, err := s.Database.TxMetadataOrNil(txHash)
+	if err != nil {
+		return nil, databaseError(err)
+	}
 	if txMeta == nil {
 		// if tx is unknown, return false
 		return &transactionMetadataResponse{
@@ -26,9 +26,12 @@ type newBundleWithValueFunc[B Container, T Container] func(bundleHash trinary.Ha
 //nolint:nonamedreturns
 func getMilestoneStateDiff[T Container, H Container, B Container](db *database.Database, milestoneIndex milestone.Index, newTxWithValue newTxWithValueFunc[T], newTxHashWithValue newTxHashWithValueFunc[H], newBundleWithValue newBundleWithValueFunc[B, T]) (confirmedTxWithValue []H, confirmedBundlesWithValue []B, totalLedgerChanges map[string]int64, err error) {
 
-	msBndl := db.MilestoneBundleOrNil(milestoneIndex)
+	msBndl, err := db.MilestoneBundleOrNil(milestoneIndex)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 	if msBndl == nil {
-		return nil, nil, nil, ierrors.Errorf("milestone not found: %d", milestoneIndex)
+		return nil, nil, nil, ierrors.Wrapf(database.ErrMilestoneNotFound, "index: %d", milestoneIndex)
 	}
 
 	txsToConfirm := make(map[string]struct{})
@@ -54,9 +57,13 @@ func getMilestoneStateDiff[T Container, H Container, B Container](db *database.D
 				continue
 			}
 
-			txMeta := db.TxMetadataOrNil(hornet.Hash(txHash))
+			txMeta, err := db.TxMetadataOrNil(hornet.Hash(txHash))
+			if err != nil {
+				return nil, nil, nil, err
+			}
 			if txMeta == nil {
-				return nil, nil, nil, ierrors.Errorf("getMilestoneStateDiff: transaction not found: %v", hornet.Hash(txHash).Trytes())
+				// the cone of a solid milestone has to be complete
+				return nil, nil, nil, ierrors.Wrapf(database.ErrCorrupted, "getMilestoneStateDiff: transaction not found: %v", hornet.Hash(txHash).Trytes())
 			}
 
 			confirmed, at := txMeta.ConfirmedWithIndex()
@@ -66,7 +73,7 @@ func getMilestoneStateDiff[T Container, H Container, B Container](db *database.D
 					continue
 				}
 			} else {
-				return nil, nil, nil, ierrors.Errorf("getMilestoneStateDiff: transaction not confirmed yet: %v", hornet.Hash(txHash).Trytes())
+				return nil, nil, nil, ierrors.Wrapf(database.ErrCorrupted, "getMilestoneStateDiff: transaction not confirmed yet: %v", hornet.Hash(txHash).Trytes())
 			}
 
 			// Mark the approvees to be traversed
@@ -77,17 +84,20 @@ func getMilestoneStateDiff[T Container, H Container, B Container](db *database.D
 				continue
 			}
 
-			bndl := db.BundleOrNil(hornet.Hash(txHash))
+			bndl, err := db.BundleOrNil(hornet.Hash(txHash))
+			if err != nil {
+				return nil, nil, nil, err
+			}
 			if bndl == nil {
 				txBundle := txMeta.BundleHash()
 
-				return nil, nil, nil, ierrors.Errorf("getMilestoneStateDiff: Tx: %v, bundle not found: %v", hornet.Hash(txHash).Trytes(), txBundle.Trytes())
+				return nil, nil, nil, ierrors.Wrapf(database.ErrCorrupted, "getMilestoneStateDiff: Tx: %v, bundle not found: %v", hornet.Hash(txHash).Trytes(), txBundle.Trytes())
 			}
 
 			if !bndl.IsValid() {
 				txBundle := txMeta.BundleHash()
 
-				return nil, nil, nil, ierrors.Errorf("getMilestoneStateDiff: Tx: %v, bundle not valid: %v", hornet.Hash(txHash).Trytes(), txBundle.Trytes())
+				return nil, nil, nil, ierrors.Wrapf(database.ErrCorrupted, "getMilestoneStateDiff: Tx: %v, bundle not valid: %v", hornet.Hash(txHash).Trytes(), txBundle.Trytes())
 			}
 
 			if !bndl.IsValueSpam() {
@@ -95,7 +105,10 @@ func getMilestoneStateDiff[T Container, H Container, B Container](db *database.D
 
 				var txsWithValue []T
 
-				txs := bndl.Transactions()
+				txs, err := bndl.Transactions()
+				if err != nil {
+					return nil, nil, nil, err
+				}
 				for _, hornetTx := range txs {
 					// hornetTx is being retained during the loop, so safe to use the pointer here
 					if hornetTx.Tx.Value != 0 {
@@ -107,7 +120,10 @@ func getMilestoneStateDiff[T Container, H Container, B Container](db *database.D
 					totalLedgerChanges[address] += change
 				}
 
-				bundleHeadTx := bndl.Head()
+				bundleHeadTx, err := bndl.Head()
+				if err != nil {
+					return nil, nil, nil, err
+				}
 				confirmedBundlesWithValue = append(confirmedBundlesWithValue, newBundleWithValue(txMeta.BundleHash().Trytes(), bndl.TailHash().Trytes(), txsWithValue, bundleHeadTx.Tx.CurrentIndex))
 			}
 
@@ -123,6 +139,34 @@ func getMilestoneStateDiff[T Container, H Container, B Container](db *database.D
 	return confirmedTxWithValue, confirmedBundlesWithValue, totalLedgerChanges, nil
 }
 
+func newTxWithValueREST(txHash trinary.Hash, address trinary.Hash, index uint64, value int64) *txWithValue {
+	return &txWithValue{
+		TxHash:  txHash,
+		Address: address,
+		Index:   uint32(index),
+		Value:   strconv.FormatInt(value, 10),
+	}
+}
+
+func newTxHashWithValueREST(txHash trinary.Hash, tailTxHash trinary.Hash, bundleHash trinary.Hash, address trinary.Hash, value int64) *txHashWithValue {
+	return &txHashWithValue{
+		TxHash:     txHash,
+		TailTxHash: tailTxHash,
+		Bundle:     bundleHash,
+		Address:    address,
+		Value:      strconv.FormatInt(value, 10),
+	}
+}
+
+func newBundleWithValueREST(bundleHash trinary.Hash, tailTxHash trinary.Hash, transactions []*txWithValue, lastIndex uint64) *bundleWithValue {
+	return &bundleWithValue{
+		Bundle:     bundleHash,
+		TailTxHash: tailTxHash,
+		Txs:        transactions,
+		LastIndex:  uint32(lastIndex),
+	}
+}
+
 func (s *DatabaseServer) rpcGetLedgerState(c echo.Context) (interface{}, error) {
 	request := &GetLedgerState{}
 	if err := c.Bind(request); err != nil {
@@ -131,7 +175,7 @@ func (s *DatabaseServer) rpcGetLedgerState(c echo.Context) (interface{}, error)
 
 	balances, index, err := s.Database.LedgerStateForMilestone(c.Request().Context(), request.TargetIndex)
 	if err != nil {
-		return nil, ierrors.Wrap(echo.ErrInternalServerError, err.Error())
+		return nil, databaseError(err)
 	}
 
 	balancesTrytes := make(map[trinary.Trytes]uint64)
@@ -159,7 +203,7 @@ func (s *DatabaseServer) rpcGetLedgerDiff(c echo.Context) (interface{}, error) {
 
 	diff, err := s.Database.LedgerDiffForMilestone(c.Request().Context(), requestedIndex)
 	if err != nil {
-		return nil, ierrors.Wrap(echo.ErrInternalServerError, err.Error())
+		return nil, databaseError(err)
 	}
 
 	diffTrytes := make(map[trinary.Trytes]int64)
@@ -215,7 +259,7 @@ func (s *DatabaseServer) rpcGetLedgerDiffExt(c echo.Context) (interface{}, error
 
 	confirmedTxWithValue, confirmedBundlesWithValue, ledgerChanges, err := getMilestoneStateDiff(s.Database, requestedIndex, newTxWithValue, newTxHashWithValue, newBundleWithValue)
 	if err != nil {
-		return nil, ierrors.Wrap(echo.ErrInternalServerError, err.Error())
+		return nil, databaseError(err)
 	}
 
 	ledgerChangesTrytes := make(map[trinary.Trytes]int64)
@@ -235,7 +279,7 @@ func (s *DatabaseServer) rpcGetLedgerDiffExt(c echo.Context) (interface{}, error
 func (s *DatabaseServer) ledgerState(c echo.Context, targetIndex milestone.Index) (interface{}, error) {
 	balances, index, err := s.Database.LedgerStateForMilestone(c.Request().Context(), targetIndex)
 	if err != nil {
-		return nil, ierrors.Wrap(echo.ErrInternalServerError, err.Error())
+		return nil, databaseError(err)
 	}
 
 	addressesWithBalances := make(map[trinary.Trytes]string)
@@ -276,7 +320,7 @@ func (s *DatabaseServer) ledgerDiff(c echo.Context) (interface{}, error) {
 
 	diff, err := s.Database.LedgerDiffForMilestone(c.Request().Context(), msIndex)
 	if err != nil {
-		return nil, ierrors.Wrap(echo.ErrInternalServerError, err.Error())
+		return nil, databaseError(err)
 	}
 
 	addressesWithDiffs := make(map[trinary.Trytes]string)
@@ -302,37 +346,9 @@ func (s *DatabaseServer) ledgerDiffExtended(c echo.Context) (interface{}, error)
 		return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid milestone index: %d, lsmi is %d", msIndex, smi)
 	}
 
-	newTxWithValue := func(txHash trinary.Hash, address trinary.Hash, index uint64, value int64) *txWithValue {
-		return &txWithValue{
-			TxHash:  txHash,
-			Address: address,
-			Index:   uint32(index),
-			Value:   strconv.FormatInt(value, 10),
-		}
-	}
-
-	newTxHashWithValue := func(txHash trinary.Hash, tailTxHash trinary.Hash, bundleHash trinary.Hash, address trinary.Hash, value int64) *txHashWithValue {
-		return &txHashWithValue{
-			TxHash:     txHash,
-			TailTxHash: tailTxHash,
-			Bundle:     bundleHash,
-			Address:    address,
-			Value:      strconv.FormatInt(value, 10),
-		}
-	}
-
-	newBundleWithValue := func(bundleHash trinary.Hash, tailTxHash trinary.Hash, transactions []*txWithValue, lastIndex uint64) *bundleWithValue {
-		return &bundleWithValue{
-			Bundle:     bundleHash,
-			TailTxHash: tailTxHash,
-			Txs:        transactions,
-			LastIndex:  uint32(lastIndex),
-		}
-	}
-
-	confirmedTxWithValue, confirmedBundlesWithValue, ledgerChanges, err := getMilestoneStateDiff(s.Database, msIndex, newTxWithValue, newTxHashWithValue, newBundleWithValue)
+	confirmedTxWithValue, confirmedBundlesWithValue, ledgerChanges, err := getMilestoneStateDiff(s.Database, msIndex, newTxWithValueREST, newTxHashWithValueREST, newBundleWithValueREST)
 	if err != nil {
-		return nil, ierrors.Wrap(echo.ErrInternalServerError, err.Error())
+		return nil, databaseError(err)
 	}
 
 	addressesWithDiffs := make(map[trinary.Trytes]string)
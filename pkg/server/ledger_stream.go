@@ -0,0 +1,158 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-app/pkg/httpserver"
+
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+)
+
+const (
+	// MIMEApplicationNDJSON is the MIME type of newline-delimited JSON.
+	MIMEApplicationNDJSON = "application/x-ndjson"
+
+	// HeaderLedgerIndex is the header that contains the ledger index of a streamed response.
+	HeaderLedgerIndex = "X-Ledger-Index"
+
+	// the amount of records after which the streamed response is flushed.
+	streamFlushInterval = 1000
+)
+
+// acceptsNDJSON returns whether the client requested a newline-delimited JSON response.
+func acceptsNDJSON(c echo.Context) bool {
+	return strings.Contains(c.Request().Header.Get(echo.HeaderAccept), MIMEApplicationNDJSON)
+}
+
+// ndjsonStream writes newline-delimited JSON records to the response.
+// The response headers are only written with the first record, so errors that happen
+// before any record was written are still returned as regular error responses.
+type ndjsonStream struct {
+	c           echo.Context
+	ledgerIndex milestone.Index
+	encoder     *json.Encoder
+	records     int
+}
+
+func newNDJSONStream(c echo.Context, ledgerIndex milestone.Index) *ndjsonStream {
+	return &ndjsonStream{
+		c:           c,
+		ledgerIndex: ledgerIndex,
+		encoder:     nil,
+		records:     0,
+	}
+}
+
+// start writes the response headers, if they were not written yet.
+func (s *ndjsonStream) start() {
+	if s.encoder != nil {
+		return
+	}
+
+	s.c.Response().Header().Set(echo.HeaderContentType, MIMEApplicationNDJSON)
+	s.c.Response().Header().Set(HeaderLedgerIndex, strconv.FormatUint(uint64(s.ledgerIndex), 10))
+	s.c.Response().WriteHeader(http.StatusOK)
+
+	s.encoder = json.NewEncoder(s.c.Response())
+}
+
+func (s *ndjsonStream) write(record interface{}) error {
+	s.start()
+
+	if err := s.encoder.Encode(record); err != nil {
+		return err
+	}
+
+	s.records++
+	if s.records%streamFlushInterval == 0 {
+		s.c.Response().Flush()
+	}
+
+	return nil
+}
+
+// finish ends the stream. If the stream failed before any record was written, the error is returned,
+// so it is sent as a regular error response. Otherwise the status code was already sent,
+// so the stream is ended with a terminal error record instead.
+func (s *ndjsonStream) finish(err error) error {
+	if err != nil && s.encoder == nil {
+		return databaseError(err)
+	}
+
+	s.start()
+
+	if err != nil {
+		// if the record can't be written, the client is gone anyway
+		_ = s.encoder.Encode(&streamErrorRecord{Error: err.Error()})
+	}
+
+	s.c.Response().Flush()
+
+	return nil
+}
+
+func (s *DatabaseServer) ledgerStateStream(c echo.Context, targetIndex milestone.Index) error {
+	if err := s.checkLedgerIndex(targetIndex); err != nil {
+		return err
+	}
+
+	// the ledger diffs are walked before the response headers are written
+	ledgerStream, err := s.Database.NewLedgerStateStream(c.Request().Context(), targetIndex)
+	if err != nil {
+		return databaseError(err)
+	}
+
+	stream := newNDJSONStream(c, ledgerStream.TargetIndex())
+
+	return stream.finish(ledgerStream.Stream(c.Request().Context(), func(address hornet.Hash, balance uint64) error {
+		return stream.write(&ledgerStateRecord{
+			Address: address.Trytes(),
+			Balance: strconv.FormatUint(balance, 10),
+		})
+	}))
+}
+
+func (s *DatabaseServer) ledgerStateByLatestSolidIndexStream(c echo.Context) error {
+	return s.ledgerStateStream(c, 0)
+}
+
+func (s *DatabaseServer) ledgerStateByIndexStream(c echo.Context) error {
+	msIndex, err := httpserver.ParseMilestoneIndexParam(c, ParameterMilestoneIndex)
+	if err != nil {
+		return err
+	}
+
+	return s.ledgerStateStream(c, milestone.Index(msIndex))
+}
+
+func (s *DatabaseServer) ledgerDiffStream(c echo.Context) error {
+	msIndexIotaGo, err := httpserver.ParseMilestoneIndexParam(c, ParameterMilestoneIndex)
+	if err != nil {
+		return err
+	}
+	msIndex := milestone.Index(msIndexIotaGo)
+
+	if msIndex == 0 {
+		return ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid milestone index: %d", msIndex)
+	}
+
+	if err := s.checkLedgerIndex(msIndex); err != nil {
+		return err
+	}
+
+	stream := newNDJSONStream(c, msIndex)
+
+	return stream.finish(s.Database.StreamLedgerDiffForMilestone(c.Request().Context(), msIndex, func(address hornet.Hash, change int64) error {
+		return stream.write(&ledgerDiffRecord{
+			Address: address.Trytes(),
+			Diff:    strconv.FormatInt(change, 10),
+		})
+	}))
+}
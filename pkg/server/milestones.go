@@ -1,11 +1,19 @@
 package server
 
 import (
+	"bytes"
+	"encoding/base64"
+	"sort"
+	"strconv"
+	"strings"
+
 	"github.com/labstack/echo/v4"
 
 	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
 	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
 	"github.com/iotaledger/inx-app/pkg/httpserver"
+	"github.com/iotaledger/iota.go/guards"
 )
 
 func (s *DatabaseServer) milestone(c echo.Context) (interface{}, error) {
@@ -20,14 +28,195 @@ func (s *DatabaseServer) milestone(c echo.Context) (interface{}, error) {
 		return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid milestone index: %d, lsmi is %d", msIndex, smi)
 	}
 
-	msBndl := s.Database.MilestoneBundleOrNil(msIndex)
+	msBndl, err := s.Database.MilestoneBundleOrNil(msIndex)
+	if err != nil {
+		return nil, databaseError(err)
+	}
 	if msBndl == nil {
-		return nil, ierrors.Errorf("milestone not found: %d", msIndex)
+		return nil, ierrors.Wrapf(echo.ErrNotFound, "milestone not found: %d", msIndex)
+	}
+
+	msTail, err := msBndl.Tail()
+	if err != nil {
+		return nil, databaseError(err)
 	}
 
 	return milestoneResponse{
 		MilestoneIndex:     msIndex,
-		MilestoneHash:      msBndl.Tail().Tx.Hash,
-		MilestoneTimestamp: msBndl.Tail().Tx.Timestamp,
+		MilestoneHash:      msTail.Tx.Hash,
+		MilestoneTimestamp: msTail.Tx.Timestamp,
+	}, nil
+}
+
+// milestoneByIndex returns the response for the milestone with the given index.
+func (s *DatabaseServer) milestoneByIndex(msIndex milestone.Index) (*milestoneResponse, error) {
+	ms, err := s.Database.MilestoneOrNil(msIndex)
+	if err != nil {
+		return nil, databaseError(err)
+	}
+	if ms == nil {
+		return nil, ierrors.Wrapf(echo.ErrNotFound, "milestone not found: %d", msIndex)
+	}
+
+	timestamp, err := s.Database.MilestoneTimestamp(msIndex)
+	if err != nil {
+		return nil, databaseError(err)
+	}
+
+	return &milestoneResponse{
+		MilestoneIndex:     msIndex,
+		MilestoneHash:      ms.Hash.Trytes(),
+		MilestoneTimestamp: timestamp,
+	}, nil
+}
+
+func (s *DatabaseServer) milestones(c echo.Context) (interface{}, error) {
+	fromIndex, err := parseMilestoneIndexQueryParam(c, QueryParameterFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	toIndex, err := parseMilestoneIndexQueryParam(c, QueryParameterTo)
+	if err != nil {
+		return nil, err
+	}
+
+	maxResults, err := parseMaxResultsQueryParam(c, s.RestAPILimitsMaxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	// milestones below the pruning index are not available
+	if fromIndex <= s.Database.PruningIndex() {
+		fromIndex = s.Database.PruningIndex() + 1
+	}
+
+	smi := s.Database.SolidMilestoneIndex()
+	if toIndex == 0 || toIndex > smi {
+		toIndex = smi
+	}
+
+	if fromIndex > toIndex {
+		return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid milestone range: %s %d is bigger than %s %d", QueryParameterFrom, fromIndex, QueryParameterTo, toIndex)
+	}
+
+	milestones := make([]*milestoneResponse, 0)
+	var next milestone.Index
+
+	// the amount of scanned milestone indexes is limited, not the amount of found milestones,
+	// so missing milestones can't extend the scanned range.
+	for msIndex := fromIndex; msIndex <= toIndex; msIndex++ {
+		if int(msIndex-fromIndex) >= maxResults {
+			next = msIndex
+			break
+		}
+
+		ms, err := s.milestoneByIndex(msIndex)
+		if err != nil {
+			if ierrors.Is(err, echo.ErrNotFound) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		milestones = append(milestones, ms)
+	}
+
+	return &milestonesResponse{
+		Milestones:  milestones,
+		Next:        next,
+		LedgerIndex: smi,
+	}, nil
+}
+
+func (s *DatabaseServer) milestoneByHash(c echo.Context) (interface{}, error) {
+	msHash := strings.ToUpper(c.Param(ParameterMilestoneHash))
+	if !guards.IsTransactionHash(msHash) {
+		return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid milestone hash provided: %s", msHash)
+	}
+
+	msIndex, err := s.Database.MilestoneIndexByHash(hornet.HashFromHashTrytes(msHash))
+	if err != nil {
+		return nil, databaseError(err)
+	}
+
+	return s.milestoneByIndex(msIndex)
+}
+
+func (s *DatabaseServer) milestoneByTimestamp(c echo.Context) (interface{}, error) {
+	timestamp, err := strconv.ParseUint(c.Param(ParameterTimestamp), 10, 64)
+	if err != nil {
+		return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid timestamp: %s, error: %s", c.Param(ParameterTimestamp), err)
+	}
+
+	msIndex, err := s.Database.MilestoneIndexByTimestamp(timestamp)
+	if err != nil {
+		return nil, databaseError(err)
+	}
+
+	return s.milestoneByIndex(msIndex)
+}
+
+func (s *DatabaseServer) milestoneTransactions(c echo.Context) (interface{}, error) {
+	msIndexIotaGo, err := httpserver.ParseMilestoneIndexParam(c, ParameterMilestoneIndex)
+	if err != nil {
+		return nil, err
+	}
+	msIndex := milestone.Index(msIndexIotaGo)
+
+	smi := s.Database.SolidMilestoneIndex()
+	if msIndex > smi {
+		return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid milestone index: %d, lsmi is %d", msIndex, smi)
+	}
+
+	maxResults, err := parseMaxResultsQueryParam(c, s.RestAPILimitsMaxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	// the cursor is the hash of the last returned transaction
+	var cursor []byte
+	if value := c.QueryParam(QueryParameterCursor); len(value) > 0 {
+		if cursor, err = base64.RawURLEncoding.DecodeString(value); err != nil || len(cursor) != hornet.HashSize {
+			return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid cursor provided: %s", value)
+		}
+	}
+
+	confirmedTxs, err := s.Database.MilestoneConfirmedTransactions(c.Request().Context(), msIndex)
+	if err != nil {
+		return nil, databaseError(err)
+	}
+
+	// skip all transactions that were returned before
+	start := 0
+	if cursor != nil {
+		start = sort.Search(len(confirmedTxs), func(i int) bool { return bytes.Compare(confirmedTxs[i].TxHash(), cursor) > 0 })
+	}
+	confirmedTxs = confirmedTxs[start:]
+
+	var nextCursor string
+	if len(confirmedTxs) > maxResults {
+		confirmedTxs = confirmedTxs[:maxResults]
+		nextCursor = base64.RawURLEncoding.EncodeToString(confirmedTxs[len(confirmedTxs)-1].TxHash())
+	}
+
+	transactions := make([]*milestoneTransaction, 0, len(confirmedTxs))
+	for _, txMeta := range confirmedTxs {
+		transactions = append(transactions, &milestoneTransaction{
+			TxHash:      txMeta.TxHash().Trytes(),
+			Bundle:      txMeta.BundleHash().Trytes(),
+			IsTail:      txMeta.IsTail(),
+			IsHead:      txMeta.IsHead(),
+			IsValue:     txMeta.IsValue(),
+			Conflicting: txMeta.IsConflicting(),
+		})
+	}
+
+	return &milestoneTransactionsResponse{
+		MilestoneIndex: msIndex,
+		Transactions:   transactions,
+		Cursor:         nextCursor,
+		LedgerIndex:    smi,
 	}, nil
 }
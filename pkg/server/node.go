@@ -7,7 +7,10 @@ import (
 )
 
 func (s *DatabaseServer) rpcGetNodeInfo(_ echo.Context) (any, error) {
-	syncState := s.Database.LatestSyncState()
+	syncState, err := s.Database.LatestSyncState()
+	if err != nil {
+		return nil, databaseError(err)
+	}
 
 	return &GetNodeInfoResponse{
 		AppName:                            s.AppInfo.Name,
@@ -29,10 +32,12 @@ func (s *DatabaseServer) rpcGetNodeInfo(_ echo.Context) (any, error) {
 	}, nil
 }
 
-//nolint:unparam // even if the error is never used, the structure of all routes should be the same
 func (s *DatabaseServer) info() (*infoResponse, error) {
 
-	syncState := s.Database.LatestSyncState()
+	syncState, err := s.Database.LatestSyncState()
+	if err != nil {
+		return nil, databaseError(err)
+	}
 
 	return &infoResponse{
 		AppName:                            s.AppInfo.Name,
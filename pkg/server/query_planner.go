@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
+)
+
+// planTransactionsQuery returns the index that should be used to search for the transactions of the query.
+//
+// Semantics of the filters:
+//   - the values of a single filter type are combined with OR (e.g. any of the given addresses).
+//   - different filter types are combined with AND (e.g. bundle AND address).
+//   - "valueOnly" only applies to the address filter.
+//
+// The most selective index is used to iterate the candidates, which is the index with the least
+// entries for the given values. The entries are only sampled up to maxResults+1 per index,
+// so big indexes (e.g. popular tags or addresses) are not counted completely. An index with at most
+// maxResults entries can be searched completely for a single page, so there is no need to count further.
+// If all indexes exceed the sample, the first given filter is used.
+// If a cursor is given, the index of the cursor is used to continue the previous search.
+func (s *DatabaseServer) planTransactionsQuery(ctx context.Context, query *database.TransactionsQuery, cursor []byte, maxResults int) (database.TransactionsIndex, error) {
+	if len(cursor) > 0 {
+		return database.TransactionsIndexFromCursor(cursor)
+	}
+
+	var bestIndex database.TransactionsIndex
+	bestCount := maxResults + 1
+
+	for _, index := range database.TransactionsIndexes {
+		values := query.Values(index)
+		if len(values) == 0 {
+			continue
+		}
+
+		if bestIndex == 0 {
+			// use the first given filter if all indexes exceed the count limit
+			bestIndex = index
+		}
+
+		count, err := s.Database.CountTransactionsInIndex(ctx, index, values, query.ValueOnly, bestCount)
+		if err != nil {
+			return 0, err
+		}
+
+		if count < bestCount {
+			bestIndex = index
+			bestCount = count
+		}
+
+		if bestCount == 0 {
+			// there can't be any results
+			break
+		}
+	}
+
+	if bestIndex == 0 {
+		return 0, database.ErrNoSearchCriteria
+	}
+
+	return bestIndex, nil
+}
@@ -15,13 +15,29 @@ import (
 const (
 	ParameterAddress         = "address"
 	ParameterTransactionHash = "txHash"
+	ParameterTailTxHash      = "tailTxHash"
+	ParameterBundleHash      = "bundleHash"
 	ParameterMilestoneIndex  = "index"
-
-	QueryParameterBundle     = "bundle"
-	QueryParameterAddress    = "address"
-	QueryParameterTag        = "tag"
-	QueryParameterApprovee   = "approvee"
-	QueryParameterMaxResults = "maxResults"
+	ParameterMilestoneHash   = "hash"
+	ParameterTimestamp       = "timestamp"
+
+	QueryParameterBundle      = "bundle"
+	QueryParameterAddress     = "address"
+	QueryParameterTag         = "tag"
+	QueryParameterApprovee    = "approvee"
+	QueryParameterMaxResults  = "maxResults"
+	QueryParameterLedgerIndex = "ledgerIndex"
+	QueryParameterStartIndex  = "startIndex"
+	QueryParameterEndIndex    = "endIndex"
+	QueryParameterCursor      = "cursor"
+	QueryParameterFrom        = "from"
+	QueryParameterTo          = "to"
+	QueryParameterDirection   = "direction"
+	QueryParameterDepth       = "depth"
+	QueryParameterMax         = "max"
+
+	// TagPrefixWildcard is the suffix of a tag to search for all tags with the given prefix.
+	TagPrefixWildcard = "*"
 )
 
 const (
@@ -33,14 +49,36 @@ const (
 	// GET returns the node info.
 	RouteInfo = "/info"
 
+	// RouteMilestones is the route for getting a list of milestones.
+	// GET returns the index, hash and timestamp of the milestones in the given range.
+	// Query parameters: "from", "to", "maxResults"
+	RouteMilestones = "/milestones"
+
 	// RouteMilestoneByIndex is the route for getting a milestone by its milestoneIndex.
 	// GET will return the milestone.
 	RouteMilestoneByIndex = "/milestones/by-index/:" + ParameterMilestoneIndex
 
+	// RouteMilestoneTransactions is the route for getting all transactions that were confirmed by a milestone.
+	// GET will return the hashes of all confirmed transactions, including zero-value transactions, sorted by hash.
+	// Query parameters: "maxResults", "cursor"
+	// If there are more results than "maxResults", a cursor is returned to query the next results.
+	RouteMilestoneTransactions = "/milestones/by-index/:" + ParameterMilestoneIndex + "/transactions"
+
+	// RouteMilestoneByHash is the route for getting a milestone by the hash of its tail transaction.
+	// GET will return the milestone.
+	RouteMilestoneByHash = "/milestones/by-hash/:" + ParameterMilestoneHash
+
+	// RouteMilestoneByTimestamp is the route for getting the milestone that was in effect at the given unix timestamp.
+	// GET will return the latest milestone with a timestamp lower or equal to the given timestamp.
+	RouteMilestoneByTimestamp = "/milestones/by-timestamp/:" + ParameterTimestamp
+
 	// RouteTransactions is the route for getting transactions filtered by the given parameters.
 	// GET with query parameter returns all txHashes that fit these filter criteria.
-	// Query parameters: "bundle", "address", "tag", "approvee", "maxResults"
+	// Query parameters: "bundle", "address", "tag", "approvee", "maxResults", "cursor"
+	// If several filters are given, the returned transactions match all of them.
+	// If the tag ends with "*", all transactions with a tag that starts with the given trytes are returned.
 	// Returns an empty list if no results are found.
+	// If there are more results than "maxResults", a cursor is returned to query the next results.
 	RouteTransactions = "/transactions" // former findTransactions
 
 	// RouteTransaction is the route for getting a transaction.
@@ -55,24 +93,63 @@ const (
 	// GET will return the metadata.
 	RouteTransactionMetadata = "/transactions/:" + ParameterTransactionHash + "/metadata" // former getInclusionStates
 
+	// RouteTransactionApprovers is the route for getting the approvers of a transaction.
+	// GET will return the hashes of all transactions that directly approve the transaction.
+	// Query parameters: "maxResults", "cursor"
+	RouteTransactionApprovers = "/transactions/:" + ParameterTransactionHash + "/approvers"
+
+	// RouteTransactionApprovees is the route for getting the approvees of a transaction.
+	// GET will return the hashes of the trunk and the branch transaction.
+	RouteTransactionApprovees = "/transactions/:" + ParameterTransactionHash + "/approvees"
+
+	// RouteTransactionCone is the route for traversing the past or future cone of a transaction.
+	// GET will return the transactions in breadth-first order and the approvals between them, with their confirmation state.
+	// Query parameters: "direction" ("past" or "future"), "depth", "max"
+	RouteTransactionCone = "/transactions/:" + ParameterTransactionHash + "/cone"
+
+	// RouteBundle is the route for getting a bundle by the hash of its tail transaction.
+	// GET will return the transactions of the bundle ordered by their index, the bundle metadata,
+	// the ledger changes and the milestone that confirmed the bundle.
+	RouteBundle = "/bundles/:" + ParameterTailTxHash
+
+	// RouteBundleAttachments is the route for getting all attachments of a bundle hash.
+	// GET will return the tail transactions of all attachments with their confirmation state.
+	// Query parameters: "maxResults", "cursor"
+	RouteBundleAttachments = "/bundles/by-hash/:" + ParameterBundleHash + "/attachments"
+
 	// RouteAddressBalance is the route for getting the balance of an address.
 	// GET will return the balance.
+	// Query parameters: "ledgerIndex"
 	RouteAddressBalance = "/addresses/:" + ParameterAddress + "/balance" // former getBalances
 
+	// RouteAddressHistory is the route for getting the balance history of an address.
+	// GET will return all milestones in the given range that changed the balance of the address, starting with the newest one.
+	// Query parameters: "startIndex", "endIndex", "maxResults"
+	RouteAddressHistory = "/addresses/:" + ParameterAddress + "/history"
+
 	// RouteAddressBalance is the route to check whether an address was already spent or not.
 	// GET will return true if the address was already spent.
 	RouteAddressWasSpent = "/addresses/:" + ParameterAddress + "/was-spent" // former wereAddressesSpentFrom
 
+	// RouteSpentAddressesFilter is the route for getting a Bloom filter over all spent addresses.
+	// GET will return the filter and its parameters, so that clients can check addresses locally
+	// and only need to confirm the positive results with "was-spent" or "wereAddressesSpentFrom".
+	// The elements of the filter are the 81 trytes of the addresses without checksum.
+	RouteSpentAddressesFilter = "/spent-addresses/filter"
+
 	// RouteLedgerState is the route to return the current ledger state.
 	// GET will return all addresses with their balances.
+	// If the "Accept" header contains "application/x-ndjson", the balances are streamed as newline-delimited JSON records.
 	RouteLedgerState = "/ledger/state" // former getLedgerState
 
 	// RouteLedgerStateByIndex is the route to return the ledger state of a given ledger index.
 	// GET will return all addresses with their balances.
+	// If the "Accept" header contains "application/x-ndjson", the balances are streamed as newline-delimited JSON records.
 	RouteLedgerStateByIndex = "/ledger/state/by-index/:" + ParameterMilestoneIndex // former getLedgerState
 
 	// RouteLedgerDiffByIndex is the route to return the ledger diff of a given ledger index.
 	// GET will return all addresses with their diffs.
+	// If the "Accept" header contains "application/x-ndjson", the diffs are streamed as newline-delimited JSON records.
 	RouteLedgerDiffByIndex = "/ledger/diff/by-index/:" + ParameterMilestoneIndex // former getLedgerDiff
 
 	// RouteLedgerDiffExtendedByIndex is the route to return the ledger diff of a given ledger index with extended informations.
@@ -120,6 +197,20 @@ func (s *DatabaseServer) configureRoutes(routeGroup echoswagger.ApiGroup) {
 		SetDescription("the route for getting the node info").
 		SetOperationId("info")
 
+	routeGroup.GET(RouteMilestones, func(c echo.Context) error {
+		resp, err := s.milestones(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	}).
+		SetDescription("the route for getting a list of milestones").
+		SetOperationId("milestones").
+		AddParamQuery("", QueryParameterFrom, "the index of the first milestone (default: pruning index + 1)", false).
+		AddParamQuery("", QueryParameterTo, "the index of the last milestone (default: latest ledger index)", false).
+		AddParamQuery("", QueryParameterMaxResults, "limit the maximum number of results", false)
+
 	routeGroup.GET(RouteMilestoneByIndex, func(c echo.Context) error {
 		resp, err := s.milestone(c)
 		if err != nil {
@@ -132,6 +223,44 @@ func (s *DatabaseServer) configureRoutes(routeGroup echoswagger.ApiGroup) {
 		SetOperationId("milestone").
 		AddParamPath("", ParameterMilestoneIndex, "the index of the milestone")
 
+	routeGroup.GET(RouteMilestoneTransactions, func(c echo.Context) error {
+		resp, err := s.milestoneTransactions(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	}).
+		SetDescription("the route for getting all transactions that were confirmed by a milestone").
+		SetOperationId("milestoneTransactions").
+		AddParamPath("", ParameterMilestoneIndex, "the index of the milestone").
+		AddParamQuery("", QueryParameterMaxResults, "limit the maximum number of results", false).
+		AddParamQuery("", QueryParameterCursor, "the cursor of a previous response to query the next results", false)
+
+	routeGroup.GET(RouteMilestoneByHash, func(c echo.Context) error {
+		resp, err := s.milestoneByHash(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	}).
+		SetDescription("the route for getting a milestone by the hash of its tail transaction").
+		SetOperationId("milestoneByHash").
+		AddParamPath("", ParameterMilestoneHash, "the hash of the milestone tail transaction")
+
+	routeGroup.GET(RouteMilestoneByTimestamp, func(c echo.Context) error {
+		resp, err := s.milestoneByTimestamp(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	}).
+		SetDescription("the route for getting the milestone that was in effect at the given unix timestamp").
+		SetOperationId("milestoneByTimestamp").
+		AddParamPath("", ParameterTimestamp, "the unix timestamp in seconds")
+
 	routeGroup.GET(RouteTransactions, func(c echo.Context) error {
 		resp, err := s.transactions(c)
 		if err != nil {
@@ -140,13 +269,14 @@ func (s *DatabaseServer) configureRoutes(routeGroup echoswagger.ApiGroup) {
 
 		return httpserver.JSONResponse(c, http.StatusOK, resp)
 	}).
-		SetDescription("the route for getting transactions filtered by the given parameters. Returns an empty list if no results are found.").
+		SetDescription("the route for getting transactions filtered by the given parameters. If several filters are given, the returned transactions match all of them. Returns an empty list if no results are found.").
 		SetOperationId("transactions").
 		AddParamQuery("", QueryParameterBundle, "filter for transactions with a specific bundle hash", false).
 		AddParamQuery("", QueryParameterAddress, "filter for transactions with a specific address", false).
-		AddParamQuery("", QueryParameterTag, "filter for transactions with a specific tag", false).
+		AddParamQuery("", QueryParameterTag, "filter for transactions with a specific tag, or with a tag prefix if it ends with \"*\"", false).
 		AddParamQuery("", QueryParameterApprovee, "filter for transactions with a specific approvee hash", false).
-		AddParamQuery("", QueryParameterMaxResults, "limit the maximum number of results", false)
+		AddParamQuery("", QueryParameterMaxResults, "limit the maximum number of results", false).
+		AddParamQuery("", QueryParameterCursor, "the cursor of a previous response to query the next results", false)
 
 	routeGroup.GET(RouteTransaction, func(c echo.Context) error {
 		resp, err := s.transaction(c)
@@ -184,6 +314,73 @@ func (s *DatabaseServer) configureRoutes(routeGroup echoswagger.ApiGroup) {
 		SetOperationId("transactionInclusionState").
 		AddParamPath("", ParameterTransactionHash, "the hash of the transaction")
 
+	routeGroup.GET(RouteTransactionApprovers, func(c echo.Context) error {
+		resp, err := s.transactionApprovers(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	}).
+		SetDescription("the route for getting the approvers of a transaction").
+		SetOperationId("transactionApprovers").
+		AddParamPath("", ParameterTransactionHash, "the hash of the transaction").
+		AddParamQuery("", QueryParameterMaxResults, "limit the maximum number of results", false).
+		AddParamQuery("", QueryParameterCursor, "the cursor of a previous response to query the next results", false)
+
+	routeGroup.GET(RouteTransactionApprovees, func(c echo.Context) error {
+		resp, err := s.transactionApprovees(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	}).
+		SetDescription("the route for getting the trunk and branch of a transaction").
+		SetOperationId("transactionApprovees").
+		AddParamPath("", ParameterTransactionHash, "the hash of the transaction")
+
+	routeGroup.GET(RouteTransactionCone, func(c echo.Context) error {
+		resp, err := s.transactionCone(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	}).
+		SetDescription("the route for traversing the past or future cone of a transaction in breadth-first order").
+		SetOperationId("transactionCone").
+		AddParamPath("", ParameterTransactionHash, "the hash of the transaction").
+		AddParamQuery("", QueryParameterDirection, "the direction of the traversal, \"past\" or \"future\" (default: past)", false).
+		AddParamQuery("", QueryParameterDepth, "the maximum distance to the transaction (default: unlimited)", false).
+		AddParamQuery("", QueryParameterMax, "limit the maximum number of transactions", false)
+
+	routeGroup.GET(RouteBundle, func(c echo.Context) error {
+		resp, err := s.bundle(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	}).
+		SetDescription("the route for getting a bundle by the hash of its tail transaction").
+		SetOperationId("bundle").
+		AddParamPath("", ParameterTailTxHash, "the hash of the tail transaction of the bundle")
+
+	routeGroup.GET(RouteBundleAttachments, func(c echo.Context) error {
+		resp, err := s.bundleAttachments(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	}).
+		SetDescription("the route for getting all attachments of a bundle hash").
+		SetOperationId("bundleAttachments").
+		AddParamPath("", ParameterBundleHash, "the hash of the bundle").
+		AddParamQuery("", QueryParameterMaxResults, "limit the maximum number of results", false).
+		AddParamQuery("", QueryParameterCursor, "the cursor of a previous response to query the next results", false)
+
 	routeGroup.GET(RouteAddressBalance, func(c echo.Context) error {
 		resp, err := s.addressBalance(c)
 		if err != nil {
@@ -194,7 +391,23 @@ func (s *DatabaseServer) configureRoutes(routeGroup echoswagger.ApiGroup) {
 	}).
 		SetDescription("the route for getting the balance of an address").
 		SetOperationId("addressBalance").
-		AddParamPath("", ParameterAddress, "the hash of the address")
+		AddParamPath("", ParameterAddress, "the hash of the address").
+		AddParamQuery("", QueryParameterLedgerIndex, "the ledger index at which the balance should be returned (default: latest ledger index)", false)
+
+	routeGroup.GET(RouteAddressHistory, func(c echo.Context) error {
+		resp, err := s.addressHistory(c)
+		if err != nil {
+			return err
+		}
+
+		return httpserver.JSONResponse(c, http.StatusOK, resp)
+	}).
+		SetDescription("the route for getting the balance history of an address").
+		SetOperationId("addressHistory").
+		AddParamPath("", ParameterAddress, "the hash of the address").
+		AddParamQuery("", QueryParameterStartIndex, "the oldest milestone index of the range (default: pruning index + 1)", false).
+		AddParamQuery("", QueryParameterEndIndex, "the newest milestone index of the range (default: latest ledger index)", false).
+		AddParamQuery("", QueryParameterMaxResults, "limit the maximum number of results (without the address milestones index: the maximum number of scanned milestones)", false)
 
 	routeGroup.GET(RouteAddressWasSpent, func(c echo.Context) error {
 		resp, err := s.addressWasSpent(c)
@@ -208,7 +421,22 @@ func (s *DatabaseServer) configureRoutes(routeGroup echoswagger.ApiGroup) {
 		SetOperationId("addressWasSpent").
 		AddParamPath("", ParameterAddress, "the hash of the address")
 
+	routeGroup.GET(RouteSpentAddressesFilter, func(c echo.Context) error {
+		filterJSON, err := s.spentAddressesFilterResponse(c)
+		if err != nil {
+			return err
+		}
+
+		return c.JSONBlob(http.StatusOK, filterJSON)
+	}).
+		SetDescription("the route for getting a Bloom filter over all spent addresses").
+		SetOperationId("spentAddressesFilter")
+
 	routeGroup.GET(RouteLedgerState, func(c echo.Context) error {
+		if acceptsNDJSON(c) {
+			return s.ledgerStateByLatestSolidIndexStream(c)
+		}
+
 		resp, err := s.ledgerStateByLatestSolidIndex(c)
 		if err != nil {
 			return err
@@ -216,10 +444,14 @@ func (s *DatabaseServer) configureRoutes(routeGroup echoswagger.ApiGroup) {
 
 		return httpserver.JSONResponse(c, http.StatusOK, resp)
 	}).
-		SetDescription("the route to return the current ledger state").
+		SetDescription("the route to return the current ledger state. Use \"Accept: application/x-ndjson\" to stream the results as newline-delimited JSON.").
 		SetOperationId("ledgerStateByLatestSolidIndex")
 
 	routeGroup.GET(RouteLedgerStateByIndex, func(c echo.Context) error {
+		if acceptsNDJSON(c) {
+			return s.ledgerStateByIndexStream(c)
+		}
+
 		resp, err := s.ledgerStateByIndex(c)
 		if err != nil {
 			return err
@@ -227,11 +459,15 @@ func (s *DatabaseServer) configureRoutes(routeGroup echoswagger.ApiGroup) {
 
 		return httpserver.JSONResponse(c, http.StatusOK, resp)
 	}).
-		SetDescription("the route to return the ledger state of a given ledger index").
+		SetDescription("the route to return the ledger state of a given ledger index. Use \"Accept: application/x-ndjson\" to stream the results as newline-delimited JSON.").
 		SetOperationId("ledgerStateByIndex").
 		AddParamPath("", ParameterMilestoneIndex, "the index of the milestone")
 
 	routeGroup.GET(RouteLedgerDiffByIndex, func(c echo.Context) error {
+		if acceptsNDJSON(c) {
+			return s.ledgerDiffStream(c)
+		}
+
 		resp, err := s.ledgerDiff(c)
 		if err != nil {
 			return err
@@ -239,7 +475,7 @@ func (s *DatabaseServer) configureRoutes(routeGroup echoswagger.ApiGroup) {
 
 		return httpserver.JSONResponse(c, http.StatusOK, resp)
 	}).
-		SetDescription("the route to return the ledger diff of a given ledger index").
+		SetDescription("the route to return the ledger diff of a given ledger index. Use \"Accept: application/x-ndjson\" to stream the results as newline-delimited JSON.").
 		SetOperationId("ledgerDiff").
 		AddParamPath("", ParameterMilestoneIndex, "the index of the milestone")
 
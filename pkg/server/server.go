@@ -1,10 +1,16 @@
 package server
 
 import (
+	"context"
+	"encoding/json"
+	"sync"
+
 	"github.com/labstack/echo/v4"
 	"github.com/pangpanglabs/echoswagger/v2"
 
 	"github.com/iotaledger/hive.go/app"
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-api-core-v0/pkg/bloom"
 	"github.com/iotaledger/inx-api-core-v0/pkg/database"
 )
 
@@ -13,18 +19,25 @@ const (
 )
 
 type DatabaseServer struct {
-	AppInfo                 *app.Info
-	Database                *database.Database
-	RestAPILimitsMaxResults int
-	RPCEndpoints            map[string]rpcEndpoint
+	AppInfo                     *app.Info
+	Database                    *database.Database
+	RestAPILimitsMaxResults     int
+	RestAPILimitsMaxScannedKeys int
+	RPCEndpoints                map[string]rpcEndpoint
+
+	// the JSON response of the spent addresses filter is encoded once, because the filter is big and never changes.
+	spentAddressesFilterJSON []byte
+	spentAddressesFilterLock sync.RWMutex
 }
 
-func NewDatabaseServer(swagger echoswagger.ApiRoot, appInfo *app.Info, db *database.Database, maxResults int) *DatabaseServer {
+func NewDatabaseServer(swagger echoswagger.ApiRoot, appInfo *app.Info, db *database.Database, maxResults int, maxScannedKeys int) *DatabaseServer {
 	s := &DatabaseServer{
-		AppInfo:                 appInfo,
-		Database:                db,
-		RestAPILimitsMaxResults: maxResults,
-		RPCEndpoints:            make(map[string]rpcEndpoint),
+		AppInfo:                     appInfo,
+		Database:                    db,
+		RestAPILimitsMaxResults:     maxResults,
+		RestAPILimitsMaxScannedKeys: maxScannedKeys,
+		RPCEndpoints:                make(map[string]rpcEndpoint),
+		spentAddressesFilterJSON:    nil,
 	}
 
 	s.configureRoutes(swagger.Group("root", APIRoute))
@@ -32,6 +45,37 @@ func NewDatabaseServer(swagger echoswagger.ApiRoot, appInfo *app.Info, db *datab
 	return s
 }
 
+// BuildSpentAddressesFilter builds the Bloom filter over all spent addresses that is served by the API.
+// The database never changes, so the filter only needs to be built once.
+func (s *DatabaseServer) BuildSpentAddressesFilter(ctx context.Context, falsePositiveRate float64) error {
+	filter, err := s.Database.SpentAddressesFilter(ctx, falsePositiveRate)
+	if err != nil {
+		return err
+	}
+
+	filterJSON, err := json.Marshal(&spentAddressesFilterResponse{
+		Type:              SpentAddressesFilterTypeBloom,
+		HashFunction:      bloom.HashFunction,
+		ElementEncoding:   database.SpentAddressesFilterElementEncoding,
+		BitsCount:         filter.BitsCount(),
+		HashesCount:       filter.HashesCount(),
+		ElementsCount:     filter.ElementsCount(),
+		FalsePositiveRate: filter.EstimatedFalsePositiveRate(),
+		Filter:            filter.Bytes(),
+		LedgerIndex:       s.Database.LedgerIndex(),
+	})
+	if err != nil {
+		return ierrors.Wrap(err, "failed to encode spent addresses filter")
+	}
+
+	s.spentAddressesFilterLock.Lock()
+	defer s.spentAddressesFilterLock.Unlock()
+
+	s.spentAddressesFilterJSON = filterJSON
+
+	return nil
+}
+
 func CreateEchoSwagger(e *echo.Echo, version string, enabled bool) echoswagger.ApiRoot {
 	if !enabled {
 		return echoswagger.NewNop(e)
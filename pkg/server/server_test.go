@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+)
+
+var (
+	testAddress  = trinary.Trytes(strings.Repeat("A", 81))
+	testTagFound = trinary.Trytes("FOUND9999999999999999999999")
+	testTagOther = trinary.Trytes("OTHER9999999999999999999999")
+	testNullHash = trinary.Hash(strings.Repeat("9", 81))
+)
+
+// newTestServer builds a small legacy database in memory and returns a server on top of it.
+// The database contains three zero-value transactions of testAddress, the first one is tagged with testTagFound,
+// the others with testTagOther. The hashes of the transactions are returned as well.
+func newTestServer(t *testing.T) (*DatabaseServer, hornet.Hashes) {
+	t.Helper()
+
+	return newTestServerWithBalances(t, nil)
+}
+
+// newTestServerWithBalances builds the database of newTestServer with the given ledger balances.
+// The balances are not checked against the total supply.
+func newTestServerWithBalances(t *testing.T, balances map[trinary.Hash]uint64) (*DatabaseServer, hornet.Hashes) {
+	t.Helper()
+
+	tangleStore := mapdb.NewMapDB()
+	snapshotStore := mapdb.NewMapDB()
+	spentStore := mapdb.NewMapDB()
+
+	builder, err := database.NewBuilder(tangleStore, snapshotStore, spentStore)
+	if err != nil {
+		t.Fatalf("failed to create builder: %v", err)
+	}
+
+	txHashes := make(hornet.Hashes, 0, 3)
+	for i, bundleHash := range []trinary.Hash{strings.Repeat("D", 81), strings.Repeat("E", 81), strings.Repeat("F", 81)} {
+		tag := testTagOther
+		if i == 0 {
+			tag = testTagFound
+		}
+
+		tx := &transaction.Transaction{
+			SignatureMessageFragment: strings.Repeat("9", 2187),
+			Address:                  testAddress,
+			ObsoleteTag:              tag,
+			Timestamp:                1_600_000_000,
+			Bundle:                   bundleHash,
+			TrunkTransaction:         testNullHash,
+			BranchTransaction:        testNullHash,
+			Tag:                      tag,
+			Nonce:                    strings.Repeat("9", 27),
+		}
+
+		txHash, err := builder.StoreTransaction(tx, database.TransactionState{ConfirmationIndex: 1})
+		if err != nil {
+			t.Fatalf("failed to store transaction: %v", err)
+		}
+		if _, err := builder.StoreBundle([]*transaction.Transaction{tx}, true); err != nil {
+			t.Fatalf("failed to store bundle: %v", err)
+		}
+
+		txHashes = append(txHashes, txHash)
+	}
+
+	solidEntryPoints := database.NewSolidEntryPoints()
+	solidEntryPoints.Add(hornet.HashFromHashTrytes(testNullHash), 0)
+
+	for _, step := range []func() error{
+		func() error { return builder.StoreLedgerIndex(1) },
+		func() error {
+			return builder.StoreSnapshotInfo(&database.SnapshotInfo{
+				CoordinatorAddress: hornet.HashFromAddressTrytes(strings.Repeat("C", 81)),
+				Hash:               hornet.HashFromHashTrytes(testNullHash),
+				SnapshotIndex:      1,
+				EntryPointIndex:    1,
+				PruningIndex:       0,
+				Timestamp:          1_600_000_000,
+			})
+		},
+		func() error { return builder.StoreSolidEntryPoints(solidEntryPoints) },
+	} {
+		if err := step(); err != nil {
+			t.Fatalf("failed to build database: %v", err)
+		}
+	}
+
+	for address, balance := range balances {
+		if err := builder.StoreLedgerBalance(hornet.HashFromAddressTrytes(address), balance); err != nil {
+			t.Fatalf("failed to store ledger balance: %v", err)
+		}
+	}
+
+	if err := builder.Flush(); err != nil {
+		t.Fatalf("failed to flush builder: %v", err)
+	}
+
+	db, err := database.NewWithStores(context.Background(), nil, tangleStore, snapshotStore, spentStore, false, database.CacheSizes{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	return &DatabaseServer{
+		Database:                    db,
+		RestAPILimitsMaxResults:     10,
+		RestAPILimitsMaxScannedKeys: 0,
+		RPCEndpoints:                make(map[string]rpcEndpoint),
+	}, txHashes
+}
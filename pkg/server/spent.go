@@ -10,6 +10,11 @@ import (
 	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
 )
 
+const (
+	// SpentAddressesFilterTypeBloom is the type of the spent addresses filter.
+	SpentAddressesFilterTypeBloom = "bloom"
+)
+
 func (s *DatabaseServer) rpcWereAddressesSpentFrom(c echo.Context) (interface{}, error) {
 	request := &WereAddressesSpentFrom{}
 	if err := c.Bind(request); err != nil {
@@ -28,7 +33,11 @@ func (s *DatabaseServer) rpcWereAddressesSpentFrom(c echo.Context) (interface{},
 		}
 
 		// State
-		result.States = append(result.States, s.Database.WasAddressSpentFrom(hornet.HashFromAddressTrytes(addr[:81])))
+		wasSpent, err := s.Database.WasAddressSpentFrom(hornet.HashFromAddressTrytes(addr[:81]))
+		if err != nil {
+			return nil, databaseError(err)
+		}
+		result.States = append(result.States, wasSpent)
 	}
 
 	return result, nil
@@ -40,9 +49,26 @@ func (s *DatabaseServer) addressWasSpent(c echo.Context) (interface{}, error) {
 		return nil, err
 	}
 
+	wasSpent, err := s.Database.WasAddressSpentFrom(addr)
+	if err != nil {
+		return nil, databaseError(err)
+	}
+
 	return &addressWasSpentResponse{
 		Address:     addr.Trytes(),
-		WasSpent:    s.Database.WasAddressSpentFrom(addr),
+		WasSpent:    wasSpent,
 		LedgerIndex: s.Database.LedgerIndex(),
 	}, nil
 }
+
+// spentAddressesFilterResponse returns the JSON encoded spent addresses filter.
+func (s *DatabaseServer) spentAddressesFilterResponse(_ echo.Context) ([]byte, error) {
+	s.spentAddressesFilterLock.RLock()
+	defer s.spentAddressesFilterLock.RUnlock()
+
+	if s.spentAddressesFilterJSON == nil {
+		return nil, ierrors.Wrap(echo.ErrServiceUnavailable, "spent addresses filter is not available yet")
+	}
+
+	return s.spentAddressesFilterJSON, nil
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/hive.go/ierrors"
+
+	"github.com/iotaledger/inx-api-core-v0/pkg/bloom"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
+)
+
+func TestSpentAddressesFilterResponse(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	if _, err := s.spentAddressesFilterResponse(nil); !ierrors.Is(err, echo.ErrServiceUnavailable) {
+		t.Fatalf("expected the filter to be unavailable before it is built, got: %v", err)
+	}
+
+	const falsePositiveRate = 0.01
+	if err := s.BuildSpentAddressesFilter(context.Background(), falsePositiveRate); err != nil {
+		t.Fatalf("failed to build spent addresses filter: %v", err)
+	}
+
+	filterJSON, err := s.spentAddressesFilterResponse(nil)
+	if err != nil {
+		t.Fatalf("failed to get spent addresses filter: %v", err)
+	}
+
+	var resp spentAddressesFilterResponse
+	if err := json.Unmarshal(filterJSON, &resp); err != nil {
+		t.Fatalf("failed to decode spent addresses filter: %v", err)
+	}
+
+	expected, err := s.Database.SpentAddressesFilter(context.Background(), falsePositiveRate)
+	if err != nil {
+		t.Fatalf("failed to build expected filter: %v", err)
+	}
+
+	if resp.Type != SpentAddressesFilterTypeBloom || resp.HashFunction != bloom.HashFunction {
+		t.Fatalf("unexpected filter type %q and hash function %q", resp.Type, resp.HashFunction)
+	}
+	if resp.ElementEncoding != database.SpentAddressesFilterElementEncoding {
+		t.Fatalf("expected element encoding %q, got %q", database.SpentAddressesFilterElementEncoding, resp.ElementEncoding)
+	}
+	if resp.BitsCount != expected.BitsCount() || resp.HashesCount != expected.HashesCount() || resp.ElementsCount != expected.ElementsCount() {
+		t.Fatalf("unexpected filter parameters: %+v", resp)
+	}
+	if !bytes.Equal(resp.Filter, expected.Bytes()) {
+		t.Fatal("filter bits do not match")
+	}
+	if resp.LedgerIndex != s.Database.LedgerIndex() {
+		t.Fatalf("expected ledger index %d, got %d", s.Database.LedgerIndex(), resp.LedgerIndex)
+	}
+
+	// the response is encoded once and served as is
+	cachedJSON, err := s.spentAddressesFilterResponse(nil)
+	if err != nil {
+		t.Fatalf("failed to get spent addresses filter: %v", err)
+	}
+	if &cachedJSON[0] != &filterJSON[0] {
+		t.Fatal("expected the encoded filter to be reused")
+	}
+}
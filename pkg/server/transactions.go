@@ -1,6 +1,7 @@
 package server
 
 import (
+	"encoding/base64"
 	"strings"
 
 	"github.com/labstack/echo/v4"
@@ -9,117 +10,51 @@ import (
 	"github.com/iotaledger/inx-app/pkg/httpserver"
 	"github.com/iotaledger/iota.go/address"
 	"github.com/iotaledger/iota.go/guards"
-	"github.com/iotaledger/iota.go/trinary"
 
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
 	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
 )
 
-func (s *DatabaseServer) findTransactions(maxResults int, valueOnly bool, queryBundleHashes, queryApproveeHashes, queryAddressHashes, queryTagHashes map[string]struct{}) []string {
+// findTransactions returns the hashes of the transactions that match the query (see planTransactionsQuery for the semantics of the filters).
+func (s *DatabaseServer) findTransactions(c echo.Context, query *database.TransactionsQuery, maxResults int, cursor string) ([]string, string, error) {
 
-	results := make(map[string]struct{})
-	searchedBefore := false
-
-	// check if bundle hash search criteria was given
-	if len(queryBundleHashes) > 0 {
-		// search txs by bundle hash
-		for bundleHash := range queryBundleHashes {
-			for _, r := range s.Database.BundleTransactionHashes(hornet.Hash(bundleHash), maxResults-len(results)) {
-				results[string(r)] = struct{}{}
-			}
+	var cursorBytes []byte
+	if len(cursor) > 0 {
+		var err error
+		if cursorBytes, err = base64.RawURLEncoding.DecodeString(cursor); err != nil {
+			return nil, "", ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid cursor provided: %s", cursor)
 		}
-		searchedBefore = true
 	}
 
-	// check if approvee search criteria was given
-	if len(queryApproveeHashes) > 0 {
-		if !searchedBefore {
-			// search txs by approvees
-			for approveeHash := range queryApproveeHashes {
-				for _, r := range s.Database.ApproverHashes(hornet.Hash(approveeHash), maxResults-len(results)) {
-					results[string(r)] = struct{}{}
-				}
-			}
-			searchedBefore = true
-		} else {
-			// check if results match at least one of the approvee search criteria
-			for txHash := range results {
-				contains := false
-				for approveeHash := range queryApproveeHashes {
-					if s.Database.ContainsApprover(hornet.Hash(approveeHash), hornet.Hash(txHash)) {
-						contains = true
-
-						break
-					}
-				}
-				if !contains {
-					delete(results, txHash)
-				}
-			}
+	searchIndex, err := s.planTransactionsQuery(c.Request().Context(), query, cursorBytes, maxResults)
+	if err != nil {
+		if ierrors.Is(err, database.ErrInvalidCursor) || ierrors.Is(err, database.ErrNoSearchCriteria) {
+			return nil, "", ierrors.Wrap(httpserver.ErrInvalidParameter, err.Error())
 		}
-	}
 
-	// check if address search criteria was given
-	if len(queryAddressHashes) > 0 {
-		if !searchedBefore {
-			// search txs by address
-			for addressHash := range queryAddressHashes {
-				for _, r := range s.Database.TransactionHashesForAddress(hornet.Hash(addressHash), valueOnly, maxResults-len(results)) {
-					results[string(r)] = struct{}{}
-				}
-			}
-			searchedBefore = true
-		} else {
-			// check if results match at least one of the address search criteria
-			for txHash := range results {
-				contains := false
-				for addressHash := range queryAddressHashes {
-					if s.Database.ContainsAddress(hornet.Hash(addressHash), hornet.Hash(txHash), valueOnly) {
-						contains = true
-
-						break
-					}
-				}
-				if !contains {
-					delete(results, txHash)
-				}
-			}
-		}
+		return nil, "", databaseError(err)
 	}
 
-	// check if tag search criteria was given
-	if len(queryTagHashes) > 0 {
-		if !searchedBefore {
-			// search txs by tags
-			for tagHash := range queryTagHashes {
-				for _, r := range s.Database.TagHashes(hornet.Hash(tagHash), maxResults-len(results)) {
-					results[string(r)] = struct{}{}
-				}
-			}
-		} else {
-			// check if results match at least one of the tag search criteria
-			for txHash := range results {
-				contains := false
-				for tagHash := range queryTagHashes {
-					if s.Database.ContainsTag(hornet.Hash(tagHash), hornet.Hash(txHash)) {
-						contains = true
-
-						break
-					}
-				}
-				if !contains {
-					delete(results, txHash)
-				}
-			}
+	results, nextCursor, err := s.Database.FindTransactions(c.Request().Context(), query, searchIndex, cursorBytes, maxResults, s.RestAPILimitsMaxScannedKeys)
+	if err != nil {
+		if ierrors.Is(err, database.ErrInvalidCursor) || ierrors.Is(err, database.ErrNoSearchCriteria) {
+			return nil, "", ierrors.Wrap(httpserver.ErrInvalidParameter, err.Error())
 		}
+
+		return nil, "", databaseError(err)
 	}
 
 	// convert to slice
 	txHashes := make([]string, 0, len(results))
-	for r := range results {
-		txHashes = append(txHashes, hornet.Hash(r).Trytes())
+	for _, r := range results {
+		txHashes = append(txHashes, r.Trytes())
+	}
+
+	if nextCursor == nil {
+		return txHashes, "", nil
 	}
 
-	return txHashes
+	return txHashes, base64.RawURLEncoding.EncodeToString(nextCursor), nil
 }
 
 func (s *DatabaseServer) rpcFindTransactions(c echo.Context) (interface{}, error) {
@@ -137,50 +72,48 @@ func (s *DatabaseServer) rpcFindTransactions(c echo.Context) (interface{}, error
 		return nil, ierrors.Wrap(httpserver.ErrInvalidParameter, "no search criteria was given")
 	}
 
-	queryBundleHashes := make(map[string]struct{})
-	queryApproveeHashes := make(map[string]struct{})
-	queryAddressHashes := make(map[string]struct{})
-	queryTagHashes := make(map[string]struct{})
+	query := &database.TransactionsQuery{
+		ValueOnly: request.ValueOnly,
+	}
 
 	// check all queries first
 	for _, bundleTrytes := range request.Bundles {
 		if !guards.IsTransactionHash(bundleTrytes) {
 			return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid bundle hash provided: %s", bundleTrytes)
 		}
-		queryBundleHashes[string(hornet.HashFromHashTrytes(bundleTrytes))] = struct{}{}
+		query.BundleHashes = append(query.BundleHashes, hornet.HashFromHashTrytes(bundleTrytes))
 	}
 
 	for _, approveeTrytes := range request.Approvees {
 		if !guards.IsTransactionHash(approveeTrytes) {
 			return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid aprovee hash provided: %s", approveeTrytes)
 		}
-		queryApproveeHashes[string(hornet.HashFromHashTrytes(approveeTrytes))] = struct{}{}
+		query.ApproveeHashes = append(query.ApproveeHashes, hornet.HashFromHashTrytes(approveeTrytes))
 	}
 
 	for _, addressTrytes := range request.Addresses {
 		if err := address.ValidAddress(addressTrytes); err != nil {
 			return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid address hash provided: %s", addressTrytes)
 		}
-		queryAddressHashes[string(hornet.HashFromAddressTrytes(addressTrytes[:81]))] = struct{}{}
+		query.Addresses = append(query.Addresses, hornet.HashFromAddressTrytes(addressTrytes[:81]))
 	}
 
 	for _, tagTrytes := range request.Tags {
-		if err := trinary.ValidTrytes(tagTrytes); err != nil {
-			return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid tag trytes provided: %s", tagTrytes)
-		}
-		if len(tagTrytes) > 27 {
-			return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid tag length: %s", tagTrytes)
-		}
-		if len(tagTrytes) < 27 {
-			tagTrytes = trinary.MustPad(tagTrytes, 27)
+		tags, err := parseTag(tagTrytes)
+		if err != nil {
+			return nil, err
 		}
-		queryTagHashes[string(hornet.HashFromTagTrytes(tagTrytes))] = struct{}{}
+		query.Tags = append(query.Tags, tags...)
 	}
 
-	txHashes := s.findTransactions(maxResults, request.ValueOnly, queryBundleHashes, queryApproveeHashes, queryAddressHashes, queryTagHashes)
+	txHashes, cursor, err := s.findTransactions(c, query, maxResults, request.Cursor)
+	if err != nil {
+		return nil, err
+	}
 
 	return &FindTransactionsResponse{
 		Hashes: txHashes,
+		Cursor: cursor,
 	}, nil
 }
 
@@ -211,34 +144,36 @@ func (s *DatabaseServer) transactions(c echo.Context) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	requestTagHash, err := parseTagQueryParam(c)
+	requestTagHashes, err := parseTagQueryParam(c)
 	if err != nil {
 		return nil, err
 	}
 
-	if requestBundleHash == nil && requestApproveeHash == nil && requestAddressHash == nil && requestTagHash == nil {
+	if requestBundleHash == nil && requestApproveeHash == nil && requestAddressHash == nil && requestTagHashes == nil {
 		return nil, ierrors.Wrap(httpserver.ErrInvalidParameter, "no search criteria was given")
 	}
 
-	queryBundleHashes := make(map[string]struct{})
-	queryApproveeHashes := make(map[string]struct{})
-	queryAddressHashes := make(map[string]struct{})
-	queryTagHashes := make(map[string]struct{})
+	query := &database.TransactionsQuery{
+		ValueOnly: valueOnly,
+	}
 
 	if requestBundleHash != nil {
-		queryBundleHashes[string(requestBundleHash)] = struct{}{}
+		query.BundleHashes = hornet.Hashes{requestBundleHash}
 	}
 	if requestApproveeHash != nil {
-		queryApproveeHashes[string(requestApproveeHash)] = struct{}{}
+		query.ApproveeHashes = hornet.Hashes{requestApproveeHash}
 	}
 	if requestAddressHash != nil {
-		queryAddressHashes[string(requestAddressHash)] = struct{}{}
+		query.Addresses = hornet.Hashes{requestAddressHash}
 	}
-	if requestTagHash != nil {
-		queryTagHashes[string(requestTagHash)] = struct{}{}
+	if requestTagHashes != nil {
+		query.Tags = requestTagHashes
 	}
 
-	txHashes := s.findTransactions(maxResults, valueOnly, queryBundleHashes, queryApproveeHashes, queryAddressHashes, queryTagHashes)
+	txHashes, cursor, err := s.findTransactions(c, query, maxResults, c.QueryParam(QueryParameterCursor))
+	if err != nil {
+		return nil, err
+	}
 
 	return &transactionsResponse{
 		Bundle: func() string {
@@ -263,13 +198,19 @@ func (s *DatabaseServer) transactions(c echo.Context) (interface{}, error) {
 			return ""
 		}(),
 		Tag: func() string {
-			if requestTagHash != nil {
-				return requestTagHash.Trytes()
+			if requestTagHashes == nil {
+				return ""
 			}
 
-			return ""
+			if requestTag := strings.ToUpper(c.QueryParam(QueryParameterTag)); strings.HasSuffix(requestTag, TagPrefixWildcard) {
+				// tag prefix search
+				return requestTag
+			}
+
+			return requestTagHashes[0].Trytes()
 		}(),
 		TransactionHashes: txHashes,
+		Cursor:            cursor,
 		LedgerIndex:       s.Database.LedgerIndex(),
 	}, nil
 }
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-app/pkg/httpserver"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+)
+
+func TestPlanTransactionsQuery(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	address := hornet.HashFromAddressTrytes(testAddress)
+	tagFound := hornet.HashFromTagTrytes(testTagFound)
+	tagOther := hornet.HashFromTagTrytes(testTagOther)
+	tagUnknown := hornet.HashFromTagTrytes(trinary.MustPad("UNKNOWN", 27))
+
+	tests := []struct {
+		name       string
+		query      *database.TransactionsQuery
+		maxResults int
+		expected   database.TransactionsIndex
+	}{
+		{
+			name:       "single filter",
+			query:      &database.TransactionsQuery{Addresses: hornet.Hashes{address}},
+			maxResults: 10,
+			expected:   database.TransactionsIndexAddress,
+		},
+		{
+			name:       "most selective filter",
+			query:      &database.TransactionsQuery{Addresses: hornet.Hashes{address}, Tags: hornet.Hashes{tagFound}},
+			maxResults: 10,
+			expected:   database.TransactionsIndexTag,
+		},
+		{
+			name:       "filter without entries",
+			query:      &database.TransactionsQuery{Addresses: hornet.Hashes{address}, Tags: hornet.Hashes{tagUnknown}},
+			maxResults: 10,
+			expected:   database.TransactionsIndexTag,
+		},
+		{
+			// the address (3 entries) and the tag (2 entries) both exceed the sample of maxResults+1 entries
+			name:       "all filters exceed the sample",
+			query:      &database.TransactionsQuery{Addresses: hornet.Hashes{address}, Tags: hornet.Hashes{tagOther}},
+			maxResults: 1,
+			expected:   database.TransactionsIndexAddress,
+		},
+	}
+
+	for _, test := range tests {
+		index, err := s.planTransactionsQuery(context.Background(), test.query, nil, test.maxResults)
+		if err != nil {
+			t.Fatalf("%s: failed to plan query: %v", test.name, err)
+		}
+
+		if index != test.expected {
+			t.Fatalf("%s: expected index %d, got %d", test.name, test.expected, index)
+		}
+	}
+
+	if _, err := s.planTransactionsQuery(context.Background(), &database.TransactionsQuery{}, nil, 10); !ierrors.Is(err, database.ErrNoSearchCriteria) {
+		t.Fatalf("expected ErrNoSearchCriteria, got %v", err)
+	}
+
+	// a cursor continues the search in the index of the previous page,
+	// even if another index would be more selective.
+	query := &database.TransactionsQuery{Addresses: hornet.Hashes{address}}
+	_, cursor, err := s.Database.FindTransactions(context.Background(), query, database.TransactionsIndexAddress, nil, 1, 0)
+	if err != nil || cursor == nil {
+		t.Fatalf("expected a cursor for the first page: %v", err)
+	}
+
+	query.Tags = hornet.Hashes{tagFound}
+	if index, err := s.planTransactionsQuery(context.Background(), query, cursor, 10); err != nil || index != database.TransactionsIndexAddress {
+		t.Fatalf("expected the index of the cursor, got %d (%v)", index, err)
+	}
+}
+
+func TestFindTransactionsCursor(t *testing.T) {
+	s, txHashes := newTestServer(t)
+
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	query := &database.TransactionsQuery{Addresses: hornet.Hashes{hornet.HashFromAddressTrytes(testAddress)}}
+
+	results := make(map[string]int)
+	var firstCursor string
+	var cursor string
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatalf("cursor did not terminate")
+		}
+
+		hashes, nextCursor, err := s.findTransactions(c, query, 1, cursor)
+		if err != nil {
+			t.Fatalf("failed to find transactions: %v", err)
+		}
+
+		for _, hash := range hashes {
+			results[hash]++
+		}
+
+		if nextCursor == "" {
+			break
+		}
+
+		// the cursor is transported as URL safe base64 without padding
+		if _, err := base64.RawURLEncoding.DecodeString(nextCursor); err != nil {
+			t.Fatalf("invalid cursor encoding %q: %v", nextCursor, err)
+		}
+
+		if firstCursor == "" {
+			firstCursor = nextCursor
+		}
+		cursor = nextCursor
+	}
+
+	if len(results) != len(txHashes) {
+		t.Fatalf("expected %d transactions, got %d", len(txHashes), len(results))
+	}
+	for _, txHash := range txHashes {
+		if results[txHash.Trytes()] != 1 {
+			t.Fatalf("expected transaction %s to be returned once, got %d", txHash.Trytes(), results[txHash.Trytes()])
+		}
+	}
+
+	// cursors of another query, truncated cursors and malformed cursors are rejected as invalid parameters
+	for name, test := range map[string]struct {
+		query  *database.TransactionsQuery
+		cursor string
+	}{
+		"value only": {
+			query:  &database.TransactionsQuery{Addresses: query.Addresses, ValueOnly: true},
+			cursor: firstCursor,
+		},
+		"additional filter": {
+			query:  &database.TransactionsQuery{Addresses: query.Addresses, Tags: hornet.Hashes{hornet.HashFromTagTrytes(testTagOther)}},
+			cursor: firstCursor,
+		},
+		"truncated": {
+			query:  query,
+			cursor: base64.RawURLEncoding.EncodeToString([]byte{byte(database.TransactionsIndexAddress)}),
+		},
+		"malformed": {
+			query:  query,
+			cursor: "not base64!",
+		},
+	} {
+		if _, _, err := s.findTransactions(c, test.query, 1, test.cursor); !ierrors.Is(err, httpserver.ErrInvalidParameter) {
+			t.Fatalf("%s: expected ErrInvalidParameter, got %v", name, err)
+		}
+	}
+}
@@ -34,7 +34,10 @@ func (s *DatabaseServer) rpcGetTrytes(c echo.Context) (interface{}, error) {
 	}
 
 	for _, hash := range request.Hashes {
-		tx := s.Database.TransactionOrNil(hornet.HashFromHashTrytes(hash))
+		tx, err := s.Database.TransactionOrNil(hornet.HashFromHashTrytes(hash))
+		if err != nil {
+			return nil, databaseError(err)
+		}
 		if tx == nil {
 			trytes = append(trytes, strings.Repeat("9", 2673))
 			milestones = append(milestones, uint32(0))
@@ -49,7 +52,10 @@ func (s *DatabaseServer) rpcGetTrytes(c echo.Context) (interface{}, error) {
 
 		trytes = append(trytes, txTrytes)
 
-		txMetadata := s.Database.TxMetadataOrNil(hornet.HashFromHashTrytes(hash))
+		txMetadata, err := s.Database.TxMetadataOrNil(hornet.HashFromHashTrytes(hash))
+		if err != nil {
+			return nil, databaseError(err)
+		}
 		if txMetadata == nil {
 			return nil, ierrors.Wrapf(echo.ErrInternalServerError, "metadata not found for hash: %s", hash)
 		}
@@ -72,7 +78,10 @@ func (s *DatabaseServer) transaction(c echo.Context) (interface{}, error) {
 		return nil, err
 	}
 
-	tx := s.Database.TransactionOrNil(txHash)
+	tx, err := s.Database.TransactionOrNil(txHash)
+	if err != nil {
+		return nil, databaseError(err)
+	}
 	if tx == nil {
 		return nil, ierrors.Wrapf(echo.ErrNotFound, "transaction not found: %s", txHash.Trytes())
 	}
@@ -86,7 +95,10 @@ func (s *DatabaseServer) transactionTrytes(c echo.Context) (interface{}, error)
 		return nil, err
 	}
 
-	tx := s.Database.TransactionOrNil(txHash)
+	tx, err := s.Database.TransactionOrNil(txHash)
+	if err != nil {
+		return nil, databaseError(err)
+	}
 	if tx == nil {
 		return nil, ierrors.Wrapf(echo.ErrNotFound, "transaction not found: %s", txHash.Trytes())
 	}
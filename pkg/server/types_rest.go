@@ -32,6 +32,32 @@ type milestoneResponse struct {
 	MilestoneTimestamp uint64          `json:"milestoneTimestamp"` // The milestone timestamp this transaction was referenced.
 }
 
+// milestonesResponse struct.
+type milestonesResponse struct {
+	Milestones []*milestoneResponse `json:"milestones"`
+	// The index to use as "from" to query the next milestones, if there are more results.
+	Next        milestone.Index `json:"next,omitempty"`
+	LedgerIndex milestone.Index `json:"ledgerIndex"`
+}
+
+// milestoneTransaction struct.
+type milestoneTransaction struct {
+	TxHash      trinary.Hash `json:"txHash"`
+	Bundle      trinary.Hash `json:"bundle"`
+	IsTail      bool         `json:"isTail"`
+	IsHead      bool         `json:"isHead"`
+	IsValue     bool         `json:"isValue"`
+	Conflicting bool         `json:"conflicting"`
+}
+
+// milestoneTransactionsResponse struct.
+type milestoneTransactionsResponse struct {
+	MilestoneIndex milestone.Index         `json:"milestoneIndex"`
+	Transactions   []*milestoneTransaction `json:"transactions"`
+	Cursor         string                  `json:"cursor,omitempty"`
+	LedgerIndex    milestone.Index         `json:"ledgerIndex"`
+}
+
 // transactionsResponse struct.
 type transactionsResponse struct {
 	Bundle            trinary.Hash    `json:"bundle,omitempty"`
@@ -39,6 +65,7 @@ type transactionsResponse struct {
 	Tag               trinary.Hash    `json:"tag,omitempty"`
 	Approvee          trinary.Hash    `json:"approvee,omitempty"`
 	TransactionHashes []trinary.Hash  `json:"txHashes"`
+	Cursor            string          `json:"cursor,omitempty"`
 	LedgerIndex       milestone.Index `json:"ledgerIndex"`
 }
 
@@ -61,6 +88,131 @@ type transactionMetadataResponse struct {
 	LedgerIndex                  milestone.Index `json:"ledgerIndex"`
 }
 
+// approversResponse struct.
+type approversResponse struct {
+	TxHash    trinary.Hash   `json:"txHash"`
+	Approvers []trinary.Hash `json:"approvers"`
+	// The cursor to query the next approvers, if there are more results.
+	Cursor      string          `json:"cursor,omitempty"`
+	LedgerIndex milestone.Index `json:"ledgerIndex"`
+}
+
+// approveesResponse struct.
+type approveesResponse struct {
+	TxHash       trinary.Hash    `json:"txHash"`
+	TrunkTxHash  trinary.Hash    `json:"trunkTxHash"`
+	BranchTxHash trinary.Hash    `json:"branchTxHash"`
+	LedgerIndex  milestone.Index `json:"ledgerIndex"`
+}
+
+// coneNode struct.
+type coneNode struct {
+	TxHash trinary.Hash `json:"txHash"`
+	Depth  int          `json:"depth"`
+	// Unknown transactions are not part of the database, e.g. because they were pruned.
+	Known                      bool            `json:"isKnown"`
+	SolidEntryPoint            bool            `json:"isSolidEntryPoint"`
+	Solid                      bool            `json:"isSolid"`
+	Confirmed                  bool            `json:"confirmed"`
+	Conflicting                bool            `json:"conflicting"`
+	ReferencedByMilestoneIndex milestone.Index `json:"referencedByMilestoneIndex,omitempty"`
+	MilestoneIndex             milestone.Index `json:"milestoneIndex,omitempty"`
+}
+
+// coneEdge struct.
+type coneEdge struct {
+	Approver trinary.Hash `json:"approver"`
+	Approvee trinary.Hash `json:"approvee"`
+	IsTrunk  bool         `json:"isTrunk"`
+}
+
+// coneResponse struct.
+type coneResponse struct {
+	TxHash    trinary.Hash `json:"txHash"`
+	Direction string       `json:"direction"`
+	Nodes     []*coneNode  `json:"nodes"`
+	Edges     []*coneEdge  `json:"edges"`
+	// Truncated is true if there are more transactions in the cone than "max".
+	Truncated   bool            `json:"truncated"`
+	LedgerIndex milestone.Index `json:"ledgerIndex"`
+}
+
+// bundleTransaction struct.
+type bundleTransaction struct {
+	TxHash  trinary.Hash `json:"txHash"`
+	Address trinary.Hash `json:"address"`
+	// The position of the transaction in the bundle.
+	Index uint32 `json:"index"`
+	// The value of the transaction in IOTA, encoded as string to prevent precision loss.
+	Value        string         `json:"value"`
+	Tag          trinary.Trytes `json:"tag"`
+	Timestamp    uint64         `json:"timestamp"`
+	TrunkTxHash  trinary.Hash   `json:"trunkTxHash"`
+	BranchTxHash trinary.Hash   `json:"branchTxHash"`
+}
+
+// bundleResponse struct.
+//
+// The metadata flags of the bundle:
+//   - isSolid: all transactions of the bundle and their past cone are known.
+//   - isValid: the bundle passed the syntactic validation (signatures, values, bundle hash).
+//   - isConfirmed: the bundle was confirmed by a milestone (see referencedByMilestoneIndex).
+//   - isConflicting: the bundle was confirmed, but its ledger changes were not applied because of a double spend.
+//   - isMilestone: the bundle is a milestone of the coordinator (see milestoneIndex).
+//   - isValueSpam: the bundle is valid, but doesn't change the ledger.
+//   - isValidStrictSemantics: the bundle also passed the stricter semantic validation of the node.
+//   - isInvalidPastCone: the past cone of the bundle contains invalid bundles.
+type bundleResponse struct {
+	Bundle       trinary.Hash         `json:"bundle"`
+	TailTxHash   trinary.Hash         `json:"tailTxHash"`
+	HeadTxHash   trinary.Hash         `json:"headTxHash"`
+	LastIndex    uint64               `json:"lastIndex"`
+	Transactions []*bundleTransaction `json:"transactions"`
+	// The metadata of the bundle.
+	IsSolid                bool `json:"isSolid"`
+	IsValid                bool `json:"isValid"`
+	IsConfirmed            bool `json:"isConfirmed"`
+	IsConflicting          bool `json:"isConflicting"`
+	IsMilestone            bool `json:"isMilestone"`
+	IsValueSpam            bool `json:"isValueSpam"`
+	IsValidStrictSemantics bool `json:"isValidStrictSemantics"`
+	IsInvalidPastCone      bool `json:"isInvalidPastCone"`
+	// The milestone that confirmed the bundle.
+	ReferencedByMilestoneIndex   milestone.Index `json:"referencedByMilestoneIndex,omitempty"`
+	MilestoneTimestampReferenced uint64          `json:"milestoneTimestampReferenced,omitempty"`
+	// If this bundle represents a milestone this is the milestone index.
+	MilestoneIndex milestone.Index         `json:"milestoneIndex,omitempty"`
+	LedgerChanges  map[trinary.Hash]string `json:"ledgerChanges"`
+	LedgerIndex    milestone.Index         `json:"ledgerIndex"`
+}
+
+// bundleAttachment struct.
+// The metadata flags have the same meaning as in the bundleResponse.
+type bundleAttachment struct {
+	TailTxHash trinary.Hash `json:"tailTxHash"`
+	// Whether the bundle of this attachment is complete and stored in the database.
+	IsSolid                      bool            `json:"isSolid"`
+	IsValid                      bool            `json:"isValid"`
+	IsConfirmed                  bool            `json:"isConfirmed"`
+	IsConflicting                bool            `json:"isConflicting"`
+	ReferencedByMilestoneIndex   milestone.Index `json:"referencedByMilestoneIndex,omitempty"`
+	MilestoneTimestampReferenced uint64          `json:"milestoneTimestampReferenced,omitempty"`
+}
+
+// bundleAttachmentsResponse struct.
+type bundleAttachmentsResponse struct {
+	Bundle      trinary.Hash        `json:"bundle"`
+	Attachments []*bundleAttachment `json:"attachments"`
+	// The tail transaction of the attachment that was confirmed without conflicts by the oldest milestone,
+	// over all attachments of the bundle (zero-value bundles can be confirmed more than once).
+	// It is set on every page, even if the confirmed attachment is not part of the returned page.
+	ConfirmedTailTxHash        trinary.Hash    `json:"confirmedTailTxHash,omitempty"`
+	ReferencedByMilestoneIndex milestone.Index `json:"referencedByMilestoneIndex,omitempty"`
+	// The cursor to query the next attachments, if there are more results.
+	Cursor      string          `json:"cursor,omitempty"`
+	LedgerIndex milestone.Index `json:"ledgerIndex"`
+}
+
 // addressWasSpentResponse struct.
 type addressWasSpentResponse struct {
 	Address     trinary.Hash    `json:"address"`
@@ -68,6 +220,28 @@ type addressWasSpentResponse struct {
 	LedgerIndex milestone.Index `json:"ledgerIndex"`
 }
 
+// spentAddressesFilterResponse struct.
+type spentAddressesFilterResponse struct {
+	// Type is the type of the filter.
+	Type string `json:"type"`
+	// HashFunction describes how the bit positions of an address are derived.
+	HashFunction string `json:"hashFunction"`
+	// ElementEncoding describes how an address is encoded before it is hashed.
+	ElementEncoding string `json:"elementEncoding"`
+	// BitsCount is the size of the filter in bits.
+	BitsCount uint64 `json:"bitsCount"`
+	// HashesCount is the amount of bit positions per address.
+	HashesCount uint8 `json:"hashesCount"`
+	// ElementsCount is the amount of spent addresses in the filter.
+	ElementsCount uint64 `json:"elementsCount"`
+	// FalsePositiveRate is the estimated false positive rate of the filter.
+	FalsePositiveRate float64 `json:"falsePositiveRate"`
+	// Filter are the base64 encoded bits of the filter.
+	Filter []byte `json:"filter"`
+	// LedgerIndex is the ledger index of the database.
+	LedgerIndex milestone.Index `json:"ledgerIndex"`
+}
+
 // balanceResponse struct.
 type balanceResponse struct {
 	Address     trinary.Hash    `json:"address"`
@@ -75,18 +249,57 @@ type balanceResponse struct {
 	LedgerIndex milestone.Index `json:"ledgerIndex"`
 }
 
+// addressHistoryEntry struct.
+type addressHistoryEntry struct {
+	MilestoneIndex     milestone.Index `json:"milestoneIndex"`
+	MilestoneTimestamp uint64          `json:"milestoneTimestamp"`
+	Change             string          `json:"change"`
+	Balance            string          `json:"balance"`
+	TailTxHashes       []trinary.Hash  `json:"tailTxHashes"`
+}
+
+// addressHistoryResponse struct.
+type addressHistoryResponse struct {
+	Address trinary.Hash           `json:"address"`
+	History []*addressHistoryEntry `json:"history"`
+	// The range of milestones that was requested.
+	StartIndex milestone.Index `json:"startIndex"`
+	EndIndex   milestone.Index `json:"endIndex"`
+	// The endIndex that should be used to query the next page, if there are more results
+	// or older milestones of the range were not scanned yet.
+	NextEndIndex milestone.Index `json:"nextEndIndex,omitempty"`
+	LedgerIndex  milestone.Index `json:"ledgerIndex"`
+}
+
 // ledgerStateResponse struct.
 type ledgerStateResponse struct {
 	Balances    map[trinary.Hash]string `json:"balances"`
 	LedgerIndex milestone.Index         `json:"ledgerIndex"`
 }
 
+// ledgerStateRecord struct.
+type ledgerStateRecord struct {
+	Address trinary.Hash `json:"address"`
+	Balance string       `json:"balance"`
+}
+
+// streamErrorRecord is the last record of a streamed response that failed after the streaming started.
+type streamErrorRecord struct {
+	Error string `json:"error"`
+}
+
 // ledgerDiffResponse struct.
 type ledgerDiffResponse struct {
 	AddressDiffs map[trinary.Hash]string `json:"addressDiffs"`
 	LedgerIndex  milestone.Index         `json:"ledgerIndex"`
 }
 
+// ledgerDiffRecord struct.
+type ledgerDiffRecord struct {
+	Address trinary.Hash `json:"address"`
+	Diff    string       `json:"diff"`
+}
+
 // txHashWithValue struct.
 type txHashWithValue struct {
 	TxHash     trinary.Hash `json:"txHash"`
@@ -48,11 +48,13 @@ type FindTransactions struct {
 	Approvees  []trinary.Hash `json:"approvees"`
 	MaxResults int            `json:"maxresults"`
 	ValueOnly  bool           `json:"valueOnly"`
+	Cursor     string         `json:"cursor,omitempty"`
 }
 
 // FindTransactionsResponse struct.
 type FindTransactionsResponse struct {
 	Hashes   []trinary.Hash `json:"hashes"`
+	Cursor   string         `json:"cursor,omitempty"`
 	Duration int            `json:"duration"`
 }
 
@@ -87,7 +89,8 @@ type GetInclusionStatesResponse struct {
 
 // GetBalances struct.
 type GetBalances struct {
-	Addresses []trinary.Hash `json:"addresses"`
+	Addresses   []trinary.Hash  `json:"addresses"`
+	LedgerIndex milestone.Index `json:"ledgerIndex,omitempty"`
 }
 
 // GetBalancesResponse struct.
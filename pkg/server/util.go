@@ -9,13 +9,31 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
 	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
 	"github.com/iotaledger/inx-app/pkg/httpserver"
 	"github.com/iotaledger/iota.go/address"
 	"github.com/iotaledger/iota.go/guards"
 	"github.com/iotaledger/iota.go/trinary"
 )
 
+// databaseError maps the errors of the database to the HTTP errors of the API.
+// The RPC endpoint uses the status codes of the HTTP errors as well.
+func databaseError(err error) error {
+	switch {
+	case ierrors.Is(err, database.ErrNotFound):
+		return ierrors.Wrap(echo.ErrNotFound, err.Error())
+	case ierrors.Is(err, database.ErrOutOfRange):
+		return ierrors.Wrap(httpserver.ErrInvalidParameter, err.Error())
+	case ierrors.Is(err, database.ErrOperationAborted):
+		return ierrors.Wrap(echo.ErrServiceUnavailable, err.Error())
+	default:
+		// corrupted data and storage errors
+		return ierrors.Wrap(echo.ErrInternalServerError, err.Error())
+	}
+}
+
 func restoreBody(c echo.Context, bodyBytes []byte) {
 	// Restore the io.ReadCloser to its original state
 	c.Request().Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
@@ -42,6 +60,26 @@ func parseTransactionHashParam(c echo.Context) (hornet.Hash, error) {
 	return hornet.HashFromHashTrytes(txHash), nil
 }
 
+func parseTailTxHashParam(c echo.Context) (hornet.Hash, error) {
+	tailTxHash := strings.ToUpper(c.Param(ParameterTailTxHash))
+
+	if !guards.IsTransactionHash(tailTxHash) {
+		return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid tail transaction hash provided: %s", tailTxHash)
+	}
+
+	return hornet.HashFromHashTrytes(tailTxHash), nil
+}
+
+func parseBundleHashParam(c echo.Context) (hornet.Hash, error) {
+	bundleHash := strings.ToUpper(c.Param(ParameterBundleHash))
+
+	if !guards.IsTransactionHash(bundleHash) {
+		return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid bundle hash provided: %s", bundleHash)
+	}
+
+	return hornet.HashFromHashTrytes(bundleHash), nil
+}
+
 func parseBundleQueryParam(c echo.Context) (hornet.Hash, error) {
 	value := strings.ToUpper(c.QueryParam(QueryParameterBundle))
 
@@ -85,33 +123,61 @@ func parseAddressQueryParam(c echo.Context) (hornet.Hash, error) {
 	return nil, nil
 }
 
-func parseTagQueryParam(c echo.Context) (hornet.Hash, error) {
-	value := strings.ToUpper(c.QueryParam(QueryParameterTag))
-
-	if len(value) > 0 {
-		if err := trinary.ValidTrytes(value); err != nil {
+// parseTag parses the given tag trytes.
+// If the tag ends with the wildcard "*", all tags starting with the given trytes match.
+// Otherwise shorter tags are padded with "9" to a full tag.
+func parseTag(value string) (hornet.Hashes, error) {
+	if strings.HasSuffix(value, TagPrefixWildcard) {
+		prefix := strings.TrimSuffix(value, TagPrefixWildcard)
+		if len(prefix) == 0 {
+			return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid tag prefix provided: %s", value)
+		}
+		if err := trinary.ValidTrytes(prefix); err != nil {
 			return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid tag trytes provided: %s", value)
 		}
-		if len(value) > 27 {
+		if len(prefix) > 27 {
 			return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid tag length: %s", value)
 		}
-		if len(value) < 27 {
-			value = trinary.MustPad(value, 27)
-		}
 
-		return hornet.HashFromTagTrytes(value), nil
+		return hornet.TagPrefixesFromTrytes(prefix), nil
+	}
+
+	if err := trinary.ValidTrytes(value); err != nil {
+		return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid tag trytes provided: %s", value)
+	}
+	if len(value) > 27 {
+		return nil, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid tag length: %s", value)
+	}
+	if len(value) < 27 {
+		value = trinary.MustPad(value, 27)
+	}
+
+	return hornet.Hashes{hornet.HashFromTagTrytes(value)}, nil
+}
+
+func parseTagQueryParam(c echo.Context) (hornet.Hashes, error) {
+	value := strings.ToUpper(c.QueryParam(QueryParameterTag))
+
+	if len(value) > 0 {
+		return parseTag(value)
 	}
 
 	return nil, nil
 }
 
 func parseMaxResultsQueryParam(c echo.Context, maxResults int) (int, error) {
-	value := c.QueryParam(QueryParameterMaxResults)
+	return parseLimitQueryParam(c, QueryParameterMaxResults, maxResults)
+}
+
+// parseLimitQueryParam parses the given query parameter as a limit.
+// The result is never bigger than the given maximum.
+func parseLimitQueryParam(c echo.Context, paramName string, maxResults int) (int, error) {
+	value := c.QueryParam(paramName)
 
 	if len(value) > 0 {
 		requestMaxResults, err := strconv.ParseInt(value, 10, 32)
 		if err != nil {
-			return 0, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid %s, error: %s", QueryParameterMaxResults, err)
+			return 0, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid %s, error: %s", paramName, err)
 		}
 
 		if (requestMaxResults > 0) && (int(requestMaxResults) < maxResults) {
@@ -122,3 +188,18 @@ func parseMaxResultsQueryParam(c echo.Context, maxResults int) (int, error) {
 
 	return maxResults, nil
 }
+
+func parseMilestoneIndexQueryParam(c echo.Context, paramName string) (milestone.Index, error) {
+	value := c.QueryParam(paramName)
+
+	if len(value) > 0 {
+		msIndex, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return 0, ierrors.Wrapf(httpserver.ErrInvalidParameter, "invalid %s, error: %s", paramName, err)
+		}
+
+		return milestone.Index(msIndex), nil
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/inx-app/pkg/httpserver"
+
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
+)
+
+func TestDatabaseError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{name: "not found", err: ierrors.Wrapf(database.ErrMilestoneNotFound, "index: %d", 5), expected: echo.ErrNotFound},
+		{name: "out of range", err: ierrors.Wrap(database.ErrOutOfRange, "milestone range too big"), expected: httpserver.ErrInvalidParameter},
+		{name: "aborted", err: database.ErrOperationAborted, expected: echo.ErrServiceUnavailable},
+		{name: "corrupted", err: ierrors.Wrap(database.ErrCorrupted, "metadata not found"), expected: echo.ErrInternalServerError},
+		{name: "storage error", err: ierrors.New("failed to read from disk"), expected: echo.ErrInternalServerError},
+	}
+
+	for _, test := range tests {
+		err := databaseError(test.err)
+		if !ierrors.Is(err, test.expected) {
+			t.Fatalf("%s: expected %v, got %v", test.name, test.expected, err)
+		}
+
+		// the message of the database error is kept for the response
+		if !strings.Contains(err.Error(), test.err.Error()) {
+			t.Fatalf("%s: expected the message %q to be kept, got %q", test.name, test.err.Error(), err.Error())
+		}
+	}
+
+	// the storage errors are not reported as client errors
+	var httpErr *echo.HTTPError
+	if !ierrors.As(databaseError(ierrors.New("failed to read from disk")), &httpErr) || httpErr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status code %d, got %v", http.StatusInternalServerError, httpErr)
+	}
+}
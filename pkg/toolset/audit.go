@@ -0,0 +1,104 @@
+package toolset
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore"
+	hivedb "github.com/iotaledger/hive.go/kvstore/database"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database/engine"
+)
+
+const (
+	// DefaultValueAuditReportPath is the default path of the audit report.
+	DefaultValueAuditReportPath = "audit_report.json"
+	// DefaultValueAuditMaxIssues is the default maximum amount of issues that are listed per check.
+	DefaultValueAuditMaxIssues = 100
+)
+
+func audit(args []string) error {
+
+	fs := newFlagSet(ToolAudit)
+	databasePathFlag := fs.String(FlagToolDatabasePath, DefaultValueDatabasePath, "the path to the folder that contains the tangle, snapshot and spent databases")
+	outputPathFlag := fs.String(FlagToolOutputPath, DefaultValueAuditReportPath, "the path to the JSON report file")
+	maxIssuesFlag := fs.Int(FlagToolMaxIssues, DefaultValueAuditMaxIssues, "the maximum amount of issues that are listed per check")
+
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage of %s:\n", ToolAudit)
+		fs.PrintDefaults()
+		println(fmt.Sprintf("\nexample: %s --%s %s --%s %s",
+			ToolAudit,
+			FlagToolDatabasePath,
+			DefaultValueDatabasePath,
+			FlagToolOutputPath,
+			DefaultValueAuditReportPath))
+	}
+
+	if err := parseFlagSet(fs, args); err != nil {
+		return err
+	}
+
+	if *maxIssuesFlag < 0 {
+		return ierrors.Errorf("'%s' must not be negative", FlagToolMaxIssues)
+	}
+
+	ctx, cancel := gracefulStopContext()
+	defer cancel()
+
+	log := logger.NewExampleLogger(ToolAudit)
+
+	// the stores are not opened as a database, because loading the database fails on the issues the audit should report
+	stores := make(map[string]kvstore.KVStore, 3)
+	for _, databaseName := range []string{tangleDatabaseName, snapshotDatabaseName, spentDatabaseName} {
+		databasePath := filepath.Join(*databasePathFlag, databaseName)
+
+		if _, err := os.Stat(databasePath); err != nil {
+			return ierrors.Wrapf(err, "failed to open %s database: %s", databaseName, databasePath)
+		}
+
+		store, err := engine.StoreWithDefaultSettings(databasePath, false, hivedb.EngineAuto, true, engine.AllowedEnginesStorageAuto...)
+		if err != nil {
+			return ierrors.Wrapf(err, "failed to open %s database: %s", databaseName, databasePath)
+		}
+		defer func() { _ = store.Close() }()
+
+		stores[databaseName] = store
+	}
+
+	fmt.Println("Auditing databases...")
+	ts := time.Now()
+
+	report, err := database.Audit(ctx, log, stores[tangleDatabaseName], stores[snapshotDatabaseName], stores[spentDatabaseName], *maxIssuesFlag)
+	if err != nil {
+		return ierrors.Wrap(err, "failed to audit databases")
+	}
+
+	reportJSON, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return ierrors.Wrap(err, "failed to marshal audit report")
+	}
+
+	if err := os.WriteFile(*outputPathFlag, reportJSON, 0o600); err != nil {
+		return ierrors.Wrapf(err, "failed to write audit report: %s", *outputPathFlag)
+	}
+
+	fmt.Printf("Auditing databases... done! took: %v\n", time.Since(ts).Truncate(time.Millisecond))
+
+	var failed uint64
+	for _, check := range report.Checks {
+		fmt.Printf("	%-30s checked: %d, failed: %d\n", check.Name, check.Checked, check.Failed)
+		failed += check.Failed
+	}
+
+	if !report.Passed {
+		return ierrors.Errorf("audit found %d issues, see report: %s", failed, *outputPathFlag)
+	}
+
+	return nil
+}
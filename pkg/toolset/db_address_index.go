@@ -0,0 +1,70 @@
+package toolset
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	hivedb "github.com/iotaledger/hive.go/kvstore/database"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database/engine"
+)
+
+func databaseAddressIndex(args []string) error {
+
+	fs := newFlagSet(ToolDatabaseAddressIndex)
+	databasePathFlag := fs.String(FlagToolDatabasePath, DefaultValueDatabasePath, "the path to the folder that contains the tangle, snapshot and spent databases")
+	indexDatabasePathFlag := fs.String(FlagToolIndexDatabasePath, DefaultValueIndexDatabasePath, "the path to the index database")
+	indexDatabaseEngineFlag := fs.String(FlagToolIndexDatabaseEngine, string(engine.EnginePebble), "the engine of the index database if it is created (values: pebble, rocksdb)")
+
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage of %s:\n", ToolDatabaseAddressIndex)
+		fs.PrintDefaults()
+		println(fmt.Sprintf("\nexample: %s --%s %s --%s %s",
+			ToolDatabaseAddressIndex,
+			FlagToolDatabasePath,
+			DefaultValueDatabasePath,
+			FlagToolIndexDatabasePath,
+			DefaultValueIndexDatabasePath))
+	}
+
+	if err := parseFlagSet(fs, args); err != nil {
+		return err
+	}
+
+	indexDatabaseEngine := hivedb.Engine(strings.ToLower(*indexDatabaseEngineFlag))
+	if indexDatabaseEngine != engine.EnginePebble && indexDatabaseEngine != hivedb.EngineRocksDB {
+		return ierrors.Errorf("'%s' has an invalid value: %s", FlagToolIndexDatabaseEngine, indexDatabaseEngine)
+	}
+
+	ctx, cancel := gracefulStopContext()
+	defer cancel()
+
+	log := logger.NewExampleLogger(ToolDatabaseAddressIndex)
+
+	db, err := openLegacyDatabase(ctx, log, *databasePathFlag)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.CloseDatabases() }()
+
+	indexDatabase, err := database.OpenIndexDatabase(*indexDatabasePathFlag, true, indexDatabaseEngine)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = indexDatabase.Close() }()
+
+	fmt.Println("Building address milestones index...")
+	ts := time.Now()
+
+	if err := database.BuildAddressMilestonesIndex(ctx, log, db, indexDatabase); err != nil {
+		return ierrors.Wrap(err, "failed to build address milestones index")
+	}
+
+	fmt.Printf("Building address milestones index... done! took: %v\n", time.Since(ts).Truncate(time.Millisecond))
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package toolset
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	hivedb "github.com/iotaledger/hive.go/kvstore/database"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database/engine"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+)
+
+const (
+	// DefaultValueLedgerCheckpointsInterval is the default interval of the ledger checkpoints in milestones.
+	DefaultValueLedgerCheckpointsInterval = 10_000
+)
+
+func databaseCheckpoints(args []string) error {
+
+	fs := newFlagSet(ToolDatabaseCheckpoints)
+	databasePathFlag := fs.String(FlagToolDatabasePath, DefaultValueDatabasePath, "the path to the folder that contains the tangle, snapshot and spent databases")
+	indexDatabasePathFlag := fs.String(FlagToolIndexDatabasePath, DefaultValueIndexDatabasePath, "the path to the index database")
+	indexDatabaseEngineFlag := fs.String(FlagToolIndexDatabaseEngine, string(engine.EnginePebble), "the engine of the index database if it is created (values: pebble, rocksdb)")
+	intervalFlag := fs.Uint32(FlagToolInterval, DefaultValueLedgerCheckpointsInterval, "the interval of the ledger checkpoints in milestones")
+
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage of %s:\n", ToolDatabaseCheckpoints)
+		fs.PrintDefaults()
+		println(fmt.Sprintf("\nexample: %s --%s %s --%s %s --%s %d",
+			ToolDatabaseCheckpoints,
+			FlagToolDatabasePath,
+			DefaultValueDatabasePath,
+			FlagToolIndexDatabasePath,
+			DefaultValueIndexDatabasePath,
+			FlagToolInterval,
+			DefaultValueLedgerCheckpointsInterval))
+	}
+
+	if err := parseFlagSet(fs, args); err != nil {
+		return err
+	}
+
+	indexDatabaseEngine := hivedb.Engine(strings.ToLower(*indexDatabaseEngineFlag))
+	if indexDatabaseEngine != engine.EnginePebble && indexDatabaseEngine != hivedb.EngineRocksDB {
+		return ierrors.Errorf("'%s' has an invalid value: %s", FlagToolIndexDatabaseEngine, indexDatabaseEngine)
+	}
+
+	if *intervalFlag == 0 {
+		return ierrors.Errorf("'%s' must be greater than zero", FlagToolInterval)
+	}
+
+	ctx, cancel := gracefulStopContext()
+	defer cancel()
+
+	log := logger.NewExampleLogger(ToolDatabaseCheckpoints)
+
+	db, err := openLegacyDatabase(ctx, log, *databasePathFlag)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.CloseDatabases() }()
+
+	indexDatabase, err := database.OpenIndexDatabase(*indexDatabasePathFlag, true, indexDatabaseEngine)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = indexDatabase.Close() }()
+
+	fmt.Printf("Building ledger checkpoints (interval: %d)...\n", *intervalFlag)
+	ts := time.Now()
+
+	if err := database.BuildLedgerCheckpoints(ctx, log, db, indexDatabase, milestone.Index(*intervalFlag)); err != nil {
+		return ierrors.Wrap(err, "failed to build ledger checkpoints")
+	}
+
+	fmt.Printf("Building ledger checkpoints... done! took: %v\n", time.Since(ts).Truncate(time.Millisecond))
+
+	return nil
+}
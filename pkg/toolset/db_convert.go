@@ -0,0 +1,236 @@
+package toolset
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore"
+	hivedb "github.com/iotaledger/hive.go/kvstore/database"
+	"github.com/iotaledger/hive.go/runtime/contextutils"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database/engine"
+)
+
+const (
+	// the amount of entries that are written to the target database in a single batch.
+	convertBatchSize = 100_000
+)
+
+func databaseConvert(args []string) error {
+
+	fs := newFlagSet(ToolDatabaseConvert)
+	sourceDatabasePathFlag := fs.String(FlagToolSourceDatabasePath, DefaultValueDatabasePath, "the path to the folder that contains the source tangle, snapshot and spent databases")
+	targetDatabasePathFlag := fs.String(FlagToolTargetDatabasePath, DefaultValueTargetDatabasePath, "the path to the folder where the converted databases are created")
+	targetEngineFlag := fs.String(FlagToolTargetEngine, string(engine.EnginePebble), "the engine of the target databases (values: pebble, rocksdb)")
+
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage of %s:\n", ToolDatabaseConvert)
+		fs.PrintDefaults()
+		println(fmt.Sprintf("\nexample: %s --%s %s --%s %s --%s %s",
+			ToolDatabaseConvert,
+			FlagToolSourceDatabasePath,
+			DefaultValueDatabasePath,
+			FlagToolTargetDatabasePath,
+			DefaultValueTargetDatabasePath,
+			FlagToolTargetEngine,
+			engine.EnginePebble))
+	}
+
+	if err := parseFlagSet(fs, args); err != nil {
+		return err
+	}
+
+	targetEngine := hivedb.Engine(strings.ToLower(*targetEngineFlag))
+	if targetEngine != engine.EnginePebble && targetEngine != hivedb.EngineRocksDB {
+		return ierrors.Errorf("'%s' has an invalid value: %s", FlagToolTargetEngine, targetEngine)
+	}
+
+	ctx, cancel := gracefulStopContext()
+	defer cancel()
+
+	for _, name := range []string{tangleDatabaseName, snapshotDatabaseName, spentDatabaseName} {
+		sourcePath := filepath.Join(*sourceDatabasePathFlag, name)
+		targetPath := filepath.Join(*targetDatabasePathFlag, name)
+
+		if _, err := os.Stat(targetPath); err == nil || !os.IsNotExist(err) {
+			return ierrors.Errorf("target database already exists: %s", targetPath)
+		}
+
+		fmt.Printf("Converting %s database (%s -> %s)...\n", name, sourcePath, targetPath)
+		ts := time.Now()
+
+		if err := convertDatabase(ctx, sourcePath, targetPath, targetEngine); err != nil {
+			return ierrors.Wrapf(err, "failed to convert %s database", name)
+		}
+
+		fmt.Printf("Converting %s database... done! took: %v\n", name, time.Since(ts).Truncate(time.Millisecond))
+	}
+
+	return nil
+}
+
+func convertDatabase(ctx context.Context, sourcePath string, targetPath string, targetEngine hivedb.Engine) (err error) {
+
+	sourceStore, err := engine.StoreWithDefaultSettings(sourcePath, false, hivedb.EngineAuto, true, engine.AllowedEnginesStorageAuto...)
+	if err != nil {
+		return ierrors.Wrapf(err, "failed to open source database: %s", sourcePath)
+	}
+	defer func() { _ = sourceStore.Close() }()
+
+	targetStore, err := engine.StoreWithDefaultSettings(targetPath, true, targetEngine, false, engine.AllowedEnginesStorage...)
+	if err != nil {
+		return ierrors.Wrapf(err, "failed to create target database: %s", targetPath)
+	}
+	defer func() {
+		if closeErr := targetStore.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	copied, err := copyStore(ctx, sourceStore, targetStore)
+	if err != nil {
+		return err
+	}
+
+	if err := targetStore.Flush(); err != nil {
+		return ierrors.Wrap(err, "failed to flush target database")
+	}
+
+	fmt.Printf("	copied %d entries, verifying...\n", copied)
+
+	return verifyStore(ctx, sourceStore, targetStore, copied)
+}
+
+// copyStore copies all entries of the source store to the target store.
+func copyStore(ctx context.Context, sourceStore kvstore.KVStore, targetStore kvstore.KVStore) (int64, error) {
+
+	batch, err := targetStore.Batched()
+	if err != nil {
+		return 0, err
+	}
+
+	lastStatusTime := time.Now()
+	var copied int64
+	var batchEntries int
+
+	var innerErr error
+	if err := sourceStore.Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		if err := batch.Set(key, value); err != nil {
+			innerErr = err
+			return false
+		}
+		copied++
+		batchEntries++
+
+		if batchEntries >= convertBatchSize {
+			if err := batch.Commit(); err != nil {
+				innerErr = err
+				return false
+			}
+
+			if batch, err = targetStore.Batched(); err != nil {
+				innerErr = err
+				return false
+			}
+			batchEntries = 0
+		}
+
+		if time.Since(lastStatusTime) >= database.PrintStatusInterval {
+			lastStatusTime = time.Now()
+
+			// check if the context was already canceled
+			if err := contextutils.ReturnErrIfCtxDone(ctx, database.ErrOperationAborted); err != nil {
+				innerErr = err
+				return false
+			}
+
+			fmt.Printf("	copied %d entries\n", copied)
+		}
+
+		return true
+	}); err != nil {
+		batch.Cancel()
+		return 0, ierrors.Wrap(err, "failed to iterate over source database")
+	}
+
+	if innerErr != nil {
+		batch.Cancel()
+		return 0, innerErr
+	}
+
+	if err := batch.Commit(); err != nil {
+		return 0, ierrors.Wrap(err, "failed to commit batch")
+	}
+
+	return copied, nil
+}
+
+// verifyStore checks that all entries of the source store exist with the same value in the target store,
+// and that the target store doesn't contain any additional entries.
+func verifyStore(ctx context.Context, sourceStore kvstore.KVStore, targetStore kvstore.KVStore, expectedEntries int64) error {
+
+	lastStatusTime := time.Now()
+	var verified int64
+
+	var innerErr error
+	if err := sourceStore.Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		targetValue, err := targetStore.Get(key)
+		if err != nil {
+			innerErr = ierrors.Wrapf(err, "failed to get key %x from target database", key)
+			return false
+		}
+
+		if !bytes.Equal(value, targetValue) {
+			innerErr = ierrors.Errorf("value mismatch for key %x", key)
+			return false
+		}
+		verified++
+
+		if time.Since(lastStatusTime) >= database.PrintStatusInterval {
+			lastStatusTime = time.Now()
+
+			// check if the context was already canceled
+			if err := contextutils.ReturnErrIfCtxDone(ctx, database.ErrOperationAborted); err != nil {
+				innerErr = err
+				return false
+			}
+
+			fmt.Printf("	verified %d/%d entries\n", verified, expectedEntries)
+		}
+
+		return true
+	}); err != nil {
+		return ierrors.Wrap(err, "failed to iterate over source database")
+	}
+
+	if innerErr != nil {
+		return innerErr
+	}
+
+	if verified != expectedEntries {
+		return ierrors.Errorf("entry count mismatch: copied %d, verified %d", expectedEntries, verified)
+	}
+
+	// all entries of the source store exist in the target store,
+	// so the target store contains additional entries if it has more entries in total.
+	var targetEntries int64
+	if err := targetStore.IterateKeys(kvstore.EmptyPrefix, func(_ kvstore.Key) bool {
+		targetEntries++
+
+		return true
+	}); err != nil {
+		return ierrors.Wrap(err, "failed to iterate over target database")
+	}
+
+	if targetEntries != expectedEntries {
+		return ierrors.Errorf("entry count mismatch: source %d, target %d", expectedEntries, targetEntries)
+	}
+
+	return nil
+}
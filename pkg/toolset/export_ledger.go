@@ -0,0 +1,359 @@
+package toolset
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hive.go/runtime/contextutils"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+)
+
+const (
+	// LedgerExportFormatCSV exports the ledger state as comma-separated values.
+	LedgerExportFormatCSV = "csv"
+	// LedgerExportFormatJSONL exports the ledger state as JSON Lines.
+	LedgerExportFormatJSONL = "jsonl"
+	// LedgerExportFormatBinary exports the ledger state in a compact binary format.
+	LedgerExportFormatBinary = "binary"
+)
+
+const (
+	// LedgerExportVersion is the version of the ledger export file formats.
+	LedgerExportVersion byte = 1
+	// LedgerExportBinaryMagic are the first bytes of a ledger export in the binary format.
+	LedgerExportBinaryMagic = "LGRX"
+)
+
+// ledgerExportHeader is written at the beginning of every ledger export.
+//
+// The checksum is the SHA-256 hash of all bytes following the header.
+//
+// CSV: the header consists of "# key: value" comment lines, followed by the column names "address,balance"
+// and one "address,balance" line per address.
+//
+// JSONL: the header is a JSON object on the first line, followed by one {"address","balance"} object per line.
+//
+// Binary (all integers little endian):
+//
+//	magic              [4]byte  "LGRX"
+//	version            byte
+//	milestoneIndex     uint32
+//	milestoneHash      [49]byte
+//	milestoneTimestamp uint64
+//	addressesCount     uint64
+//	totalBalance       uint64
+//	checksum           [32]byte
+//	records            addressesCount * (address [49]byte, balance uint64)
+//
+// The addresses are sorted by their bytes in all formats.
+type ledgerExportHeader struct {
+	MilestoneIndex     milestone.Index
+	MilestoneHash      hornet.Hash
+	MilestoneTimestamp uint64
+	AddressesCount     uint64
+	TotalBalance       uint64
+	Checksum           []byte
+}
+
+// ledgerExportHeaderJSON is the header of the JSONL format.
+type ledgerExportHeaderJSON struct {
+	Version            byte            `json:"version"`
+	MilestoneIndex     milestone.Index `json:"milestoneIndex"`
+	MilestoneHash      string          `json:"milestoneHash"`
+	MilestoneTimestamp uint64          `json:"milestoneTimestamp"`
+	AddressesCount     uint64          `json:"addressesCount"`
+	TotalBalance       string          `json:"totalBalance"`
+	Checksum           string          `json:"checksum"`
+}
+
+// ledgerExportRecordJSON is a record of the JSONL format.
+type ledgerExportRecordJSON struct {
+	Address string `json:"address"`
+	Balance string `json:"balance"`
+}
+
+// ledgerExportEncoder writes the header and the records of a ledger export format.
+type ledgerExportEncoder interface {
+	// FileExtension returns the default file extension of the format.
+	FileExtension() string
+	WriteHeader(w io.Writer, header *ledgerExportHeader) error
+	WriteRecord(w io.Writer, address hornet.Hash, balance uint64) error
+}
+
+type csvLedgerExportEncoder struct{}
+
+func (e *csvLedgerExportEncoder) FileExtension() string {
+	return "csv"
+}
+
+func (e *csvLedgerExportEncoder) WriteHeader(w io.Writer, header *ledgerExportHeader) error {
+	_, err := fmt.Fprintf(w, "# version: %d\n# milestoneIndex: %d\n# milestoneHash: %s\n# milestoneTimestamp: %d\n# addressesCount: %d\n# totalBalance: %d\n# checksum: %s\naddress,balance\n",
+		LedgerExportVersion,
+		header.MilestoneIndex,
+		header.MilestoneHash.Trytes(),
+		header.MilestoneTimestamp,
+		header.AddressesCount,
+		header.TotalBalance,
+		hex.EncodeToString(header.Checksum),
+	)
+
+	return err
+}
+
+func (e *csvLedgerExportEncoder) WriteRecord(w io.Writer, address hornet.Hash, balance uint64) error {
+	_, err := fmt.Fprintf(w, "%s,%d\n", address.Trytes(), balance)
+
+	return err
+}
+
+type jsonlLedgerExportEncoder struct{}
+
+func (e *jsonlLedgerExportEncoder) FileExtension() string {
+	return "jsonl"
+}
+
+func (e *jsonlLedgerExportEncoder) WriteHeader(w io.Writer, header *ledgerExportHeader) error {
+	return json.NewEncoder(w).Encode(&ledgerExportHeaderJSON{
+		Version:            LedgerExportVersion,
+		MilestoneIndex:     header.MilestoneIndex,
+		MilestoneHash:      header.MilestoneHash.Trytes(),
+		MilestoneTimestamp: header.MilestoneTimestamp,
+		AddressesCount:     header.AddressesCount,
+		TotalBalance:       strconv.FormatUint(header.TotalBalance, 10),
+		Checksum:           hex.EncodeToString(header.Checksum),
+	})
+}
+
+func (e *jsonlLedgerExportEncoder) WriteRecord(w io.Writer, address hornet.Hash, balance uint64) error {
+	return json.NewEncoder(w).Encode(&ledgerExportRecordJSON{
+		Address: address.Trytes(),
+		Balance: strconv.FormatUint(balance, 10),
+	})
+}
+
+type binaryLedgerExportEncoder struct{}
+
+func (e *binaryLedgerExportEncoder) FileExtension() string {
+	return "bin"
+}
+
+func (e *binaryLedgerExportEncoder) WriteHeader(w io.Writer, header *ledgerExportHeader) error {
+	if len(header.MilestoneHash) != hornet.HashSize {
+		return ierrors.Errorf("invalid milestone hash length: %d", len(header.MilestoneHash))
+	}
+
+	if len(header.Checksum) != sha256.Size {
+		return ierrors.Errorf("invalid checksum length: %d", len(header.Checksum))
+	}
+
+	buf := make([]byte, 0, len(LedgerExportBinaryMagic)+1+4+hornet.HashSize+8+8+8+sha256.Size)
+	buf = append(buf, LedgerExportBinaryMagic...)
+	buf = append(buf, LedgerExportVersion)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(header.MilestoneIndex))
+	buf = append(buf, header.MilestoneHash...)
+	buf = binary.LittleEndian.AppendUint64(buf, header.MilestoneTimestamp)
+	buf = binary.LittleEndian.AppendUint64(buf, header.AddressesCount)
+	buf = binary.LittleEndian.AppendUint64(buf, header.TotalBalance)
+	buf = append(buf, header.Checksum...)
+
+	_, err := w.Write(buf)
+
+	return err
+}
+
+func (e *binaryLedgerExportEncoder) WriteRecord(w io.Writer, address hornet.Hash, balance uint64) error {
+	buf := make([]byte, 0, hornet.HashSize+8)
+	buf = append(buf, address...)
+	buf = binary.LittleEndian.AppendUint64(buf, balance)
+
+	_, err := w.Write(buf)
+
+	return err
+}
+
+func newLedgerExportEncoder(format string) (ledgerExportEncoder, error) {
+	switch format {
+	case LedgerExportFormatCSV:
+		return &csvLedgerExportEncoder{}, nil
+	case LedgerExportFormatJSONL:
+		return &jsonlLedgerExportEncoder{}, nil
+	case LedgerExportFormatBinary:
+		return &binaryLedgerExportEncoder{}, nil
+	default:
+		return nil, ierrors.Errorf("'%s' has an invalid value: %s", FlagToolFormat, format)
+	}
+}
+
+func exportLedger(args []string) error {
+
+	fs := newFlagSet(ToolExportLedger)
+	databasePathFlag := fs.String(FlagToolDatabasePath, DefaultValueDatabasePath, "the path to the folder that contains the tangle, snapshot and spent databases")
+	milestoneIndexFlag := fs.Uint32(FlagToolMilestoneIndex, 0, "the milestone index of the exported ledger state (0 = latest solid milestone)")
+	formatFlag := fs.String(FlagToolFormat, LedgerExportFormatCSV, "the format of the export (values: csv, jsonl, binary)")
+	outputPathFlag := fs.String(FlagToolOutputPath, "", "the path to the export file (default: ledger_<milestoneIndex>.<format extension>)")
+
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage of %s:\n", ToolExportLedger)
+		fs.PrintDefaults()
+		println(fmt.Sprintf("\nexample: %s --%s %s --%s %d --%s %s",
+			ToolExportLedger,
+			FlagToolDatabasePath,
+			DefaultValueDatabasePath,
+			FlagToolMilestoneIndex,
+			1050000,
+			FlagToolFormat,
+			LedgerExportFormatCSV))
+	}
+
+	if err := parseFlagSet(fs, args); err != nil {
+		return err
+	}
+
+	encoder, err := newLedgerExportEncoder(strings.ToLower(*formatFlag))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := gracefulStopContext()
+	defer cancel()
+
+	log := logger.NewExampleLogger(ToolExportLedger)
+
+	db, err := openLegacyDatabase(ctx, log, *databasePathFlag)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.CloseDatabases() }()
+
+	fmt.Println("Calculating ledger state...")
+	ts := time.Now()
+
+	balances, ledgerIndex, err := db.LedgerStateForMilestone(ctx, milestone.Index(*milestoneIndexFlag))
+	if err != nil {
+		return ierrors.Wrap(err, "failed to calculate ledger state")
+	}
+
+	fmt.Printf("Calculating ledger state... done! took: %v\n", time.Since(ts).Truncate(time.Millisecond))
+
+	ms, err := db.MilestoneOrNil(ledgerIndex)
+	if err != nil {
+		return err
+	}
+	if ms == nil {
+		return ierrors.Wrapf(database.ErrMilestoneNotFound, "index: %d", ledgerIndex)
+	}
+
+	timestamp, err := db.MilestoneTimestamp(ledgerIndex)
+	if err != nil {
+		return err
+	}
+
+	outputPath := *outputPathFlag
+	if outputPath == "" {
+		outputPath = fmt.Sprintf("ledger_%d.%s", ledgerIndex, encoder.FileExtension())
+	}
+
+	fmt.Printf("Exporting ledger state (milestone: %d, addresses: %d)...\n", ledgerIndex, len(balances))
+	ts = time.Now()
+
+	header, err := writeLedgerExport(ctx, encoder, outputPath, ms, timestamp, balances)
+	if err != nil {
+		return ierrors.Wrapf(err, "failed to export ledger state: %s", outputPath)
+	}
+
+	fmt.Printf("Exporting ledger state... done! took: %v\n", time.Since(ts).Truncate(time.Millisecond))
+	fmt.Printf("	%-20s %s\n", "file:", outputPath)
+	fmt.Printf("	%-20s %d\n", "milestone index:", header.MilestoneIndex)
+	fmt.Printf("	%-20s %s\n", "milestone hash:", header.MilestoneHash.Trytes())
+	fmt.Printf("	%-20s %d\n", "addresses:", header.AddressesCount)
+	fmt.Printf("	%-20s %d\n", "total balance:", header.TotalBalance)
+	fmt.Printf("	%-20s %s\n", "checksum:", hex.EncodeToString(header.Checksum))
+
+	return nil
+}
+
+// writeLedgerExport writes the sorted balances to the export file.
+// The records are encoded twice, first to calculate the checksum for the header and then to write them to the file.
+func writeLedgerExport(ctx context.Context, encoder ledgerExportEncoder, outputPath string, ms *database.Milestone, timestamp uint64, balances map[string]uint64) (*ledgerExportHeader, error) {
+
+	addresses := make([]string, 0, len(balances))
+	var totalBalance uint64
+	for address, balance := range balances {
+		addresses = append(addresses, address)
+		totalBalance += balance
+	}
+	sort.Strings(addresses)
+
+	writeRecords := func(w io.Writer) error {
+		for _, address := range addresses {
+			if err := contextutils.ReturnErrIfCtxDone(ctx, database.ErrOperationAborted); err != nil {
+				return err
+			}
+
+			if err := encoder.WriteRecord(w, hornet.Hash(address), balances[address]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	hasher := sha256.New()
+	if err := writeRecords(hasher); err != nil {
+		return nil, err
+	}
+
+	header := &ledgerExportHeader{
+		MilestoneIndex:     ms.Index,
+		MilestoneHash:      ms.Hash,
+		MilestoneTimestamp: timestamp,
+		AddressesCount:     uint64(len(addresses)),
+		TotalBalance:       totalBalance,
+		Checksum:           hasher.Sum(nil),
+	}
+
+	file, err := os.OpenFile(outputPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := func() error {
+		w := bufio.NewWriter(file)
+
+		if err := encoder.WriteHeader(w, header); err != nil {
+			return err
+		}
+
+		if err := writeRecords(w); err != nil {
+			return err
+		}
+
+		return w.Flush()
+	}(); err != nil {
+		_ = file.Close()
+		_ = os.Remove(outputPath)
+
+		return nil, err
+	}
+
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+
+	return header, nil
+}
@@ -0,0 +1,236 @@
+package toolset
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+)
+
+const testLedgerExportTimestamp = 1_600_000_000
+
+var (
+	testLedgerExportMilestone = &database.Milestone{
+		Index: 42,
+		Hash:  hornet.HashFromHashTrytes(strings.Repeat("M", 81)),
+	}
+
+	// the addresses in their expected export order
+	testLedgerExportAddresses = []hornet.Hash{
+		hornet.HashFromAddressTrytes(strings.Repeat("A", 81)),
+		hornet.HashFromAddressTrytes(strings.Repeat("B", 81)),
+		hornet.HashFromAddressTrytes(strings.Repeat("C", 81)),
+	}
+)
+
+// writeTestLedgerExport exports a small ledger in the given format and returns the file content.
+func writeTestLedgerExport(t *testing.T, format string) ([]byte, *ledgerExportHeader, map[string]uint64) {
+	t.Helper()
+
+	balances := map[string]uint64{
+		string(testLedgerExportAddresses[2]): 300,
+		string(testLedgerExportAddresses[0]): 100,
+		string(testLedgerExportAddresses[1]): 200,
+	}
+
+	encoder, err := newLedgerExportEncoder(format)
+	if err != nil {
+		t.Fatalf("failed to create encoder: %v", err)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "ledger."+encoder.FileExtension())
+	header, err := writeLedgerExport(context.Background(), encoder, outputPath, testLedgerExportMilestone, testLedgerExportTimestamp, balances)
+	if err != nil {
+		t.Fatalf("failed to export ledger: %v", err)
+	}
+
+	if header.MilestoneIndex != testLedgerExportMilestone.Index ||
+		!bytes.Equal(header.MilestoneHash, testLedgerExportMilestone.Hash) ||
+		header.MilestoneTimestamp != testLedgerExportTimestamp ||
+		header.AddressesCount != 3 ||
+		header.TotalBalance != 600 {
+		t.Fatalf("unexpected header: %+v", header)
+	}
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read export: %v", err)
+	}
+
+	return content, header, balances
+}
+
+// checkLedgerExportChecksum checks that the checksum matches the bytes following the header.
+func checkLedgerExportChecksum(t *testing.T, checksum []byte, records []byte) {
+	t.Helper()
+
+	if expected := sha256.Sum256(records); !bytes.Equal(checksum, expected[:]) {
+		t.Fatalf("expected checksum %x, got %x", expected, checksum)
+	}
+}
+
+func TestLedgerExportCSV(t *testing.T) {
+	content, header, balances := writeTestLedgerExport(t, LedgerExportFormatCSV)
+
+	headerEnd := bytes.Index(content, []byte("address,balance\n"))
+	if headerEnd == -1 {
+		t.Fatalf("missing column names in export:\n%s", content)
+	}
+	headerEnd += len("address,balance\n")
+
+	headerFields := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSuffix(string(content[:headerEnd]), "address,balance\n"), "\n") {
+		if line == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(strings.TrimPrefix(line, "# "), ": ")
+		if !found || !strings.HasPrefix(line, "# ") {
+			t.Fatalf("invalid header line: %q", line)
+		}
+		headerFields[key] = value
+	}
+
+	for key, expected := range map[string]string{
+		"version":            fmt.Sprint(LedgerExportVersion),
+		"milestoneIndex":     "42",
+		"milestoneHash":      testLedgerExportMilestone.Hash.Trytes(),
+		"milestoneTimestamp": fmt.Sprint(testLedgerExportTimestamp),
+		"addressesCount":     "3",
+		"totalBalance":       "600",
+		"checksum":           hex.EncodeToString(header.Checksum),
+	} {
+		if headerFields[key] != expected {
+			t.Fatalf("expected header %s to be %q, got %q", key, expected, headerFields[key])
+		}
+	}
+
+	records := content[headerEnd:]
+	checkLedgerExportChecksum(t, header.Checksum, records)
+
+	lines := strings.Split(strings.TrimSuffix(string(records), "\n"), "\n")
+	if len(lines) != len(testLedgerExportAddresses) {
+		t.Fatalf("expected %d records, got %d", len(testLedgerExportAddresses), len(lines))
+	}
+	for i, address := range testLedgerExportAddresses {
+		if expected := fmt.Sprintf("%s,%d", address.Trytes(), balances[string(address)]); lines[i] != expected {
+			t.Fatalf("expected record %q, got %q", expected, lines[i])
+		}
+	}
+}
+
+func TestLedgerExportJSONL(t *testing.T) {
+	content, header, balances := writeTestLedgerExport(t, LedgerExportFormatJSONL)
+
+	headerEnd := bytes.IndexByte(content, '\n') + 1
+	if headerEnd == 0 {
+		t.Fatalf("missing header line in export:\n%s", content)
+	}
+
+	headerJSON := &ledgerExportHeaderJSON{}
+	if err := json.Unmarshal(content[:headerEnd], headerJSON); err != nil {
+		t.Fatalf("failed to decode header: %v", err)
+	}
+
+	if *headerJSON != (ledgerExportHeaderJSON{
+		Version:            LedgerExportVersion,
+		MilestoneIndex:     42,
+		MilestoneHash:      testLedgerExportMilestone.Hash.Trytes(),
+		MilestoneTimestamp: testLedgerExportTimestamp,
+		AddressesCount:     3,
+		TotalBalance:       "600",
+		Checksum:           hex.EncodeToString(header.Checksum),
+	}) {
+		t.Fatalf("unexpected header: %+v", headerJSON)
+	}
+
+	records := content[headerEnd:]
+	checkLedgerExportChecksum(t, header.Checksum, records)
+
+	decoder := json.NewDecoder(bytes.NewReader(records))
+	for _, address := range testLedgerExportAddresses {
+		record := &ledgerExportRecordJSON{}
+		if err := decoder.Decode(record); err != nil {
+			t.Fatalf("failed to decode record: %v", err)
+		}
+
+		if record.Address != address.Trytes() || record.Balance != fmt.Sprint(balances[string(address)]) {
+			t.Fatalf("unexpected record of %s: %+v", address.Trytes(), record)
+		}
+	}
+
+	if decoder.More() {
+		t.Fatal("expected no more records")
+	}
+}
+
+func TestLedgerExportBinary(t *testing.T) {
+	content, header, balances := writeTestLedgerExport(t, LedgerExportFormatBinary)
+
+	const headerSize = len(LedgerExportBinaryMagic) + 1 + 4 + hornet.HashSize + 8 + 8 + 8 + sha256.Size
+	const recordSize = hornet.HashSize + 8
+
+	if len(content) != headerSize+len(testLedgerExportAddresses)*recordSize {
+		t.Fatalf("unexpected export size: %d", len(content))
+	}
+
+	offset := 0
+	next := func(size int) []byte {
+		data := content[offset : offset+size]
+		offset += size
+
+		return data
+	}
+
+	if magic := next(len(LedgerExportBinaryMagic)); string(magic) != LedgerExportBinaryMagic {
+		t.Fatalf("unexpected magic: %q", magic)
+	}
+	if version := next(1)[0]; version != LedgerExportVersion {
+		t.Fatalf("unexpected version: %d", version)
+	}
+	if msIndex := binary.LittleEndian.Uint32(next(4)); msIndex != 42 {
+		t.Fatalf("unexpected milestone index: %d", msIndex)
+	}
+	if msHash := next(hornet.HashSize); !bytes.Equal(msHash, testLedgerExportMilestone.Hash) {
+		t.Fatalf("unexpected milestone hash: %s", hornet.Hash(msHash).Trytes())
+	}
+	if timestamp := binary.LittleEndian.Uint64(next(8)); timestamp != testLedgerExportTimestamp {
+		t.Fatalf("unexpected milestone timestamp: %d", timestamp)
+	}
+	if addressesCount := binary.LittleEndian.Uint64(next(8)); addressesCount != 3 {
+		t.Fatalf("unexpected addresses count: %d", addressesCount)
+	}
+	if totalBalance := binary.LittleEndian.Uint64(next(8)); totalBalance != 600 {
+		t.Fatalf("unexpected total balance: %d", totalBalance)
+	}
+	if checksum := next(sha256.Size); !bytes.Equal(checksum, header.Checksum) {
+		t.Fatalf("unexpected checksum: %x", checksum)
+	}
+
+	checkLedgerExportChecksum(t, header.Checksum, content[headerSize:])
+
+	for _, address := range testLedgerExportAddresses {
+		if recordAddress := next(hornet.HashSize); !bytes.Equal(recordAddress, address) {
+			t.Fatalf("expected address %s, got %s", address.Trytes(), hornet.Hash(recordAddress).Trytes())
+		}
+		if balance := binary.LittleEndian.Uint64(next(8)); balance != balances[string(address)] {
+			t.Fatalf("expected balance %d of %s, got %d", balances[string(address)], address.Trytes(), balance)
+		}
+	}
+}
+
+func TestLedgerExportInvalidFormat(t *testing.T) {
+	if _, err := newLedgerExportEncoder("xml"); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,101 @@
+package toolset
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+)
+
+const (
+	// DefaultValueSpentAddressesPath is the default path of the spent addresses export.
+	DefaultValueSpentAddressesPath = "spent_addresses.txt"
+)
+
+// exportSpent writes all spent addresses to a text file, one address per line (81 trytes without checksum).
+// The addresses are sorted by their binary representation.
+func exportSpent(args []string) error {
+
+	fs := newFlagSet(ToolExportSpent)
+	databasePathFlag := fs.String(FlagToolDatabasePath, DefaultValueDatabasePath, "the path to the folder that contains the tangle, snapshot and spent databases")
+	outputPathFlag := fs.String(FlagToolOutputPath, DefaultValueSpentAddressesPath, "the path to the export file")
+
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage of %s:\n", ToolExportSpent)
+		fs.PrintDefaults()
+		println(fmt.Sprintf("\nexample: %s --%s %s --%s %s",
+			ToolExportSpent,
+			FlagToolDatabasePath,
+			DefaultValueDatabasePath,
+			FlagToolOutputPath,
+			DefaultValueSpentAddressesPath))
+	}
+
+	if err := parseFlagSet(fs, args); err != nil {
+		return err
+	}
+
+	ctx, cancel := gracefulStopContext()
+	defer cancel()
+
+	log := logger.NewExampleLogger(ToolExportSpent)
+
+	db, err := openLegacyDatabase(ctx, log, *databasePathFlag)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.CloseDatabases() }()
+
+	file, err := os.OpenFile(*outputPathFlag, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return ierrors.Wrapf(err, "failed to create export file: %s", *outputPathFlag)
+	}
+
+	fmt.Println("Exporting spent addresses...")
+	ts := time.Now()
+
+	var exported uint64
+	if err := func() error {
+		w := bufio.NewWriter(file)
+		lastStatusTime := time.Now()
+
+		if err := db.ForEachSpentAddress(ctx, func(address hornet.Hash) error {
+			exported++
+
+			if time.Since(lastStatusTime) >= database.PrintStatusInterval {
+				lastStatusTime = time.Now()
+				log.Infof("	exported %d spent addresses", exported)
+			}
+
+			if _, err := w.WriteString(address.Trytes()); err != nil {
+				return err
+			}
+
+			return w.WriteByte('\n')
+		}); err != nil {
+			return err
+		}
+
+		return w.Flush()
+	}(); err != nil {
+		_ = file.Close()
+		_ = os.Remove(*outputPathFlag)
+
+		return ierrors.Wrap(err, "failed to export spent addresses")
+	}
+
+	if err := file.Close(); err != nil {
+		return ierrors.Wrapf(err, "failed to close export file: %s", *outputPathFlag)
+	}
+
+	fmt.Printf("Exporting spent addresses... done! took: %v\n", time.Since(ts).Truncate(time.Millisecond))
+	fmt.Printf("	%-20s %s\n", "file:", *outputPathFlag)
+	fmt.Printf("	%-20s %d\n", "spent addresses:", exported)
+
+	return nil
+}
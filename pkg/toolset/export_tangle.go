@@ -0,0 +1,334 @@
+package toolset
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+)
+
+const (
+	// DefaultValueTangleArchivePath is the default path of the tangle archive.
+	DefaultValueTangleArchivePath = "tangle_archive.bin"
+	// DefaultValueTangleArchiveChunkSize is the default amount of transactions per chunk of the tangle archive.
+	DefaultValueTangleArchiveChunkSize = 10_000
+)
+
+const (
+	// TangleArchiveVersion is the version of the tangle archive file format.
+	TangleArchiveVersion byte = 1
+	// TangleArchiveMagic are the first bytes of a tangle archive.
+	TangleArchiveMagic = "TNGA"
+
+	// TangleArchiveFlagValueOnly is set if the archive only contains value transactions.
+	TangleArchiveFlagValueOnly byte = 1 << 0
+
+	// maxTangleArchiveRecordSize is the size of a record with the biggest possible transaction.
+	maxTangleArchiveRecordSize = hornet.HashSize + database.TransactionMetadataSize + 2 + math.MaxUint16
+	// MaxTangleArchiveChunkSize is the maximum amount of transactions per chunk,
+	// so that the payload length of a chunk always fits into its uint32 field.
+	MaxTangleArchiveChunkSize = math.MaxUint32 / maxTangleArchiveRecordSize
+)
+
+// tangleArchiveWriter writes the transactions and their metadata to a tangle archive.
+//
+// The archive consists of a header, followed by chunks of transactions and a final end chunk (all integers little endian):
+//
+//	header:
+//	  magic          [4]byte  "TNGA"
+//	  version        byte
+//	  ledgerIndex    uint32   the solid milestone index of the exported database
+//	  startIndex     uint32   only transactions confirmed by milestones >= startIndex (0 = no lower bound)
+//	  endIndex       uint32   only transactions confirmed by milestones <= endIndex (0 = no upper bound)
+//	  flags          byte     bit 0: only value transactions
+//
+//	chunk:
+//	  recordsCount   uint32
+//	  payloadLength  uint32
+//	  checksum       [32]byte SHA-256 hash of the payload
+//	  payload        recordsCount * record
+//
+//	record:
+//	  txHash         [49]byte
+//	  metadata       [156]byte the marshaled database.TransactionMetadata
+//	  txLength       uint16
+//	  tx             [txLength]byte the compressed transaction (see compressed.TransactionFromCompressedBytes)
+//
+//	end chunk:
+//	  recordsCount   uint32   always 0
+//	  payloadLength  uint32   always 16
+//	  checksum       [32]byte SHA-256 hash of the payload
+//	  payload        total amount of records (uint64), total amount of chunks without the end chunk (uint64)
+//
+// If any of the milestone bounds is set, unconfirmed transactions are not part of the archive.
+// The records are sorted by the transaction hash.
+type tangleArchiveWriter struct {
+	w         *bufio.Writer
+	chunkSize int
+
+	chunk        bytes.Buffer
+	chunkRecords uint32
+
+	totalRecords uint64
+	totalChunks  uint64
+}
+
+func newTangleArchiveWriter(w io.Writer, chunkSize int) *tangleArchiveWriter {
+	return &tangleArchiveWriter{
+		w:         bufio.NewWriter(w),
+		chunkSize: chunkSize,
+	}
+}
+
+func (a *tangleArchiveWriter) writeHeader(ledgerIndex milestone.Index, startIndex milestone.Index, endIndex milestone.Index, flags byte) error {
+	buf := make([]byte, 0, len(TangleArchiveMagic)+1+4+4+4+1)
+	buf = append(buf, TangleArchiveMagic...)
+	buf = append(buf, TangleArchiveVersion)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(ledgerIndex))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(startIndex))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(endIndex))
+	buf = append(buf, flags)
+
+	_, err := a.w.Write(buf)
+
+	return err
+}
+
+func (a *tangleArchiveWriter) writeChunk(recordsCount uint32, payload []byte) error {
+	if uint64(len(payload)) > math.MaxUint32 {
+		return ierrors.Errorf("chunk payload too big: %d", len(payload))
+	}
+
+	checksum := sha256.Sum256(payload)
+
+	buf := make([]byte, 0, 4+4+sha256.Size)
+	buf = binary.LittleEndian.AppendUint32(buf, recordsCount)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(payload)))
+	buf = append(buf, checksum[:]...)
+
+	if _, err := a.w.Write(buf); err != nil {
+		return err
+	}
+
+	_, err := a.w.Write(payload)
+
+	return err
+}
+
+// addRecord adds the transaction to the current chunk and writes the chunk if it is full.
+func (a *tangleArchiveWriter) addRecord(txHash hornet.Hash, txBytes []byte, txMeta *database.TransactionMetadata) error {
+	if len(txBytes) > math.MaxUint16 {
+		return ierrors.Wrapf(database.ErrCorrupted, "transaction too big: %s, length: %d", txHash.Trytes(), len(txBytes))
+	}
+
+	a.chunk.Write(txHash)
+	a.chunk.Write(txMeta.Marshal())
+	a.chunk.Write(binary.LittleEndian.AppendUint16(nil, uint16(len(txBytes))))
+	a.chunk.Write(txBytes)
+	a.chunkRecords++
+	a.totalRecords++
+
+	if int(a.chunkRecords) >= a.chunkSize {
+		return a.flushChunk()
+	}
+
+	return nil
+}
+
+func (a *tangleArchiveWriter) flushChunk() error {
+	if a.chunkRecords == 0 {
+		return nil
+	}
+
+	if err := a.writeChunk(a.chunkRecords, a.chunk.Bytes()); err != nil {
+		return err
+	}
+
+	a.chunk.Reset()
+	a.chunkRecords = 0
+	a.totalChunks++
+
+	return nil
+}
+
+// close writes the remaining records and the end chunk.
+func (a *tangleArchiveWriter) close() error {
+	if err := a.flushChunk(); err != nil {
+		return err
+	}
+
+	payload := make([]byte, 0, 16)
+	payload = binary.LittleEndian.AppendUint64(payload, a.totalRecords)
+	payload = binary.LittleEndian.AppendUint64(payload, a.totalChunks)
+
+	if err := a.writeChunk(0, payload); err != nil {
+		return err
+	}
+
+	return a.w.Flush()
+}
+
+// tangleArchiveFilter selects the transactions that are written to a tangle archive.
+type tangleArchiveFilter struct {
+	startIndex milestone.Index
+	endIndex   milestone.Index
+	valueOnly  bool
+}
+
+// flags returns the header flags of the archive.
+func (f *tangleArchiveFilter) flags() byte {
+	var flags byte
+	if f.valueOnly {
+		flags |= TangleArchiveFlagValueOnly
+	}
+
+	return flags
+}
+
+// includes checks if the transaction matches the filter.
+func (f *tangleArchiveFilter) includes(txMeta *database.TransactionMetadata) bool {
+	if f.valueOnly && !txMeta.IsValue() {
+		return false
+	}
+
+	if f.startIndex == 0 && f.endIndex == 0 {
+		return true
+	}
+
+	confirmed, at := txMeta.ConfirmedWithIndex()
+
+	return confirmed && at >= f.startIndex && (f.endIndex == 0 || at <= f.endIndex)
+}
+
+// writeTangleArchive writes all transactions of the database that match the filter to the archive.
+func writeTangleArchive(ctx context.Context, log *logger.Logger, db *database.Database, w io.Writer, filter *tangleArchiveFilter, chunkSize int) (*tangleArchiveWriter, error) {
+
+	archive := newTangleArchiveWriter(w, chunkSize)
+
+	if err := archive.writeHeader(db.SolidMilestoneIndex(), filter.startIndex, filter.endIndex, filter.flags()); err != nil {
+		return nil, err
+	}
+
+	var analyzed uint64
+	lastStatusTime := time.Now()
+
+	if err := db.ForEachTransaction(ctx, func(txHash hornet.Hash, txBytes []byte, txMeta *database.TransactionMetadata) error {
+		analyzed++
+
+		if time.Since(lastStatusTime) >= database.PrintStatusInterval {
+			lastStatusTime = time.Now()
+			log.Infof("	analyzed %d transactions, exported %d transactions", analyzed, archive.totalRecords)
+		}
+
+		if !filter.includes(txMeta) {
+			return nil
+		}
+
+		return archive.addRecord(txHash, txBytes, txMeta)
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := archive.close(); err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+func exportTangle(args []string) error {
+
+	fs := newFlagSet(ToolExportTangle)
+	databasePathFlag := fs.String(FlagToolDatabasePath, DefaultValueDatabasePath, "the path to the folder that contains the tangle, snapshot and spent databases")
+	outputPathFlag := fs.String(FlagToolOutputPath, DefaultValueTangleArchivePath, "the path to the archive file")
+	startIndexFlag := fs.Uint32(FlagToolStartIndex, 0, "only export transactions confirmed by milestones >= startIndex (0 = no lower bound)")
+	endIndexFlag := fs.Uint32(FlagToolEndIndex, 0, "only export transactions confirmed by milestones <= endIndex (0 = no upper bound)")
+	valueOnlyFlag := fs.Bool(FlagToolValueOnly, false, "only export value transactions")
+	chunkSizeFlag := fs.Int(FlagToolChunkSize, DefaultValueTangleArchiveChunkSize, "the amount of transactions per chunk")
+
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage of %s:\n", ToolExportTangle)
+		fs.PrintDefaults()
+		println(fmt.Sprintf("\nexample: %s --%s %s --%s %s --%s %d --%s %d",
+			ToolExportTangle,
+			FlagToolDatabasePath,
+			DefaultValueDatabasePath,
+			FlagToolOutputPath,
+			DefaultValueTangleArchivePath,
+			FlagToolStartIndex,
+			1000000,
+			FlagToolEndIndex,
+			1050000))
+	}
+
+	if err := parseFlagSet(fs, args); err != nil {
+		return err
+	}
+
+	startIndex := milestone.Index(*startIndexFlag)
+	endIndex := milestone.Index(*endIndexFlag)
+
+	if endIndex != 0 && startIndex > endIndex {
+		return ierrors.Errorf("'%s' must not be bigger than '%s'", FlagToolStartIndex, FlagToolEndIndex)
+	}
+
+	if *chunkSizeFlag <= 0 || *chunkSizeFlag > MaxTangleArchiveChunkSize {
+		return ierrors.Errorf("'%s' must be between 1 and %d", FlagToolChunkSize, MaxTangleArchiveChunkSize)
+	}
+
+	ctx, cancel := gracefulStopContext()
+	defer cancel()
+
+	log := logger.NewExampleLogger(ToolExportTangle)
+
+	db, err := openLegacyDatabase(ctx, log, *databasePathFlag)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.CloseDatabases() }()
+
+	file, err := os.OpenFile(*outputPathFlag, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return ierrors.Wrapf(err, "failed to create archive: %s", *outputPathFlag)
+	}
+
+	filter := &tangleArchiveFilter{
+		startIndex: startIndex,
+		endIndex:   endIndex,
+		valueOnly:  *valueOnlyFlag,
+	}
+
+	fmt.Println("Exporting tangle...")
+	ts := time.Now()
+
+	archive, err := writeTangleArchive(ctx, log, db, file, filter, *chunkSizeFlag)
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(*outputPathFlag)
+
+		return ierrors.Wrap(err, "failed to export tangle")
+	}
+
+	if err := file.Close(); err != nil {
+		return ierrors.Wrapf(err, "failed to close archive: %s", *outputPathFlag)
+	}
+
+	fmt.Printf("Exporting tangle... done! took: %v\n", time.Since(ts).Truncate(time.Millisecond))
+	fmt.Printf("	%-20s %s\n", "file:", *outputPathFlag)
+	fmt.Printf("	%-20s %d\n", "transactions:", archive.totalRecords)
+	fmt.Printf("	%-20s %d\n", "chunks:", archive.totalChunks)
+
+	return nil
+}
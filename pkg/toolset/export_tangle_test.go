@@ -0,0 +1,312 @@
+package toolset
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/inx-api-core-v0/pkg/compressed"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/inx-api-core-v0/pkg/milestone"
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+)
+
+// testTangleTransaction describes a transaction of the tangle archive test database.
+type testTangleTransaction struct {
+	value             int64
+	confirmationIndex milestone.Index
+}
+
+// the transactions of the tangle archive test database, the ledger index is 3.
+var testTangleTransactions = []testTangleTransaction{
+	{value: 0, confirmationIndex: 0},
+	{value: 0, confirmationIndex: 1},
+	{value: 1, confirmationIndex: 2},
+	{value: -1, confirmationIndex: 3},
+	{value: 1, confirmationIndex: 0},
+}
+
+// newTangleArchiveTestDatabase builds the transactions of testTangleTransactions in memory
+// and returns the database and the hashes of the transactions in the same order.
+func newTangleArchiveTestDatabase(t *testing.T) (*database.Database, hornet.Hashes) {
+	t.Helper()
+
+	tangleStore := mapdb.NewMapDB()
+	snapshotStore := mapdb.NewMapDB()
+	spentStore := mapdb.NewMapDB()
+
+	builder, err := database.NewBuilder(tangleStore, snapshotStore, spentStore)
+	if err != nil {
+		t.Fatalf("failed to create builder: %v", err)
+	}
+
+	nullHash := trinary.Hash(strings.Repeat("9", 81))
+
+	txHashes := make(hornet.Hashes, 0, len(testTangleTransactions))
+	for i, testTx := range testTangleTransactions {
+		tx := &transaction.Transaction{
+			SignatureMessageFragment: strings.Repeat("9", 2187),
+			Address:                  strings.Repeat("A", 81),
+			Value:                    testTx.value,
+			ObsoleteTag:              strings.Repeat("9", 27),
+			Timestamp:                1_600_000_000 + uint64(i),
+			Bundle:                   nullHash,
+			TrunkTransaction:         nullHash,
+			BranchTransaction:        nullHash,
+			Tag:                      strings.Repeat("9", 27),
+			Nonce:                    strings.Repeat("9", 27),
+		}
+
+		txHash, err := builder.StoreTransaction(tx, database.TransactionState{ConfirmationIndex: testTx.confirmationIndex})
+		if err != nil {
+			t.Fatalf("failed to store transaction: %v", err)
+		}
+		txHashes = append(txHashes, txHash)
+	}
+
+	for _, step := range []func() error{
+		func() error { return builder.StoreLedgerIndex(3) },
+		func() error {
+			return builder.StoreSnapshotInfo(&database.SnapshotInfo{
+				CoordinatorAddress: hornet.HashFromAddressTrytes(strings.Repeat("C", 81)),
+				Hash:               hornet.HashFromHashTrytes(nullHash),
+				SnapshotIndex:      1,
+				EntryPointIndex:    1,
+				PruningIndex:       0,
+				Timestamp:          1_600_000_000,
+			})
+		},
+		func() error { return builder.StoreSolidEntryPoints(database.NewSolidEntryPoints()) },
+		builder.Flush,
+	} {
+		if err := step(); err != nil {
+			t.Fatalf("failed to build database: %v", err)
+		}
+	}
+
+	db, err := database.NewWithStores(context.Background(), nil, tangleStore, snapshotStore, spentStore, false, database.CacheSizes{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	return db, txHashes
+}
+
+var errTangleArchiveChecksum = ierrors.New("checksum mismatch")
+
+// tangleArchiveContent is the decoded content of a tangle archive.
+type tangleArchiveContent struct {
+	ledgerIndex milestone.Index
+	startIndex  milestone.Index
+	endIndex    milestone.Index
+	flags       byte
+	chunks      []uint32
+	records     map[string]*database.TransactionMetadata
+}
+
+// readTangleArchive decodes a tangle archive and verifies the checksums, the record counts and the end chunk.
+func readTangleArchive(data []byte) (*tangleArchiveContent, error) {
+	r := bytes.NewReader(data)
+
+	header := make([]byte, len(TangleArchiveMagic)+1+4+4+4+1)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if string(header[:len(TangleArchiveMagic)]) != TangleArchiveMagic || header[len(TangleArchiveMagic)] != TangleArchiveVersion {
+		return nil, ierrors.Errorf("invalid header: %x", header)
+	}
+	header = header[len(TangleArchiveMagic)+1:]
+
+	content := &tangleArchiveContent{
+		ledgerIndex: milestone.Index(binary.LittleEndian.Uint32(header[0:4])),
+		startIndex:  milestone.Index(binary.LittleEndian.Uint32(header[4:8])),
+		endIndex:    milestone.Index(binary.LittleEndian.Uint32(header[8:12])),
+		flags:       header[12],
+		records:     make(map[string]*database.TransactionMetadata),
+	}
+
+	for {
+		chunkHeader := make([]byte, 4+4+sha256.Size)
+		if _, err := io.ReadFull(r, chunkHeader); err != nil {
+			return nil, err
+		}
+		recordsCount := binary.LittleEndian.Uint32(chunkHeader[0:4])
+
+		payload := make([]byte, binary.LittleEndian.Uint32(chunkHeader[4:8]))
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return nil, err
+		}
+		if checksum := sha256.Sum256(payload); !bytes.Equal(checksum[:], chunkHeader[8:]) {
+			return nil, errTangleArchiveChecksum
+		}
+
+		if recordsCount == 0 {
+			if len(payload) != 16 {
+				return nil, ierrors.Errorf("invalid end chunk length: %d", len(payload))
+			}
+			if totalRecords := binary.LittleEndian.Uint64(payload[0:8]); totalRecords != uint64(len(content.records)) {
+				return nil, ierrors.Errorf("invalid total records: %d != %d", totalRecords, len(content.records))
+			}
+			if totalChunks := binary.LittleEndian.Uint64(payload[8:16]); totalChunks != uint64(len(content.chunks)) {
+				return nil, ierrors.Errorf("invalid total chunks: %d != %d", totalChunks, len(content.chunks))
+			}
+			if r.Len() != 0 {
+				return nil, ierrors.Errorf("%d bytes after the end chunk", r.Len())
+			}
+
+			return content, nil
+		}
+
+		for i := uint32(0); i < recordsCount; i++ {
+			if len(payload) < hornet.HashSize+database.TransactionMetadataSize+2 {
+				return nil, ierrors.New("truncated record")
+			}
+			txHash := hornet.Hash(payload[:hornet.HashSize])
+			payload = payload[hornet.HashSize:]
+
+			txMeta := database.NewTransactionMetadata(txHash)
+			if err := txMeta.Unmarshal(payload[:database.TransactionMetadataSize]); err != nil {
+				return nil, err
+			}
+			payload = payload[database.TransactionMetadataSize:]
+
+			txLength := int(binary.LittleEndian.Uint16(payload[:2]))
+			payload = payload[2:]
+			if len(payload) < txLength {
+				return nil, ierrors.New("truncated transaction")
+			}
+
+			// the transaction hash is recalculated from the compressed transaction
+			tx, err := compressed.TransactionFromCompressedBytes(payload[:txLength])
+			if err != nil {
+				return nil, err
+			}
+			if tx.Hash != txHash.Trytes() {
+				return nil, ierrors.Errorf("transaction hash mismatch: %s != %s", tx.Hash, txHash.Trytes())
+			}
+			payload = payload[txLength:]
+
+			content.records[string(txHash)] = txMeta
+		}
+
+		if len(payload) != 0 {
+			return nil, ierrors.Errorf("%d bytes after the last record of the chunk", len(payload))
+		}
+		content.chunks = append(content.chunks, recordsCount)
+	}
+}
+
+func TestTangleArchiveRoundTrip(t *testing.T) {
+	db, txHashes := newTangleArchiveTestDatabase(t)
+
+	var buf bytes.Buffer
+	archive, err := writeTangleArchive(context.Background(), logger.NewExampleLogger("test"), db, &buf, &tangleArchiveFilter{}, 2)
+	if err != nil {
+		t.Fatalf("failed to write tangle archive: %v", err)
+	}
+
+	if archive.totalRecords != 5 || archive.totalChunks != 3 {
+		t.Fatalf("unexpected totals: %d records, %d chunks", archive.totalRecords, archive.totalChunks)
+	}
+
+	content, err := readTangleArchive(buf.Bytes())
+	if err != nil {
+		t.Fatalf("failed to read tangle archive: %v", err)
+	}
+
+	if content.ledgerIndex != 3 || content.startIndex != 0 || content.endIndex != 0 || content.flags != 0 {
+		t.Fatalf("unexpected header: %+v", content)
+	}
+
+	if len(content.chunks) != 3 || content.chunks[0] != 2 || content.chunks[1] != 2 || content.chunks[2] != 1 {
+		t.Fatalf("unexpected chunks: %v", content.chunks)
+	}
+
+	for i, txHash := range txHashes {
+		txMeta, exists := content.records[string(txHash)]
+		if !exists {
+			t.Fatalf("transaction %d is missing in the archive", i)
+		}
+
+		confirmed, at := txMeta.ConfirmedWithIndex()
+		expected := testTangleTransactions[i]
+		if confirmed != (expected.confirmationIndex != 0) || at != expected.confirmationIndex || txMeta.IsValue() != (expected.value != 0) {
+			t.Fatalf("unexpected metadata of transaction %d: confirmed %v at %d, value %v", i, confirmed, at, txMeta.IsValue())
+		}
+	}
+
+	// the checksum covers the whole payload of a chunk
+	corrupted := bytes.Clone(buf.Bytes())
+	corrupted[len(TangleArchiveMagic)+1+4+4+4+1+4+4+sha256.Size+hornet.HashSize]++
+	if _, err := readTangleArchive(corrupted); !ierrors.Is(err, errTangleArchiveChecksum) {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+}
+
+func TestTangleArchiveFilter(t *testing.T) {
+	db, txHashes := newTangleArchiveTestDatabase(t)
+
+	tests := []struct {
+		name     string
+		filter   *tangleArchiveFilter
+		expected []int
+	}{
+		{name: "all", filter: &tangleArchiveFilter{}, expected: []int{0, 1, 2, 3, 4}},
+		{name: "start index", filter: &tangleArchiveFilter{startIndex: 2}, expected: []int{2, 3}},
+		{name: "end index", filter: &tangleArchiveFilter{endIndex: 2}, expected: []int{1, 2}},
+		{name: "milestone range", filter: &tangleArchiveFilter{startIndex: 2, endIndex: 2}, expected: []int{2}},
+		{name: "value only", filter: &tangleArchiveFilter{valueOnly: true}, expected: []int{2, 3, 4}},
+		{name: "value only with start index", filter: &tangleArchiveFilter{startIndex: 3, valueOnly: true}, expected: []int{3}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if _, err := writeTangleArchive(context.Background(), logger.NewExampleLogger("test"), db, &buf, test.filter, DefaultValueTangleArchiveChunkSize); err != nil {
+				t.Fatalf("failed to write tangle archive: %v", err)
+			}
+
+			content, err := readTangleArchive(buf.Bytes())
+			if err != nil {
+				t.Fatalf("failed to read tangle archive: %v", err)
+			}
+
+			if content.startIndex != test.filter.startIndex || content.endIndex != test.filter.endIndex || content.flags != test.filter.flags() {
+				t.Fatalf("unexpected header: %+v", content)
+			}
+
+			if len(content.records) != len(test.expected) {
+				t.Fatalf("expected %d records, got %d", len(test.expected), len(content.records))
+			}
+			for _, i := range test.expected {
+				if _, exists := content.records[string(txHashes[i])]; !exists {
+					t.Fatalf("expected transaction %d in the archive", i)
+				}
+			}
+		})
+	}
+}
+
+func TestExportTangleChunkSize(t *testing.T) {
+	// the chunk size is checked before the database is opened
+	for _, chunkSize := range []int{0, -1, MaxTangleArchiveChunkSize + 1} {
+		if err := exportTangle([]string{"--" + FlagToolDatabasePath, t.TempDir(), "--" + FlagToolChunkSize, strconv.Itoa(chunkSize)}); err == nil || !strings.Contains(err.Error(), FlagToolChunkSize) {
+			t.Fatalf("expected an invalid chunk size error for %d, got %v", chunkSize, err)
+		}
+	}
+
+	if uint64(MaxTangleArchiveChunkSize)*maxTangleArchiveRecordSize > math.MaxUint32 {
+		t.Fatal("the payload of a full chunk doesn't fit into the payload length")
+	}
+}
@@ -0,0 +1,139 @@
+package toolset
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	"github.com/iotaledger/hive.go/kvstore"
+	hivedb "github.com/iotaledger/hive.go/kvstore/database"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database/engine"
+	"github.com/iotaledger/inx-api-core-v0/pkg/hornet"
+	"github.com/iotaledger/iota.go/address"
+)
+
+const (
+	// DefaultValueSnapshotPath is the default path of the legacy local snapshot file.
+	DefaultValueSnapshotPath = "snapshots/mainnet/export.bin"
+)
+
+func importSnapshot(args []string) error {
+
+	fs := newFlagSet(ToolImportSnapshot)
+	snapshotPathFlag := fs.String(FlagToolSnapshotPath, DefaultValueSnapshotPath, "the path to the legacy local snapshot file")
+	targetDatabasePathFlag := fs.String(FlagToolTargetDatabasePath, DefaultValueDatabasePath, "the path to the folder where the tangle, snapshot and spent databases are created")
+	targetEngineFlag := fs.String(FlagToolTargetEngine, string(engine.EnginePebble), "the engine of the target databases (values: pebble, rocksdb)")
+	coordinatorAddressFlag := fs.String(FlagToolCoordinatorAddress, "", "the address of the coordinator that is stored in the snapshot info (optional)")
+
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage of %s:\n", ToolImportSnapshot)
+		fs.PrintDefaults()
+		println(fmt.Sprintf("\nexample: %s --%s %s --%s %s --%s %s",
+			ToolImportSnapshot,
+			FlagToolSnapshotPath,
+			DefaultValueSnapshotPath,
+			FlagToolTargetDatabasePath,
+			DefaultValueDatabasePath,
+			FlagToolTargetEngine,
+			engine.EnginePebble))
+	}
+
+	if err := parseFlagSet(fs, args); err != nil {
+		return err
+	}
+
+	targetEngine := hivedb.Engine(strings.ToLower(*targetEngineFlag))
+	if targetEngine != engine.EnginePebble && targetEngine != hivedb.EngineRocksDB {
+		return ierrors.Errorf("'%s' has an invalid value: %s", FlagToolTargetEngine, targetEngine)
+	}
+
+	var coordinatorAddress hornet.Hash
+	if *coordinatorAddressFlag != "" {
+		coordinatorAddressTrytes := strings.ToUpper(*coordinatorAddressFlag)
+		if err := address.ValidAddress(coordinatorAddressTrytes); err != nil {
+			return ierrors.Errorf("'%s' has an invalid value: %s, error: %s", FlagToolCoordinatorAddress, coordinatorAddressTrytes, err)
+		}
+		coordinatorAddress = hornet.HashFromAddressTrytes(coordinatorAddressTrytes[:81])
+	}
+
+	databasePaths := []string{
+		filepath.Join(*targetDatabasePathFlag, tangleDatabaseName),
+		filepath.Join(*targetDatabasePathFlag, snapshotDatabaseName),
+		filepath.Join(*targetDatabasePathFlag, spentDatabaseName),
+	}
+
+	for _, databasePath := range databasePaths {
+		if _, err := os.Stat(databasePath); err == nil || !os.IsNotExist(err) {
+			return ierrors.Errorf("target database already exists: %s", databasePath)
+		}
+	}
+
+	snapshotFile, err := os.Open(*snapshotPathFlag)
+	if err != nil {
+		return ierrors.Wrapf(err, "failed to open local snapshot file: %s", *snapshotPathFlag)
+	}
+	defer func() { _ = snapshotFile.Close() }()
+
+	ctx, cancel := gracefulStopContext()
+	defer cancel()
+
+	log := logger.NewExampleLogger(ToolImportSnapshot)
+
+	stores := make([]kvstore.KVStore, 0, len(databasePaths))
+	closeStores := func() error {
+		var closeErr error
+		for _, store := range stores {
+			if err := store.Close(); err != nil {
+				closeErr = err
+			}
+		}
+		stores = nil
+
+		return closeErr
+	}
+	defer func() { _ = closeStores() }()
+
+	for _, databasePath := range databasePaths {
+		store, err := engine.StoreWithDefaultSettings(databasePath, true, targetEngine, false, engine.AllowedEnginesStorage...)
+		if err != nil {
+			return ierrors.Wrapf(err, "failed to create target database: %s", databasePath)
+		}
+		stores = append(stores, store)
+	}
+
+	builder, err := database.NewBuilder(stores[0], stores[1], stores[2])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Importing local snapshot (%s -> %s)...\n", *snapshotPathFlag, *targetDatabasePathFlag)
+	ts := time.Now()
+
+	result, err := database.ImportLegacySnapshot(ctx, log, snapshotFile, builder, coordinatorAddress)
+	if err != nil {
+		return ierrors.Wrap(err, "failed to import local snapshot")
+	}
+
+	if err := closeStores(); err != nil {
+		return ierrors.Wrap(err, "failed to close target databases")
+	}
+
+	fmt.Printf("Importing local snapshot... done! took: %v\n", time.Since(ts).Truncate(time.Millisecond))
+	fmt.Printf("	%-20s %d\n", "milestone index:", result.Header.MilestoneIndex)
+	fmt.Printf("	%-20s %s\n", "milestone hash:", result.Header.MilestoneHash.Trytes())
+	fmt.Printf("	%-20s %d\n", "ledger entries:", result.LedgerEntries)
+	fmt.Printf("	%-20s %d\n", "spent addresses:", result.SpentAddresses)
+
+	// check that the created databases can be opened by the API
+	db, err := openLegacyDatabase(ctx, log, *targetDatabasePathFlag)
+	if err != nil {
+		return err
+	}
+
+	return db.CloseDatabases()
+}
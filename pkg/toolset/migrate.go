@@ -0,0 +1,97 @@
+package toolset
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	hivedb "github.com/iotaledger/hive.go/kvstore/database"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database/engine"
+)
+
+func migrate(args []string) error {
+
+	fs := newFlagSet(ToolMigrate)
+	databasePathFlag := fs.String(FlagToolDatabasePath, DefaultValueDatabasePath, "the path to the folder that contains the tangle, snapshot and spent databases")
+	dryRunFlag := fs.Bool(FlagToolDryRun, false, "only report the changes of the migration without writing to the databases")
+
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage of %s:\n", ToolMigrate)
+		fs.PrintDefaults()
+		println(fmt.Sprintf("\nexample: %s --%s %s --%s",
+			ToolMigrate,
+			FlagToolDatabasePath,
+			DefaultValueDatabasePath,
+			FlagToolDryRun))
+	}
+
+	if err := parseFlagSet(fs, args); err != nil {
+		return err
+	}
+
+	ctx, cancel := gracefulStopContext()
+	defer cancel()
+
+	log := logger.NewExampleLogger(ToolMigrate)
+
+	if *dryRunFlag {
+		fmt.Println("Dry-run, the databases will not be changed.")
+	}
+
+	for _, databaseName := range []string{tangleDatabaseName, snapshotDatabaseName, spentDatabaseName} {
+		databasePath := filepath.Join(*databasePathFlag, databaseName)
+
+		if _, err := os.Stat(databasePath); err != nil {
+			return ierrors.Wrapf(err, "failed to open %s database: %s", databaseName, databasePath)
+		}
+
+		// the databases are only opened in read/write mode if they really get migrated
+		store, err := engine.StoreWithDefaultSettings(databasePath, false, hivedb.EngineAuto, *dryRunFlag, engine.AllowedEnginesStorageAuto...)
+		if err != nil {
+			return ierrors.Wrapf(err, "failed to open %s database: %s", databaseName, databasePath)
+		}
+
+		fmt.Printf("Migrating %s database (%s)...\n", databaseName, databasePath)
+		ts := time.Now()
+
+		report, err := database.Migrate(ctx, log, databaseName, store, *dryRunFlag)
+		if closeErr := store.Close(); err == nil && closeErr != nil {
+			err = ierrors.Wrapf(closeErr, "failed to close %s database", databaseName)
+		}
+		if err != nil {
+			// the progress of the current step was checkpointed, so the migration can be resumed
+			return ierrors.Wrapf(err, "failed to migrate %s database, run the tool again to resume the migration", databaseName)
+		}
+
+		printMigrationReport(report)
+		fmt.Printf("Migrating %s database... done! took: %v\n", databaseName, time.Since(ts).Truncate(time.Millisecond))
+	}
+
+	return nil
+}
+
+func printMigrationReport(report *database.MigrationReport) {
+	if len(report.Steps) == 0 && report.CurrentVersion == report.TargetVersion {
+		fmt.Printf("	database is already at version %d\n", report.TargetVersion)
+		return
+	}
+
+	changedVerb := "changed"
+	if report.DryRun {
+		changedVerb = "would change"
+	}
+
+	fmt.Printf("	%-20s %d -> %d\n", "version:", report.CurrentVersion, report.TargetVersion)
+	for _, step := range report.Steps {
+		resumed := ""
+		if step.Resumed {
+			resumed = ", resumed from checkpoint"
+		}
+
+		fmt.Printf("	%d -> %d (%s): analyzed %d entries, %s %d entries%s\n", step.FromVersion, step.ToVersion, step.Name, step.Analyzed, changedVerb, step.Changed, resumed)
+	}
+}
@@ -0,0 +1,173 @@
+package toolset
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/iotaledger/hive.go/ierrors"
+	hivedb "github.com/iotaledger/hive.go/kvstore/database"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/inx-api-core-v0/pkg/database"
+)
+
+const (
+	// the name of the argument that needs to be passed to the app to use the tools.
+	toolsArgument = "tools"
+)
+
+const (
+	FlagToolSourceDatabasePath  = "sourceDatabasePath"
+	FlagToolTargetDatabasePath  = "targetDatabasePath"
+	FlagToolTargetEngine        = "targetEngine"
+	FlagToolDatabasePath        = "databasePath"
+	FlagToolIndexDatabasePath   = "indexDatabasePath"
+	FlagToolIndexDatabaseEngine = "indexDatabaseEngine"
+	FlagToolInterval            = "interval"
+	FlagToolOutputPath          = "outputPath"
+	FlagToolMaxIssues           = "maxIssues"
+	FlagToolMilestoneIndex      = "milestoneIndex"
+	FlagToolFormat              = "format"
+	FlagToolStartIndex          = "startIndex"
+	FlagToolEndIndex            = "endIndex"
+	FlagToolValueOnly           = "valueOnly"
+	FlagToolChunkSize           = "chunkSize"
+	FlagToolSnapshotPath        = "snapshotPath"
+	FlagToolCoordinatorAddress  = "coordinatorAddress"
+	FlagToolDryRun              = "dryRun"
+)
+
+const (
+	ToolDatabaseConvert      = "db-convert"
+	ToolDatabaseCheckpoints  = "db-checkpoints"
+	ToolDatabaseAddressIndex = "db-address-index"
+	ToolAudit                = "audit"
+	ToolExportLedger         = "export-ledger"
+	ToolExportTangle         = "export-tangle"
+	ToolImportSnapshot       = "import-snapshot"
+	ToolMigrate              = "migrate"
+	ToolExportSpent          = "export-spent"
+)
+
+const (
+	DefaultValueDatabasePath       = "database"
+	DefaultValueTargetDatabasePath = "database_converted"
+	DefaultValueIndexDatabasePath  = "database/index"
+)
+
+const (
+	// the names of the legacy databases inside of the database folder.
+	tangleDatabaseName   = "tangle"
+	snapshotDatabaseName = "snapshot"
+	spentDatabaseName    = "spent"
+)
+
+// ShouldHandleTools checks if tools were requested.
+func ShouldHandleTools() bool {
+	return len(os.Args) > 1 && strings.ToLower(os.Args[1]) == toolsArgument
+}
+
+// HandleTools handles available tools.
+func HandleTools() {
+
+	args := os.Args[1:]
+	if len(args) == 1 {
+		listTools()
+		os.Exit(1)
+	}
+
+	tools := map[string]func([]string) error{
+		ToolDatabaseConvert:      databaseConvert,
+		ToolDatabaseCheckpoints:  databaseCheckpoints,
+		ToolDatabaseAddressIndex: databaseAddressIndex,
+		ToolAudit:                audit,
+		ToolExportLedger:         exportLedger,
+		ToolExportTangle:         exportTangle,
+		ToolImportSnapshot:       importSnapshot,
+		ToolMigrate:              migrate,
+		ToolExportSpent:          exportSpent,
+	}
+
+	tool, exists := tools[strings.ToLower(args[1])]
+	if !exists {
+		fmt.Print("tool not found.\n\n")
+		listTools()
+		os.Exit(1)
+	}
+
+	if err := tool(args[2:]); err != nil {
+		if ierrors.Is(err, flag.ErrHelp) {
+			// help text was requested
+			os.Exit(0)
+		}
+
+		fmt.Printf("\nerror: %s\n", err)
+		os.Exit(1)
+	}
+
+	os.Exit(0)
+}
+
+func listTools() {
+	fmt.Printf("%-20s converts the legacy databases to another database engine\n", fmt.Sprintf("%s:", ToolDatabaseConvert))
+	fmt.Printf("%-20s builds the ledger checkpoints in the index database to speed up historical ledger queries\n", fmt.Sprintf("%s:", ToolDatabaseCheckpoints))
+	fmt.Printf("%-20s builds the index of the milestones that changed the balance of an address in the index database\n", fmt.Sprintf("%s:", ToolDatabaseAddressIndex))
+	fmt.Printf("%-20s checks the integrity of the legacy databases and writes a JSON report\n", fmt.Sprintf("%s:", ToolAudit))
+	fmt.Printf("%-20s exports the ledger state of a milestone as CSV, JSON Lines or binary file\n", fmt.Sprintf("%s:", ToolExportLedger))
+	fmt.Printf("%-20s exports the transactions and their metadata into a chunked and checksummed archive file\n", fmt.Sprintf("%s:", ToolExportTangle))
+	fmt.Printf("%-20s creates ledger-only databases from a legacy local snapshot file\n", fmt.Sprintf("%s:", ToolImportSnapshot))
+	fmt.Printf("%-20s migrates the legacy databases to the current database version\n", fmt.Sprintf("%s:", ToolMigrate))
+	fmt.Printf("%-20s exports all spent addresses into a text file, one address per line\n", fmt.Sprintf("%s:", ToolExportSpent))
+}
+
+func newFlagSet(toolName string) *flag.FlagSet {
+	fs := flag.NewFlagSet(toolName, flag.ContinueOnError)
+	fs.SortFlags = false
+
+	return fs
+}
+
+func parseFlagSet(fs *flag.FlagSet, args []string) error {
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Check if all parameters were parsed
+	if fs.NArg() != 0 {
+		return ierrors.New("too much arguments")
+	}
+
+	return nil
+}
+
+// openLegacyDatabase opens the legacy tangle, snapshot and spent databases inside of the given folder.
+func openLegacyDatabase(ctx context.Context, log *logger.Logger, databasePath string) (*database.Database, error) {
+	db, err := database.New(
+		ctx,
+		log,
+		filepath.Join(databasePath, tangleDatabaseName),
+		filepath.Join(databasePath, snapshotDatabaseName),
+		filepath.Join(databasePath, spentDatabaseName),
+		hivedb.EngineAuto,
+		false,
+		// the tools mostly iterate over the databases, so caching the objects doesn't help
+		database.CacheSizes{},
+	)
+	if err != nil {
+		return nil, ierrors.Wrapf(err, "failed to open databases: %s", databasePath)
+	}
+
+	return db, nil
+}
+
+// gracefulStopContext returns a context that is canceled if the tool receives a termination signal.
+func gracefulStopContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}